@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/luigimorel/gogen/internal"
+)
+
+func AddCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "add",
+		Usage: "Add a component to an existing gogen project",
+		Description: `Add a component to a project created with gogen new.
+Run the command from the project root directory.
+
+Usage:
+  gogen add k8s
+  gogen add k8s --helm
+  gogen add worker
+  gogen add service --template api users
+  gogen add realtime --transport sse
+  gogen add storage --backend s3`,
+		Subcommands: []*cli.Command{
+			AddK8sCommand(),
+			AddWorkerCommand(),
+			AddServiceCommand(),
+			AddRealtimeCommand(),
+			AddStorageCommand(),
+		},
+	}
+}
+
+type K8sGenerator struct {
+	ProjectName string
+	UseHelm     bool
+}
+
+func NewK8sGenerator(projectName string, useHelm bool) *K8sGenerator {
+	return &K8sGenerator{
+		ProjectName: projectName,
+		UseHelm:     useHelm,
+	}
+}
+
+func AddK8sCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "k8s",
+		Usage: "Generate Kubernetes manifests and an optional Helm chart",
+		Description: `Generate a Deployment, Service, ConfigMap, Secret template and Ingress for the project.
+Web projects get separate manifests for the api and frontend services, mirroring docker-compose.yml.
+The ConfigMap is built from the keys in .env.example, keys that look like secrets go to the Secret template.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "name",
+				Aliases: []string{"n"},
+				Usage:   "Project name used for resource names (default: current directory name)",
+			},
+			&cli.BoolFlag{
+				Name:  "helm",
+				Usage: "Also generate a Helm chart",
+				Value: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			projectName := c.String("name")
+			if projectName == "" {
+				wd, err := os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get current directory: %w", err)
+				}
+				projectName = filepath.Base(wd)
+			}
+
+			generator := NewK8sGenerator(projectName, c.Bool("helm"))
+			return generator.execute()
+		},
+	}
+}
+
+func (kg *K8sGenerator) execute() error {
+	config, err := internal.NewK8sConfig(".", kg.ProjectName, kg.UseHelm)
+	if err != nil {
+		return err
+	}
+
+	pg := internal.NewProjectGenerator()
+	if err := pg.CreateK8sManifests(".", config); err != nil {
+		return fmt.Errorf("failed to create kubernetes manifests: %w", err)
+	}
+
+	kg.printInstructions(config.ProjectName)
+
+	return nil
+}
+
+func (kg *K8sGenerator) printInstructions(chartName string) {
+	fmt.Println("Kubernetes manifests created in k8s/")
+	fmt.Println("\nNext steps:")
+	fmt.Println("   kubectl apply -k k8s")
+	if kg.UseHelm {
+		fmt.Printf("   helm install %s ./helm/%s\n", chartName, chartName)
+	}
+}
+
+type WorkerGenerator struct {
+	DockerBase string
+}
+
+func NewWorkerGenerator(dockerBase string) *WorkerGenerator {
+	return &WorkerGenerator{
+		DockerBase: dockerBase,
+	}
+}
+
+func AddWorkerCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "worker",
+		Usage: "Add a background job worker to an API or web project",
+		Description: `Generate internal/worker (jobs, registry, backends and a worker pool), a sample job in
+internal/jobs and a cmd/worker binary that runs the pool. Web projects get them in api/.
+When the project has a docker-compose.yml, a worker service built from Dockerfile.worker is added to it.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "docker-base",
+				Usage: "Final stage of the worker Docker image (distroless, scratch, alpine)",
+				Value: internal.DockerBaseDistroless,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			generator := NewWorkerGenerator(c.String("docker-base"))
+			return generator.execute()
+		},
+	}
+}
+
+func (wg *WorkerGenerator) execute() error {
+	if !internal.IsDockerBase(wg.DockerBase) {
+		return fmt.Errorf("unsupported docker base: %s. Supported bases: distroless, scratch, alpine", wg.DockerBase)
+	}
+
+	pg := internal.NewProjectGenerator()
+	moduleDir, err := pg.AddWorker(".", &internal.WebProjectConfig{DockerBase: wg.DockerBase})
+	if err != nil {
+		return fmt.Errorf("failed to add worker: %w", err)
+	}
+
+	fmt.Printf("Worker created in %s\n", filepath.Join(moduleDir, "internal", "worker"))
+	fmt.Println("\nNext steps:")
+	if moduleDir != "." {
+		fmt.Printf("   cd %s\n", moduleDir)
+	}
+	fmt.Println("   go run ./cmd/worker")
+
+	return nil
+}
+
+type ServiceGenerator struct {
+	Config *internal.ServiceConfig
+}
+
+func NewServiceGenerator(config *internal.ServiceConfig) *ServiceGenerator {
+	return &ServiceGenerator{
+		Config: config,
+	}
+}
+
+func AddServiceCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "service",
+		Usage:     "Add a service module to a workspace",
+		ArgsUsage: "<name>",
+		Description: `Create a module in services/<name> with the api, worker or cli template and add it to go.work.
+The module uses the shared pkg module through a replace directive and gets build, run and test targets
+in the Makefile. api and worker services are added to docker-compose.yml, built from the workspace root.
+Run the command from the root of a project created with gogen new --template workspace.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "template",
+				Aliases: []string{"t"},
+				Usage:   "Service template (api, worker, cli)",
+				Value:   "api",
+			},
+			&cli.StringFlag{
+				Name:    "router",
+				Aliases: []string{"r"},
+				Usage:   "Router type for api services (stdlib, chi, gorilla, httprouter)",
+				Value:   "stdlib",
+			},
+			&cli.IntFlag{
+				Name:  "port",
+				Usage: "Port an api service listens on (default: next free port in docker-compose.yml)",
+			},
+			&cli.StringFlag{
+				Name:  "docker-base",
+				Usage: "Final stage of the service Docker image (distroless, scratch, alpine)",
+				Value: internal.DockerBaseDistroless,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return fmt.Errorf("expected a service name, usage: gogen add service [flags] <name>")
+			}
+
+			generator := NewServiceGenerator(&internal.ServiceConfig{
+				Name:       c.Args().First(),
+				Template:   c.String("template"),
+				Router:     c.String("router"),
+				Port:       c.Int("port"),
+				DockerBase: c.String("docker-base"),
+			})
+			return generator.execute()
+		},
+	}
+}
+
+func (sg *ServiceGenerator) execute() error {
+	if !internal.IsDockerBase(sg.Config.DockerBase) {
+		return fmt.Errorf("unsupported docker base: %s. Supported bases: distroless, scratch, alpine", sg.Config.DockerBase)
+	}
+
+	pg := internal.NewProjectGenerator()
+	if err := pg.CreateWorkspaceService(".", sg.Config); err != nil {
+		return fmt.Errorf("failed to add service: %w", err)
+	}
+
+	fmt.Printf("Service created in %s\n", filepath.Join("services", sg.Config.Name))
+	fmt.Println("\nNext steps:")
+	fmt.Printf("   make run-%s\n", sg.Config.Name)
+	fmt.Printf("   make test-%s\n", sg.Config.Name)
+
+	return nil
+}
+
+type RealtimeGenerator struct {
+	Transport string
+}
+
+func NewRealtimeGenerator(transport string) *RealtimeGenerator {
+	return &RealtimeGenerator{
+		Transport: transport,
+	}
+}
+
+func AddRealtimeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "realtime",
+		Usage: "Add WebSocket or Server-Sent Events push to an API or web project",
+		Description: `Generate internal/realtime, a hub that broadcasts messages to connected clients with per-client
+send buffers, keepalive pings and graceful shutdown, and register its endpoint (/ws or /events) in
+cmd/web/routes.go. Web projects get it in api/, plus a client and a hook, composable, store or signal
+for the frontend framework next to src/config.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "transport",
+				Aliases: []string{"t"},
+				Usage:   "Transport to push messages over (ws, sse)",
+				Value:   internal.RealtimeWebSocket,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			generator := NewRealtimeGenerator(c.String("transport"))
+			return generator.execute()
+		},
+	}
+}
+
+func (rg *RealtimeGenerator) execute() error {
+	if !internal.IsRealtimeTransport(rg.Transport) {
+		return fmt.Errorf("unsupported transport: %s. Supported transports: ws, sse", rg.Transport)
+	}
+
+	pg := internal.NewProjectGenerator()
+	result, err := pg.AddRealtime(".", rg.Transport)
+	if err != nil {
+		return fmt.Errorf("failed to add realtime: %w", err)
+	}
+
+	fmt.Printf("Realtime hub created in %s\n", filepath.Join(result.ModuleDir, "internal", "realtime"))
+	for _, file := range result.ClientFiles {
+		fmt.Printf("Realtime client created in %s\n", file)
+	}
+
+	endpoint := internal.RealtimeEndpoint(rg.Transport)
+	fmt.Println("\nNext steps:")
+	if !result.RouteAdded {
+		fmt.Printf("   Serve realtime.Handler() on GET %s in your router\n", endpoint)
+	}
+	if !result.ShutdownAdded {
+		fmt.Println("   Call realtime.Close() when the server shuts down to close client connections")
+	}
+	fmt.Println("   Publish from your handlers with realtime.Broadcast or realtime.BroadcastJSON")
+	if rg.Transport == internal.RealtimeSSE {
+		fmt.Printf("   curl -N http://localhost:8080%s\n", endpoint)
+	}
+
+	return nil
+}
+
+type StorageGenerator struct {
+	Backend string
+}
+
+func NewStorageGenerator(backend string) *StorageGenerator {
+	return &StorageGenerator{
+		Backend: backend,
+	}
+}
+
+func AddStorageCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "storage",
+		Usage: "Add file uploads and object storage to an API or web project",
+		Description: `Generate internal/storage, a Storage interface with local-disk and S3-compatible implementations,
+multipart upload handlers with size and content type limits, and signed download URLs, and register
+POST /uploads, GET /uploads/{key} and GET /files/{key} in cmd/web/routes.go. The backend is configured
+in .env. With the s3 backend, docker-compose.yml gets a minio service. Web projects get it in api/,
+plus an upload component for the frontend framework next to src/config.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "backend",
+				Aliases: []string{"b"},
+				Usage:   "Backend to keep uploaded files in (local, s3)",
+				Value:   internal.StorageLocal,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			generator := NewStorageGenerator(c.String("backend"))
+			return generator.execute()
+		},
+	}
+}
+
+func (sg *StorageGenerator) execute() error {
+	if !internal.IsStorageBackend(sg.Backend) {
+		return fmt.Errorf("unsupported storage backend: %s. Supported backends: local, s3", sg.Backend)
+	}
+
+	pg := internal.NewProjectGenerator()
+	result, err := pg.AddStorage(".", sg.Backend)
+	if err != nil {
+		return fmt.Errorf("failed to add storage: %w", err)
+	}
+
+	fmt.Printf("Storage created in %s\n", filepath.Join(result.ModuleDir, "internal", "storage"))
+	for _, file := range result.ClientFiles {
+		fmt.Printf("Upload client created in %s\n", file)
+	}
+	if result.ComposeUpdated {
+		fmt.Println("docker-compose.yml updated to keep uploads in the minio service")
+	}
+
+	fmt.Println("\nNext steps:")
+	if !result.RouteAdded {
+		fmt.Println("   Serve storage.UploadHandler() on POST and OPTIONS /uploads, storage.URLHandler() on GET /uploads/{key}")
+		fmt.Println("   and storage.DownloadHandler() on GET /files/{key} in your router")
+	}
+	if sg.Backend == internal.StorageS3 {
+		fmt.Println("   docker compose up -d minio")
+		if result.ModuleDir != "." {
+			fmt.Printf("   cd %s\n", result.ModuleDir)
+		}
+		fmt.Println("   S3_TEST_ENDPOINT=http://localhost:9000 go test ./internal/storage/")
+	}
+	fmt.Println("   curl -F file=@image.png http://localhost:8080/uploads")
+
+	return nil
+}
@@ -47,6 +47,10 @@ Supported frameworks:
 - vue: Vue.js with Vite
 - svelte: Svelte with Vite
 - solidjs: SolidJS with Vite
+- preact: Preact with Vite
+- lit: Lit web components with Vite
+- qwik: Qwik with Qwik City
+- astro: Astro
 - angular: Angular CLI
 
 Supported runtimes:
@@ -151,7 +155,7 @@ func (fm *FrontendManager) validateSetup() error {
 				return fmt.Errorf("failed to install Angular CLI: %w", err)
 			}
 		}
-	case "react", "vue", "svelte", "solidjs":
+	case "react", "vue", "svelte", "solidjs", "preact", "lit", "qwik", "astro":
 	default:
 		return fmt.Errorf("unsupported frontend framework: %s", fm.FrameworkType)
 	}
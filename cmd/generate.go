@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/luigimorel/gogen/internal"
+)
+
+func GenerateCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "generate",
+		Aliases: []string{"g"},
+		Usage:   "Generate code inside an existing gogen project",
+		Description: `Generate code inside a project created with gogen new.
+Run the command from the project root directory.
+
+Usage:
+  gogen generate command --flag target:string:staging --flag dry-run:bool deploy`,
+		Subcommands: []*cli.Command{
+			GenerateCLICommandCommand(),
+		},
+	}
+}
+
+type CLICommandGenerator struct {
+	Name  string
+	Usage string
+	Flags []string
+}
+
+func NewCLICommandGenerator(name, usage string, flags []string) *CLICommandGenerator {
+	return &CLICommandGenerator{
+		Name:  name,
+		Usage: usage,
+		Flags: flags,
+	}
+}
+
+func GenerateCLICommandCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "command",
+		Usage:     "Add a subcommand to a urfave/cli project",
+		ArgsUsage: "<name>",
+		Description: `Insert a new cli.Command into the app's Commands slice, with its action in
+commands/<name>.go and a test in <name>_test.go that runs it against captured output.
+
+Flags are given as name[:type[:default]], supported types are string (default),
+int, int64, uint, float64, bool and duration. Flags must come before the name.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "usage",
+				Aliases: []string{"u"},
+				Usage:   "Usage text of the command",
+			},
+			&cli.StringSliceFlag{
+				Name:    "flag",
+				Aliases: []string{"f"},
+				Usage:   "Flag spec name[:type[:default]], can be repeated",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return fmt.Errorf("expected exactly one command name after the flags, got %d arguments", c.NArg())
+			}
+
+			generator := NewCLICommandGenerator(c.Args().First(), c.String("usage"), c.StringSlice("flag"))
+			return generator.execute()
+		},
+	}
+}
+
+func (cg *CLICommandGenerator) execute() error {
+	config, err := internal.NewCLICommandConfig(cg.Name, cg.Usage, cg.Flags)
+	if err != nil {
+		return err
+	}
+
+	pg := internal.NewProjectGenerator()
+	if err := pg.CreateCLICommand(".", config); err != nil {
+		return fmt.Errorf("failed to generate command: %w", err)
+	}
+
+	fmt.Printf("Command '%s' added\n", cg.Name)
+	fmt.Println("\nNext steps:")
+	fmt.Printf("   go run . %s --help\n", cg.Name)
+	fmt.Println("   go test ./...")
+
+	return nil
+}
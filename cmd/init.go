@@ -14,6 +14,8 @@ func App() *cli.App {
 			NewCommand(),
 			InstallCommand(),
 			FrontendCommand(),
+			AddCommand(),
+			GenerateCommand(),
 		},
 	}
 }
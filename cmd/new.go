@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 
@@ -23,6 +25,24 @@ type ProjectCreator struct {
 	UseTailwind       bool
 	Editor            string
 	UseDocker         bool
+	APIPort           int
+	FrontendPort      int
+	Services          []string
+	DockerBase        string
+	FrontendServer    string
+	UseK8s            bool
+	UseHelm           bool
+	UseDevContainer   bool
+	CI                string
+	CLIFramework      string
+	GRPCPort          int
+	UseGateway        bool
+	UseWASI           bool
+	LambdaConfig      string
+	UseObservability  bool
+	UseRateLimit      bool
+	APIStyle          string
+	UseCache          bool
 }
 
 func NewProjectCreator(name, moduleName, template, router, frontendFramework, projectDir, runtime, editor string, useTypeScript, useTailwind, useDocker bool) *ProjectCreator {
@@ -66,19 +86,19 @@ This command will create a new directory, initialize a Go module, and create a n
 			&cli.StringFlag{
 				Name:    "template",
 				Aliases: []string{"t"},
-				Usage:   "Project template (cli, web, api)",
+				Usage:   "Project template (cli, web, api, tui, grpc, worker, workspace, lib, wasm, lambda)",
 				Value:   "api",
 			},
 			&cli.StringFlag{
 				Name:    "router",
 				Aliases: []string{"r"},
-				Usage:   "Router type for API/web projects (stdlib, chi, gorilla, httprouter)",
+				Usage:   "Router type for API/web/lambda projects (stdlib, chi, gorilla, httprouter)",
 				Value:   "stdlib",
 			},
 			&cli.StringFlag{
 				Name:    "frontend",
 				Aliases: []string{"fe"},
-				Usage:   "Frontend framework for web projects (react, vue, svelte, solidjs, angular)",
+				Usage:   "Frontend framework for web projects (react, vue, svelte, solidjs, preact, lit, qwik, astro, angular)",
 			},
 			&cli.StringFlag{
 				Name:  "dir",
@@ -108,6 +128,94 @@ This command will create a new directory, initialize a Go module, and create a n
 				Usage: "Adds Docker and docker compose files to the project",
 				Value: false,
 			},
+			&cli.IntFlag{
+				Name:  "api-port",
+				Usage: "Port the API listens on in docker compose (only applicable with --docker)",
+				Value: internal.DefaultAPIPort,
+			},
+			&cli.IntFlag{
+				Name:  "frontend-port",
+				Usage: "Host port the frontend container is published on (only applicable with --docker)",
+				Value: internal.DefaultFrontendPort,
+			},
+			&cli.StringFlag{
+				Name:  "docker-base",
+				Usage: "Final stage of the API Docker image (distroless, scratch, alpine)",
+				Value: internal.DockerBaseDistroless,
+			},
+			&cli.StringFlag{
+				Name:  "frontend-server",
+				Usage: "Server for the production frontend image (nginx, caddy, static)",
+				Value: internal.FrontendServerNginx,
+			},
+			&cli.StringSliceFlag{
+				Name:  "services",
+				Usage: "Extra docker compose services (postgres, redis, mailpit, minio, jaeger, prometheus, grafana)",
+			},
+			&cli.BoolFlag{
+				Name:  "k8s",
+				Usage: "Generate Kubernetes manifests for API and web projects",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "helm",
+				Usage: "Also generate a Helm chart (only applicable with --k8s)",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "devcontainer",
+				Usage: "Generate a dev container with the Go toolchain, Air, golangci-lint and the JS runtime",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:  "ci",
+				Usage: "Generate a CI pipeline (github, gitlab)",
+			},
+			&cli.StringFlag{
+				Name:  "cli-framework",
+				Usage: "CLI framework for the cli template (urfave, cobra, stdlib-flag)",
+				Value: internal.CLIFrameworkUrfave,
+			},
+			&cli.IntFlag{
+				Name:  "grpc-port",
+				Usage: "Port the gRPC server listens on (only applicable with the grpc template)",
+				Value: internal.DefaultGRPCPort,
+			},
+			&cli.BoolFlag{
+				Name:  "gateway",
+				Usage: "Serve the gRPC services as JSON over HTTP with grpc-gateway on --router (only applicable with the grpc template)",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:  "lambda-config",
+				Usage: "Deployment config for the lambda template (sam, serverless)",
+				Value: internal.LambdaConfigSAM,
+			},
+			&cli.BoolFlag{
+				Name:  "wasi",
+				Usage: "Also build a WASI command with GOOS=wasip1 (only applicable with the wasm template)",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "observability",
+				Usage: "Add Prometheus metrics on /metrics, OpenTelemetry tracing and trace IDs in logs to the API (only applicable with the api and web templates)",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "ratelimit",
+				Usage: "Put the API behind a token-bucket rate limiter configured by RATE_LIMIT_* variables (only applicable with the api and web templates)",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:  "api-style",
+				Usage: "Style of the API (rest, graphql). graphql serves a gqlgen schema on /graphql with a playground on /playground (only applicable with the api and web templates)",
+				Value: internal.APIStyleREST,
+			},
+			&cli.BoolFlag{
+				Name:  "cache",
+				Usage: "Add a cache package with in-memory LRU and Redis stores and a response-caching middleware to the API, with Redis in docker compose when --docker is set (only applicable with the api and web templates)",
+				Value: false,
+			},
 		},
 		Action: func(c *cli.Context) error {
 			projectName := c.String("name")
@@ -124,11 +232,56 @@ This command will create a new directory, initialize a Go module, and create a n
 
 			// Check if runtime was explicitly set by user
 			runtimeExplicitlySet := c.IsSet("runtime")
-			if runtimeExplicitlySet && template != constants.WebTemplate {
-				return fmt.Errorf("runtime flag is only applicable when template is 'web'")
+			if runtimeExplicitlySet && template != constants.WebTemplate && template != constants.WasmTemplate {
+				return fmt.Errorf("runtime flag is only applicable when template is 'web' or 'wasm'")
 			}
 
 			creator := NewProjectCreator(projectName, moduleName, template, router, frontend, projectDir, runtime, editor, useTypeScript, useTailwind, useDocker)
+			creator.APIPort = c.Int("api-port")
+			creator.FrontendPort = c.Int("frontend-port")
+			creator.Services = c.StringSlice("services")
+			creator.DockerBase = c.String("docker-base")
+			creator.FrontendServer = c.String("frontend-server")
+			creator.UseK8s = c.Bool("k8s")
+			creator.UseHelm = c.Bool("helm")
+			creator.UseDevContainer = c.Bool("devcontainer")
+			creator.CI = c.String("ci")
+			creator.CLIFramework = c.String("cli-framework")
+			if c.IsSet("cli-framework") && template != constants.CLITemplate {
+				return fmt.Errorf("cli-framework flag is only applicable when template is 'cli'")
+			}
+			creator.GRPCPort = c.Int("grpc-port")
+			creator.UseGateway = c.Bool("gateway")
+			if (c.IsSet("grpc-port") || creator.UseGateway) && template != constants.GRPCTemplate {
+				return fmt.Errorf("grpc-port and gateway flags are only applicable when template is 'grpc'")
+			}
+			creator.LambdaConfig = c.String("lambda-config")
+			if c.IsSet("lambda-config") && template != constants.LambdaTemplate {
+				return fmt.Errorf("lambda-config flag is only applicable when template is 'lambda'")
+			}
+			creator.UseWASI = c.Bool("wasi")
+			if creator.UseWASI && template != constants.WasmTemplate {
+				return fmt.Errorf("wasi flag is only applicable when template is 'wasm'")
+			}
+			creator.UseObservability = c.Bool("observability")
+			if creator.UseObservability && template != constants.APITemplate && template != constants.WebTemplate {
+				return fmt.Errorf("observability flag is only applicable when template is 'api' or 'web'")
+			}
+			creator.UseRateLimit = c.Bool("ratelimit")
+			if creator.UseRateLimit && template != constants.APITemplate && template != constants.WebTemplate {
+				return fmt.Errorf("ratelimit flag is only applicable when template is 'api' or 'web'")
+			}
+			creator.APIStyle = c.String("api-style")
+			if c.IsSet("api-style") && template != constants.APITemplate && template != constants.WebTemplate {
+				return fmt.Errorf("api-style flag is only applicable when template is 'api' or 'web'")
+			}
+			creator.UseCache = c.Bool("cache")
+			if creator.UseCache && template != constants.APITemplate && template != constants.WebTemplate {
+				return fmt.Errorf("cache flag is only applicable when template is 'api' or 'web'")
+			}
+			if creator.UseCache && useDocker && !slices.Contains(creator.Services, internal.ServiceRedis) {
+				creator.Services = append(creator.Services, internal.ServiceRedis)
+			}
 			return creator.execute()
 		},
 	}
@@ -159,6 +312,24 @@ func (pc *ProjectCreator) execute() error {
 		return fmt.Errorf("failed to create project files: %w", err)
 	}
 
+	if pc.UseK8s {
+		if err := pc.createK8sManifests(); err != nil {
+			return fmt.Errorf("failed to create kubernetes manifests: %w", err)
+		}
+	}
+
+	if pc.UseDevContainer {
+		if err := pc.createDevContainer(); err != nil {
+			return fmt.Errorf("failed to create dev container: %w", err)
+		}
+	}
+
+	if pc.CI != "" {
+		if err := pc.createCIConfig(); err != nil {
+			return fmt.Errorf("failed to create CI pipeline: %w", err)
+		}
+	}
+
 	if pc.Editor != "" {
 		if err := pc.createEditorLLMRules(); err != nil {
 			fmt.Printf("Warning: failed to create LLM rules for %s: %v\n", pc.Editor, err)
@@ -173,8 +344,12 @@ func (pc *ProjectCreator) execute() error {
 }
 
 func (pc *ProjectCreator) validate() error {
-	if pc.FrontendFramework != "" && pc.Template != constants.WebTemplate {
-		return fmt.Errorf("frontend flag is only applicable when template is 'web'")
+	if pc.FrontendFramework != "" && pc.Template != constants.WebTemplate && pc.Template != constants.WasmTemplate {
+		return fmt.Errorf("frontend flag is only applicable when template is 'web' or 'wasm'")
+	}
+
+	if pc.Template == constants.WasmTemplate && pc.FrontendFramework != "" && !internal.IsWasmFrontend(pc.FrontendFramework) {
+		return fmt.Errorf("unsupported frontend for the wasm template: %s. Supported frameworks: %s", pc.FrontendFramework, strings.Join(internal.WasmFrontends, ", "))
 	}
 
 	if pc.UseTypeScript && pc.FrontendFramework == "" {
@@ -185,6 +360,62 @@ func (pc *ProjectCreator) validate() error {
 		return fmt.Errorf("tailwind flag is only applicable when frontend is specified")
 	}
 
+	if len(pc.Services) > 0 && !pc.UseDocker {
+		return fmt.Errorf("services flag is only applicable when docker is enabled")
+	}
+
+	if pc.UseK8s && !(pc.Template == constants.APITemplate || pc.Template == constants.WebTemplate) {
+		return fmt.Errorf("k8s flag is only applicable when template is 'api' or 'web'")
+	}
+
+	if pc.Template == constants.WorkspaceTemplate && (pc.UseDocker || pc.UseDevContainer || pc.CI != "") {
+		return fmt.Errorf("docker, devcontainer and ci flags are not applicable to workspaces, services get Docker files from gogen add service")
+	}
+
+	if pc.Template == constants.LambdaTemplate && !internal.IsLambdaConfig(pc.LambdaConfig) {
+		return fmt.Errorf("unsupported lambda config: %s. Supported configs: sam, serverless", pc.LambdaConfig)
+	}
+
+	if (pc.Template == constants.LibTemplate || pc.Template == constants.WasmTemplate || pc.Template == constants.LambdaTemplate) && pc.UseDocker {
+		return fmt.Errorf("docker flag is not applicable to the %s template", pc.Template)
+	}
+
+	if pc.UseHelm && !pc.UseK8s {
+		return fmt.Errorf("helm flag is only applicable when k8s is enabled")
+	}
+
+	if pc.CI != "" && !internal.IsCIProvider(pc.CI) {
+		return fmt.Errorf("unsupported CI provider: %s. Supported providers: github, gitlab", pc.CI)
+	}
+
+	if pc.Template == constants.CLITemplate && !internal.IsCLIFramework(pc.CLIFramework) {
+		return fmt.Errorf("unsupported CLI framework: %s. Supported frameworks: urfave, cobra, stdlib-flag", pc.CLIFramework)
+	}
+
+	if pc.APIStyle != "" && !internal.IsAPIStyle(pc.APIStyle) {
+		return fmt.Errorf("unsupported API style: %s. Supported styles: rest, graphql", pc.APIStyle)
+	}
+
+	if !internal.IsDockerBase(pc.DockerBase) {
+		return fmt.Errorf("unsupported docker base: %s. Supported bases: distroless, scratch, alpine", pc.DockerBase)
+	}
+
+	if !internal.IsFrontendServer(pc.FrontendServer) {
+		return fmt.Errorf("unsupported frontend server: %s. Supported servers: nginx, caddy, static", pc.FrontendServer)
+	}
+
+	// Repeated services would be written to docker-compose.yml twice.
+	var services []string
+	for _, service := range pc.Services {
+		if !internal.IsComposeService(service) {
+			return fmt.Errorf("unsupported service: %s. Supported services: %s", service, strings.Join(internal.ComposeServices, ", "))
+		}
+		if !slices.Contains(services, service) {
+			services = append(services, service)
+		}
+	}
+	pc.Services = services
+
 	return nil
 }
 
@@ -211,7 +442,8 @@ func (pc *ProjectCreator) ChangeToProjectDirectory() (string, func(), error) {
 }
 
 func (pc *ProjectCreator) initializeGoModule() error {
-	if pc.Template != constants.WebTemplate {
+	// Web projects get their module in api/, workspaces one per service.
+	if pc.Template != constants.WebTemplate && pc.Template != constants.WorkspaceTemplate {
 		moduleName := pc.ModuleName
 		if moduleName == "" {
 			moduleName = pc.Name
@@ -231,16 +463,123 @@ func (pc *ProjectCreator) createProjectFiles() error {
 
 	switch pc.Template {
 	case constants.CLITemplate:
-		return pg.CreateCLIProject(pc.Name, pc.ModuleName)
+		return pg.CreateCLIProject(pc.Name, pc.ModuleName, pc.CLIFramework)
+	case constants.TUITemplate:
+		return pg.CreateTUIProject(pc.Name, pc.ModuleName)
+	case constants.GRPCTemplate:
+		return pg.CreateGRPCProject(&internal.WebProjectConfig{
+			ProjectName: pc.Name,
+			ModuleName:  pc.ModuleName,
+			Router:      pc.Router,
+			UseDocker:   pc.UseDocker,
+			APIPort:     pc.APIPort,
+			Services:    pc.Services,
+			DockerBase:  pc.DockerBase,
+			GRPCPort:    pc.GRPCPort,
+			UseGateway:  pc.UseGateway,
+		})
+	case constants.WorkerTemplate:
+		return pg.CreateWorkerProject(&internal.WebProjectConfig{
+			ProjectName: pc.Name,
+			ModuleName:  pc.ModuleName,
+			UseDocker:   pc.UseDocker,
+			Services:    pc.Services,
+			DockerBase:  pc.DockerBase,
+		})
+	case constants.WorkspaceTemplate:
+		return pg.CreateWorkspaceProject(pc.Name, pc.ModuleName)
+	case constants.LibTemplate:
+		return pg.CreateLibProject(pc.Name, pc.ModuleName)
+	case constants.LambdaTemplate:
+		return pg.CreateLambdaProject(&internal.WebProjectConfig{
+			ProjectName:  pc.Name,
+			ModuleName:   pc.ModuleName,
+			Router:       pc.Router,
+			LambdaConfig: pc.LambdaConfig,
+		})
+	case constants.WasmTemplate:
+		return pg.CreateWasmProject(&internal.WebProjectConfig{
+			ProjectName:       pc.Name,
+			ModuleName:        pc.ModuleName,
+			FrontendFramework: pc.FrontendFramework,
+			Runtime:           pc.Runtime,
+			UseTypeScript:     pc.UseTypeScript,
+			UseTailwind:       pc.UseTailwind,
+			UseWASI:           pc.UseWASI,
+		})
 	case constants.WebTemplate:
-		return pg.CreateWebProject(pc.Name, pc.ModuleName, pc.Router, pc.FrontendFramework, pc.Runtime, pc.UseTypeScript, pc.UseTailwind, pc.UseDocker)
+		return pg.CreateWebProjectWithConfig(&internal.WebProjectConfig{
+			ProjectName:       pc.Name,
+			ModuleName:        pc.ModuleName,
+			Router:            pc.Router,
+			FrontendFramework: pc.FrontendFramework,
+			Runtime:           pc.Runtime,
+			UseTypeScript:     pc.UseTypeScript,
+			UseTailwind:       pc.UseTailwind,
+			UseDocker:         pc.UseDocker,
+			APIPort:           pc.APIPort,
+			FrontendPort:      pc.FrontendPort,
+			Services:          pc.Services,
+			DockerBase:        pc.DockerBase,
+			FrontendServer:    pc.FrontendServer,
+			UseObservability:  pc.UseObservability,
+			UseRateLimit:      pc.UseRateLimit,
+			APIStyle:          pc.APIStyle,
+			UseCache:          pc.UseCache,
+		})
 	case constants.APIDir:
-		return pg.CreateAPIProject(pc.Name, pc.ModuleName, pc.Router)
+		return pg.CreateAPIProjectWithConfig(&internal.WebProjectConfig{
+			ProjectName:      pc.Name,
+			ModuleName:       pc.ModuleName,
+			Router:           pc.Router,
+			UseDocker:        pc.UseDocker,
+			APIPort:          pc.APIPort,
+			Services:         pc.Services,
+			DockerBase:       pc.DockerBase,
+			UseObservability: pc.UseObservability,
+			UseRateLimit:     pc.UseRateLimit,
+			APIStyle:         pc.APIStyle,
+			UseCache:         pc.UseCache,
+		})
 	default:
 		return fmt.Errorf("unsupported template: %s", pc.Template)
 	}
 }
 
+func (pc *ProjectCreator) createK8sManifests() error {
+	config, err := internal.NewK8sConfig(".", pc.Name, pc.UseHelm)
+	if err != nil {
+		return err
+	}
+
+	pg := internal.NewProjectGenerator()
+	return pg.CreateK8sManifests(".", config)
+}
+
+func (pc *ProjectCreator) createDevContainer() error {
+	pg := internal.NewProjectGenerator()
+	return pg.CreateDevContainer(".", &internal.DevContainerConfig{
+		ProjectName:       pc.Name,
+		Template:          pc.Template,
+		Runtime:           pc.Runtime,
+		FrontendFramework: pc.FrontendFramework,
+		APIPort:           pc.APIPort,
+		UseDocker:         pc.UseDocker,
+		Services:          pc.Services,
+	})
+}
+
+func (pc *ProjectCreator) createCIConfig() error {
+	pg := internal.NewProjectGenerator()
+	return pg.CreateCIConfig(".", &internal.CIConfig{
+		Provider:          pc.CI,
+		Template:          pc.Template,
+		Runtime:           pc.Runtime,
+		FrontendFramework: pc.FrontendFramework,
+		UseDocker:         pc.UseDocker,
+	})
+}
+
 func (pc *ProjectCreator) createEditorLLMRules() error {
 	if err := os.Chdir(".."); err != nil {
 		return fmt.Errorf("failed to change to project root directory: %w", err)
@@ -267,7 +606,53 @@ func (pc *ProjectCreator) printNextSteps() {
 			fmt.Println("   cd frontend")
 			fmt.Println("   npm run dev")
 		}
+	} else if pc.Template == constants.CLITemplate {
+		fmt.Println("   go run . greet")
+		fmt.Println("   make build-all   # cross-compile into bin/")
+		fmt.Println("   make snapshot    # local GoReleaser build")
+	} else if pc.Template == constants.TUITemplate {
+		fmt.Println("   go run .")
+		fmt.Println("   go test ./...")
+	} else if pc.Template == constants.GRPCTemplate {
+		fmt.Println("   go run .")
+		fmt.Printf("   grpcurl -plaintext localhost:%d list\n", pc.GRPCPort)
+		if pc.UseGateway {
+			fmt.Printf("   curl -X POST -d '{\"name\":\"Gopher\"}' http://localhost:%d/v1/greeter/hello\n", pc.APIPort)
+		}
+		fmt.Println("   make generate   # after editing proto/, requires buf")
+	} else if pc.Template == constants.WorkerTemplate {
+		fmt.Println("   go run .")
+		fmt.Println("   go test ./...")
+	} else if pc.Template == constants.LambdaTemplate {
+		fmt.Println("   go run .          # serves the routes on http://localhost:8080")
+		fmt.Println("   make invoke       # runs the handler against events/*.json")
+		fmt.Println("   make bootstrap    # builds bin/bootstrap for provided.al2023")
+	} else if pc.Template == constants.WasmTemplate {
+		if pc.FrontendFramework != "" {
+			fmt.Println("   make dev")
+		} else {
+			fmt.Println("   make serve   # then open http://localhost:8080")
+		}
+		if pc.UseWASI {
+			fmt.Println("   make run-wasi   # requires wasmtime")
+		}
+	} else if pc.Template == constants.LibTemplate {
+		fmt.Println("   go test ./...")
+		fmt.Println("   make bench")
+		fmt.Println("   make fuzz")
+	} else if pc.Template == constants.WorkspaceTemplate {
+		fmt.Println("   gogen add service --template api users")
+		fmt.Println("   gogen add service --template worker mailer")
+		fmt.Println("   make build test")
 	} else {
 		fmt.Println("   go run main.go")
 	}
+
+	if pc.APIStyle == internal.APIStyleGraphQL {
+		fmt.Println("   open http://localhost:$PORT" + internal.GraphQLPlaygroundPath + "   # PORT is set in .env, 8080 by default")
+	}
+
+	if pc.UseObservability {
+		fmt.Println("   curl http://localhost:$PORT/metrics   # PORT is set in .env, 8080 by default")
+	}
 }
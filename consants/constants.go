@@ -1,9 +1,16 @@
 package constants
 
 const (
-	APIDir      = "api"
-	FrontendDir = "frontend"
-	WebTemplate = "web"
-	APITemplate = "api"
-	CLITemplate = "cli"
+	APIDir            = "api"
+	FrontendDir       = "frontend"
+	WebTemplate       = "web"
+	APITemplate       = "api"
+	CLITemplate       = "cli"
+	TUITemplate       = "tui"
+	GRPCTemplate      = "grpc"
+	WorkerTemplate    = "worker"
+	WorkspaceTemplate = "workspace"
+	LibTemplate       = "lib"
+	WasmTemplate      = "wasm"
+	LambdaTemplate    = "lambda"
 )
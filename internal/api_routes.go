@@ -6,121 +6,169 @@ func NewRouterGenerator() *RouterGenerator {
 	return &RouterGenerator{}
 }
 
-func (rg *RouterGenerator) generateStdlibContent() string {
+// apiHandlersContent holds the handlers every router variant registers. They
+// decode, validate and answer through internal/httpx, so errors look the
+// same whichever router serves them.
+const apiHandlersContent = `
+type messageResponse struct {
+	Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+type greetRequest struct {
+	Name string ` + "`" + `json:"name" validate:"required,max=100"` + "`" + `
+}
+
+func greetHandler(w http.ResponseWriter, r *http.Request) error {
+	var req greetRequest
+	if err := httpx.Decode(w, r, &req); err != nil {
+		return err
+	}
+
+	return httpx.Encode(w, http.StatusCreated, messageResponse{Message: "Hello, " + req.Name})
+}
+`
+
+func (rg *RouterGenerator) generateStdlibContent(modulePath string) string {
 	return `package web
 
 import (
-	"fmt"
 	"net/http"
+
+	"` + modulePath + `/internal/health"
+	"` + modulePath + `/internal/httpx"
 )
 
 func SetupRoutes() {
-	http.HandleFunc("/", homeHandler)
-	http.HandleFunc("/health", healthHandler)
+	http.Handle("/", httpx.HandlerFunc(homeHandler))
+	http.Handle("/greetings", allow(http.MethodPost, greetHandler))
+	http.Handle("/livez", health.LiveHandler())
+	http.Handle("/readyz", health.ReadyHandler())
 }
 
-func homeHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprintf(w, "Hello from web server")
+// allow answers requests with any other method than method with a 405.
+func allow(method string, next httpx.HandlerFunc) httpx.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			httpx.MethodNotAllowedHandler().ServeHTTP(w, r)
+			return nil
+		}
+		return next(w, r)
+	}
 }
 
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "OK")
-}`
+func homeHandler(w http.ResponseWriter, r *http.Request) error {
+	// "/" matches every path nothing else does.
+	if r.URL.Path != "/" {
+		httpx.NotFoundHandler().ServeHTTP(w, r)
+		return nil
+	}
+
+	return httpx.Encode(w, http.StatusOK, messageResponse{Message: "Hello from web server"})
+}
+` + apiHandlersContent
 }
 
-func (rg *RouterGenerator) generateChiContent() string {
+func (rg *RouterGenerator) generateChiContent(modulePath string) string {
 	return `package web
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+
+	"` + modulePath + `/internal/health"
+	"` + modulePath + `/internal/httpx"
 )
 
 func SetupRoutes() *chi.Mux {
 	r := chi.NewRouter()
-	
+
 	// Middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
+	r.NotFound(httpx.NotFoundHandler().ServeHTTP)
+	r.MethodNotAllowed(httpx.MethodNotAllowedHandler().ServeHTTP)
+
 	// Routes
-	r.Get("/", homeHandler)
-	r.Get("/health", healthHandler)
+	r.Method(http.MethodGet, "/", httpx.HandlerFunc(homeHandler))
+	r.Method(http.MethodPost, "/greetings", httpx.HandlerFunc(greetHandler))
+	r.Method(http.MethodGet, "/livez", health.LiveHandler())
+	r.Method(http.MethodGet, "/readyz", health.ReadyHandler())
 
 	return r
 }
 
-func homeHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprintf(w, "Hello from web server")
+func homeHandler(w http.ResponseWriter, r *http.Request) error {
+	return httpx.Encode(w, http.StatusOK, messageResponse{Message: "Hello from web server"})
 }
-
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "OK")
-}`
+` + apiHandlersContent
 }
 
-func (rg *RouterGenerator) generateGorillaContent() string {
+func (rg *RouterGenerator) generateGorillaContent(modulePath string) string {
 	return `package web
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
+
+	"` + modulePath + `/internal/health"
+	"` + modulePath + `/internal/httpx"
 )
 
 func SetupRoutes() *mux.Router {
 	r := mux.NewRouter()
 
+	r.NotFoundHandler = httpx.NotFoundHandler()
+	r.MethodNotAllowedHandler = httpx.MethodNotAllowedHandler()
+
 	// Routes
-	r.HandleFunc("/", homeHandler).Methods("GET")
-	r.HandleFunc("/health", healthHandler).Methods("GET")
+	r.Handle("/", httpx.HandlerFunc(homeHandler)).Methods("GET")
+	r.Handle("/greetings", httpx.HandlerFunc(greetHandler)).Methods("POST")
+	r.Handle("/livez", health.LiveHandler()).Methods("GET")
+	r.Handle("/readyz", health.ReadyHandler()).Methods("GET")
 
 	return r
 }
 
-func homeHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprintf(w, "Hello from web server")
+func homeHandler(w http.ResponseWriter, r *http.Request) error {
+	return httpx.Encode(w, http.StatusOK, messageResponse{Message: "Hello from web server"})
 }
-
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "OK")
-}`
+` + apiHandlersContent
 }
 
-func (rg *RouterGenerator) generateHttpRouterContent() string {
+func (rg *RouterGenerator) generateHttpRouterContent(modulePath string) string {
 	return `package web
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+
+	"` + modulePath + `/internal/health"
+	"` + modulePath + `/internal/httpx"
 )
 
 func SetupRoutes() *httprouter.Router {
 	router := httprouter.New()
 
+	router.NotFound = httpx.NotFoundHandler()
+	router.MethodNotAllowed = httpx.MethodNotAllowedHandler()
+
 	// Routes
-	router.GET("/", homeHandler)
-	router.GET("/health", healthHandler)
+	router.Handler(http.MethodGet, "/", httpx.HandlerFunc(homeHandler))
+	router.Handler(http.MethodPost, "/greetings", httpx.HandlerFunc(greetHandler))
+	router.Handler(http.MethodGet, "/livez", health.LiveHandler())
+	router.Handler(http.MethodGet, "/readyz", health.ReadyHandler())
 
 	return router
 }
 
-func homeHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	fmt.Fprintf(w, "Hello from web server")
+func homeHandler(w http.ResponseWriter, r *http.Request) error {
+	return httpx.Encode(w, http.StatusOK, messageResponse{Message: "Hello from web server"})
 }
-
-// healthHandler handles the health check endpoint
-func healthHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "OK")
-}`
+` + apiHandlersContent
 }
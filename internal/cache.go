@@ -0,0 +1,992 @@
+package internal
+
+import (
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// createCacheFiles writes internal/cache into the API module in baseDir.
+// main.go replaces its Default cache with the one FromEnv configures.
+func (pg *ProjectGenerator) createCacheFiles(baseDir string) error {
+	dir := filepath.Join("internal", "cache")
+	files := map[string]string{
+		filepath.Join(dir, "cache.go"):      pg.generateCacheContent(),
+		filepath.Join(dir, "memory.go"):     pg.generateCacheMemoryContent(),
+		filepath.Join(dir, "redis.go"):      pg.generateCacheRedisContent(),
+		filepath.Join(dir, "config.go"):     pg.generateCacheConfigContent(),
+		filepath.Join(dir, "http.go"):       pg.generateCacheHTTPContent(),
+		filepath.Join(dir, "cache_test.go"): pg.generateCacheTestContent(),
+		filepath.Join(dir, "http_test.go"):  pg.generateCacheHTTPTestContent(),
+	}
+
+	if err := writeProjectFiles(baseDir, files); err != nil {
+		return fmt.Errorf("failed to create cache package: %w", err)
+	}
+
+	return nil
+}
+
+// cacheEnvExample lists the settings FromEnv reads, commented out with their
+// defaults, for .env.example.
+const cacheEnvExample = `# Cache
+# CACHE_STORE=memory
+# CACHE_MAX_ENTRIES=10000
+# CACHE_PREFIX=cache:
+`
+
+// homeRoute is the handler of the GET / route in every routes.go variant.
+const homeRoute = "httpx.HandlerFunc(homeHandler)"
+
+// cacheHomeRoute puts the GET / route of routes behind cache.Middleware, so
+// the generated API shows how a route is cached.
+func cacheHomeRoute(routes, modulePath string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "routes.go", routes, parser.ImportsOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse routes.go: %w", err)
+	}
+
+	var edits []sourceEdit
+	if edit, needed := importEdit(fset, file, "time", true); needed {
+		edits = append(edits, edit)
+	}
+	// The last group of routes.go holds the packages of the module.
+	last := file.Imports[len(file.Imports)-1]
+	edits = append(edits, sourceEdit{offset: fset.Position(last.End()).Offset, text: "\n\t" + strconv.Quote(modulePath+"/internal/cache")})
+
+	source := strings.Replace(string(applyEdits([]byte(routes), edits)), homeRoute,
+		"cache.Middleware(cache.Default, time.Minute)("+homeRoute+")", 1)
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return "", fmt.Errorf("failed to format routes.go after adding the cache: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+func (pg *ProjectGenerator) generateCacheContent() string {
+	return `// Package cache keeps values the API would otherwise compute or fetch again,
+// in memory or in Redis, and caches HTTP responses with Middleware.
+//
+// Values are bytes with a time to live. Use GetOrLoad to cache the result of
+// a slow call, concurrent misses of the same key share a single call:
+//
+//	data, err := cache.Default.GetOrLoad(ctx, "user:"+id, time.Minute, func(ctx context.Context) ([]byte, error) {
+//		user, err := db.User(ctx, id)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return json.Marshal(user)
+//	})
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound is returned for keys that are not cached or have expired.
+var ErrNotFound = errors.New("cache: key not found")
+
+// LoadFunc computes the value of a key GetOrLoad did not find.
+type LoadFunc func(ctx context.Context) ([]byte, error)
+
+// Cache stores values by key. A ttl of zero keeps a value until it is
+// deleted or, for the memory cache, evicted.
+type Cache interface {
+	// Get returns the value of key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key, deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// TTL returns how long key has left, zero when it does not expire, or
+	// ErrNotFound.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// GetOrLoad returns the value of key, or calls load and stores what it
+	// returns for ttl. Concurrent calls for a key that is not cached wait
+	// for the same load.
+	GetOrLoad(ctx context.Context, key string, ttl time.Duration, load LoadFunc) ([]byte, error)
+}
+
+// Default is the cache main configures from the environment with FromEnv.
+var Default Cache = NewMemoryCache(DefaultMaxEntries)
+
+// loader implements GetOrLoad on top of the other methods of a Cache.
+type loader struct {
+	group singleflight.Group
+}
+
+func (l *loader) getOrLoad(ctx context.Context, c Cache, key string, ttl time.Duration, load LoadFunc) ([]byte, error) {
+	value, err := c.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		// The cache only saves work, so its failures are not the caller's.
+		slog.WarnContext(ctx, "cache get failed, loading the value", "key", key, "error", err)
+	}
+
+	v, err, _ := l.group.Do(key, func() (any, error) {
+		// Every caller waiting for this load shares the result, so it must
+		// not be cut short because the one that started it went away.
+		ctx := context.WithoutCancel(ctx)
+
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Set(ctx, key, value, ttl); err != nil {
+			slog.WarnContext(ctx, "cache set failed", "key", key, "error", err)
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers may modify what they get, so none of them share a slice.
+	return bytes.Clone(v.([]byte)), nil
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCacheMemoryContent() string {
+	return `package cache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is how many values the memory cache holds when
+// CACHE_MAX_ENTRIES is not set.
+const DefaultMaxEntries = 10000
+
+// MemoryCache keeps values in the process, evicting the least recently used
+// one once it holds its maximum number of entries. Each instance of the API
+// has its own.
+type MemoryCache struct {
+	loader
+
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	// order holds the entries from the most to the least recently used.
+	order *list.List
+	now   func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty cache holding up to maxEntries values.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.lookup(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c.order.MoveToFront(c.entries[key])
+	return bytes.Clone(e.value), nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	e := &memoryEntry{key: key, value: bytes.Clone(value)}
+	if ttl > 0 {
+		e.expires = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = e
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(e)
+	if c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	return nil
+}
+
+func (c *MemoryCache) TTL(_ context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.lookup(key)
+	if !ok {
+		return 0, ErrNotFound
+	}
+	if e.expires.IsZero() {
+		return 0, nil
+	}
+	return e.expires.Sub(c.now()), nil
+}
+
+func (c *MemoryCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load LoadFunc) ([]byte, error) {
+	return c.getOrLoad(ctx, c, key, ttl, load)
+}
+
+// Len returns how many values the cache holds, expired ones included until
+// they are looked up or evicted.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// lookup returns the entry of key, removing it when it has expired. The
+// caller holds the lock.
+func (c *MemoryCache) lookup(key string) (*memoryEntry, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := elem.Value.(*memoryEntry)
+	if !e.expires.IsZero() && !c.now().Before(e.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+	return e, true
+}
+
+func (c *MemoryCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*memoryEntry).key)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCacheRedisContent() string {
+	return `package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache keeps values in Redis, so every instance of the API shares
+// them.
+type RedisCache struct {
+	loader
+
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisCache returns a RedisCache keeping values in client under keys
+// starting with prefix.
+func NewRedisCache(client redis.Cmdable, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.client.Set(ctx, c.prefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set %s: %w", key, err)
+	}
+	return nil
+}
+
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	if err := c.client.Del(ctx, c.prefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (c *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, c.prefix+key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ttl of %s: %w", key, err)
+	}
+
+	// PTTL answers -2 for missing keys and -1 for keys without expiry,
+	// which go-redis passes on as durations of that many nanoseconds.
+	switch ttl {
+	case -2:
+		return 0, ErrNotFound
+	case -1:
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (c *RedisCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load LoadFunc) ([]byte, error) {
+	return c.getOrLoad(ctx, c, key, ttl, load)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCacheConfigContent() string {
+	return `package cache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// FromEnv builds a Cache from these variables:
+//
+//	CACHE_STORE        memory (the default) or redis, which connects to REDIS_URL
+//	CACHE_MAX_ENTRIES  values the memory cache holds, 10000 by default
+//	CACHE_PREFIX       prefix of the Redis keys, cache: by default
+func FromEnv() (Cache, error) {
+	switch store := getenv("CACHE_STORE", "memory"); store {
+	case "memory":
+		maxEntries, err := strconv.Atoi(getenv("CACHE_MAX_ENTRIES", strconv.Itoa(DefaultMaxEntries)))
+		if err != nil || maxEntries <= 0 {
+			return nil, fmt.Errorf("CACHE_MAX_ENTRIES: want a positive number, got %q", os.Getenv("CACHE_MAX_ENTRIES"))
+		}
+		return NewMemoryCache(maxEntries), nil
+	case "redis":
+		url := os.Getenv("REDIS_URL")
+		if url == "" {
+			return nil, fmt.Errorf("CACHE_STORE=redis needs REDIS_URL")
+		}
+		opts, err := redis.ParseURL(url)
+		if err != nil {
+			return nil, fmt.Errorf("REDIS_URL: %w", err)
+		}
+		return NewRedisCache(redis.NewClient(opts), getenv("CACHE_PREFIX", "cache:")), nil
+	default:
+		return nil, fmt.Errorf("CACHE_STORE: unknown store %q, want memory or redis", store)
+	}
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCacheHTTPContent() string {
+	return `package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// response is what Middleware stores for a request.
+type response struct {
+	Status int         ` + "`" + `json:"status"` + "`" + `
+	Header http.Header ` + "`" + `json:"header"` + "`" + `
+	Body   []byte      ` + "`" + `json:"body"` + "`" + `
+}
+
+// Middleware caches the responses next gives to GET requests in c for ttl,
+// by URL, and serves them again to GET and HEAD requests. 200 responses get
+// an ETag unless next set one, and requests whose If-None-Match matches it
+// get 304 Not Modified without a body. An X-Cache header tells whether the
+// response came from the cache.
+//
+// Only 200 responses are cached, and not those marked no-store or private,
+// setting cookies, negotiated on request headers listed in Vary or answering
+// requests with an Authorization header.
+// Responses are buffered, so do not put streaming handlers behind it.
+func Middleware(c Cache, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.Header.Get("Authorization") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := "http:" + r.URL.RequestURI()
+			if res, ok := lookup(r.Context(), c, key); ok {
+				w.Header().Set("X-Cache", "HIT")
+				res.write(w, r)
+				return
+			}
+
+			if r.Method == http.MethodHead {
+				// The body of the response is missing, it cannot be stored.
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &recorder{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			res := &response{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()}
+			if res.Status == http.StatusOK && res.Header.Get("ETag") == "" {
+				res.Header.Set("ETag", etag(res.Body))
+			}
+			if res.cacheable() {
+				store(r.Context(), c, key, res, ttl)
+			}
+
+			w.Header().Set("X-Cache", "MISS")
+			res.write(w, r)
+		})
+	}
+}
+
+func lookup(ctx context.Context, c Cache, key string) (*response, bool) {
+	data, err := c.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.WarnContext(ctx, "cache get failed, serving the request", "key", key, "error", err)
+		}
+		return nil, false
+	}
+
+	var res response
+	if err := json.Unmarshal(data, &res); err != nil {
+		slog.WarnContext(ctx, "cached response is invalid, serving the request", "key", key, "error", err)
+		return nil, false
+	}
+	return &res, true
+}
+
+func store(ctx context.Context, c Cache, key string, res *response, ttl time.Duration) {
+	data, err := json.Marshal(res)
+	if err == nil {
+		err = c.Set(ctx, key, data, ttl)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "cache set failed", "key", key, "error", err)
+	}
+}
+
+func (res *response) cacheable() bool {
+	// The key is the URL alone, a response that varies on request headers
+	// such as Accept or Accept-Encoding would be served to every client.
+	if res.Status != http.StatusOK || res.Header.Get("Set-Cookie") != "" || res.Header.Get("Vary") != "" {
+		return false
+	}
+	cacheControl := strings.ToLower(res.Header.Get("Cache-Control"))
+	return !strings.Contains(cacheControl, "no-store") && !strings.Contains(cacheControl, "private")
+}
+
+// write sends res, or 304 Not Modified when it matches the If-None-Match
+// header of r.
+func (res *response) write(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	for name, values := range res.Header {
+		header[name] = values
+	}
+
+	if tag := res.Header.Get("ETag"); tag != "" && matches(r.Header.Get("If-None-Match"), tag) {
+		header.Del("Content-Length")
+		header.Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(res.Status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(res.Body)
+	}
+}
+
+// etag returns a strong entity tag derived from body.
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return ` + "`" + `"` + "`" + ` + base64.RawURLEncoding.EncodeToString(sum[:16]) + ` + "`" + `"` + "`" + `
+}
+
+// matches reports whether the If-None-Match header value lists tag. The
+// comparison is weak, as RFC 9110 asks for If-None-Match.
+func matches(ifNoneMatch, tag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// recorder buffers the response of the handler behind Middleware.
+type recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = code, true
+	}
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(b)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCacheTestContent() string {
+	return `package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// caches returns every Cache, each with a clock moved by its advance func.
+func caches(t *testing.T) map[string]struct {
+	cache   Cache
+	advance func(time.Duration)
+} {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	memory := NewMemoryCache(100)
+	memory.now = func() time.Time { return now }
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]struct {
+		cache   Cache
+		advance func(time.Duration)
+	}{
+		"memory": {memory, func(d time.Duration) { now = now.Add(d) }},
+		"redis":  {NewRedisCache(client, "test:"), server.FastForward},
+	}
+}
+
+func TestCaches(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range caches(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := c.cache.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get(missing) error = %v, want ErrNotFound", err)
+			}
+			if _, err := c.cache.TTL(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("TTL(missing) error = %v, want ErrNotFound", err)
+			}
+
+			if err := c.cache.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.cache.Set(ctx, "b", []byte("2"), 0); err != nil {
+				t.Fatal(err)
+			}
+
+			if value, err := c.cache.Get(ctx, "a"); err != nil || string(value) != "1" {
+				t.Fatalf("Get(a) = %q, %v, want 1", value, err)
+			}
+			if ttl, err := c.cache.TTL(ctx, "a"); err != nil || ttl <= 0 || ttl > time.Minute {
+				t.Fatalf("TTL(a) = %s, %v, want up to 1m", ttl, err)
+			}
+			if ttl, err := c.cache.TTL(ctx, "b"); err != nil || ttl != 0 {
+				t.Fatalf("TTL(b) = %s, %v, want 0", ttl, err)
+			}
+
+			c.advance(time.Minute)
+			if _, err := c.cache.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get(a) after its ttl error = %v, want ErrNotFound", err)
+			}
+			if _, err := c.cache.Get(ctx, "b"); err != nil {
+				t.Errorf("Get(b) error = %v, values without ttl must not expire", err)
+			}
+
+			if err := c.cache.Delete(ctx, "b"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.cache.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get(b) after Delete error = %v, want ErrNotFound", err)
+			}
+			if err := c.cache.Delete(ctx, "b"); err != nil {
+				t.Errorf("Delete of a missing key error = %v", err)
+			}
+		})
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range caches(t) {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int32
+			release := make(chan struct{})
+			load := func(context.Context) ([]byte, error) {
+				calls.Add(1)
+				<-release
+				return []byte("loaded"), nil
+			}
+
+			var wg sync.WaitGroup
+			values := make([][]byte, 10)
+			for i := range values {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					values[i], _ = c.cache.GetOrLoad(ctx, "k", time.Minute, load)
+				}(i)
+			}
+			// Let every caller reach the load before it returns.
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if got := calls.Load(); got != 1 {
+				t.Errorf("load called %d times, want 1", got)
+			}
+			for i, value := range values {
+				if string(value) != "loaded" {
+					t.Errorf("caller %d got %q, want loaded", i, value)
+				}
+			}
+
+			value, err := c.cache.GetOrLoad(ctx, "k", time.Minute, func(context.Context) ([]byte, error) {
+				t.Error("load called for a cached key")
+				return nil, nil
+			})
+			if err != nil || string(value) != "loaded" {
+				t.Errorf("GetOrLoad of a cached key = %q, %v", value, err)
+			}
+		})
+	}
+}
+
+func TestGetOrLoadError(t *testing.T) {
+	c := NewMemoryCache(10)
+	failure := errors.New("database down")
+
+	_, err := c.GetOrLoad(context.Background(), "k", time.Minute, func(context.Context) ([]byte, error) {
+		return nil, failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("GetOrLoad error = %v, want %v", err, failure)
+	}
+	if c.Len() != 0 {
+		t.Error("a failed load was cached")
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, err := c.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("b was used least recently and should have been evicted, error = %v", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := c.Get(ctx, key); err != nil {
+			t.Errorf("Get(%s) error = %v", key, err)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len = %d, want 2", c.Len())
+	}
+}
+
+func TestMemoryCacheCopiesValues(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	value := []byte("abc")
+	_ = c.Set(ctx, "k", value, 0)
+	value[0] = 'x'
+
+	got, _ := c.Get(ctx, "k")
+	got[1] = 'y'
+
+	if again, _ := c.Get(ctx, "k"); string(again) != "abc" {
+		t.Errorf("cached value = %q, want abc", again)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("CACHE_STORE", "")
+	if c, err := FromEnv(); err != nil {
+		t.Fatal(err)
+	} else if _, ok := c.(*MemoryCache); !ok {
+		t.Errorf("default cache is %T, want *MemoryCache", c)
+	}
+
+	for env, want := range map[string]string{
+		"CACHE_STORE":       "memcached",
+		"CACHE_MAX_ENTRIES": "-1",
+	} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, want)
+			if _, err := FromEnv(); err == nil {
+				t.Errorf("FromEnv with %s=%s: expected an error", env, want)
+			}
+		})
+	}
+
+	t.Setenv("CACHE_STORE", "redis")
+	t.Setenv("REDIS_URL", "")
+	if _, err := FromEnv(); err == nil {
+		t.Error("CACHE_STORE=redis without REDIS_URL: expected an error")
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCacheHTTPTestContent() string {
+	return `package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// counter answers with the number of requests it served.
+func counter(calls *int, header ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte{byte('0' + *calls)})
+	})
+}
+
+func serve(h http.Handler, method, target string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls int
+	h := Middleware(NewMemoryCache(10), time.Minute)(counter(&calls))
+
+	first := serve(h, http.MethodGet, "/items?page=1")
+	if first.Code != http.StatusOK || first.Body.String() != "1" || first.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("first response = %d %q %s", first.Code, first.Body, first.Header().Get("X-Cache"))
+	}
+	tag := first.Header().Get("ETag")
+	if tag == "" {
+		t.Fatal("response has no ETag")
+	}
+
+	second := serve(h, http.MethodGet, "/items?page=1")
+	if second.Body.String() != "1" || second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("second response = %q %s, want the cached one", second.Body, second.Header().Get("X-Cache"))
+	}
+	if second.Header().Get("ETag") != tag || second.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("cached response headers = %v", second.Header())
+	}
+
+	head := serve(h, http.MethodHead, "/items?page=1")
+	if head.Code != http.StatusOK || head.Body.Len() != 0 || head.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("HEAD response = %d %q %s", head.Code, head.Body, head.Header().Get("X-Cache"))
+	}
+
+	if other := serve(h, http.MethodGet, "/items?page=2"); other.Body.String() != "2" {
+		t.Errorf("another query got %q, want its own response", other.Body)
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
+
+func TestMiddlewareNotModified(t *testing.T) {
+	var calls int
+	h := Middleware(NewMemoryCache(10), time.Minute)(counter(&calls))
+
+	tag := serve(h, http.MethodGet, "/").Header().Get("ETag")
+
+	for _, ifNoneMatch := range []string{tag, "W/" + tag, ` + "`" + `"other", ` + "`" + ` + tag, "*"} {
+		w := serve(h, http.MethodGet, "/", "If-None-Match", ifNoneMatch)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: got %d %q, want 304 without body", ifNoneMatch, w.Code, w.Body)
+		}
+		if w.Header().Get("ETag") != tag {
+			t.Errorf("If-None-Match %s: ETag = %q, want %q", ifNoneMatch, w.Header().Get("ETag"), tag)
+		}
+	}
+
+	if w := serve(h, http.MethodGet, "/", "If-None-Match", ` + "`" + `"other"` + "`" + `); w.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match: status = %d, want 200", w.Code)
+	}
+}
+
+func TestMiddlewareKeepsHandlerETag(t *testing.T) {
+	var calls int
+	h := Middleware(NewMemoryCache(10), time.Minute)(counter(&calls, "ETag", ` + "`" + `"v1"` + "`" + `))
+
+	if tag := serve(h, http.MethodGet, "/").Header().Get("ETag"); tag != ` + "`" + `"v1"` + "`" + ` {
+		t.Errorf("ETag = %s, want the handler's", tag)
+	}
+	if w := serve(h, http.MethodGet, "/", "If-None-Match", ` + "`" + `"v1"` + "`" + `); w.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", w.Code)
+	}
+}
+
+func TestMiddlewareSkips(t *testing.T) {
+	tests := map[string]struct {
+		handler func(*int) http.Handler
+		method  string
+		header  []string
+	}{
+		"post": {
+			handler: func(calls *int) http.Handler { return counter(calls) },
+			method:  http.MethodPost,
+		},
+		"authorization": {
+			handler: func(calls *int) http.Handler { return counter(calls) },
+			method:  http.MethodGet,
+			header:  []string{"Authorization", "Bearer token"},
+		},
+		"no-store": {
+			handler: func(calls *int) http.Handler { return counter(calls, "Cache-Control", "no-store") },
+			method:  http.MethodGet,
+		},
+		"private": {
+			handler: func(calls *int) http.Handler { return counter(calls, "Cache-Control", "private, max-age=60") },
+			method:  http.MethodGet,
+		},
+		"cookie": {
+			handler: func(calls *int) http.Handler { return counter(calls, "Set-Cookie", "session=1") },
+			method:  http.MethodGet,
+		},
+		"vary": {
+			handler: func(calls *int) http.Handler { return counter(calls, "Vary", "Accept-Encoding") },
+			method:  http.MethodGet,
+		},
+		"error": {
+			handler: func(calls *int) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					*calls++
+					http.Error(w, "boom", http.StatusInternalServerError)
+				})
+			},
+			method: http.MethodGet,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			h := Middleware(NewMemoryCache(10), time.Minute)(tt.handler(&calls))
+
+			serve(h, tt.method, "/", tt.header...)
+			serve(h, tt.method, "/", tt.header...)
+			if calls != 2 {
+				t.Errorf("handler called %d times, want 2", calls)
+			}
+		})
+	}
+}
+
+func TestMiddlewareExpires(t *testing.T) {
+	now := time.Now()
+	c := NewMemoryCache(10)
+	c.now = func() time.Time { return now }
+
+	var calls int
+	h := Middleware(c, time.Minute)(counter(&calls))
+
+	serve(h, http.MethodGet, "/")
+	now = now.Add(time.Minute)
+	if w := serve(h, http.MethodGet, "/"); w.Body.String() != "2" || w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("response after the ttl = %q %s, want a fresh one", w.Body, w.Header().Get("X-Cache"))
+	}
+}
+`
+}
@@ -0,0 +1,498 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	CIGitHub = "github"
+	CIGitLab = "gitlab"
+)
+
+// golangciLintVersion matches the version gogen itself is linted with.
+const golangciLintVersion = "v2.0.0"
+
+type CIConfig struct {
+	Provider          string
+	Template          string
+	Runtime           string
+	FrontendFramework string
+	UseDocker         bool
+}
+
+func IsCIProvider(provider string) bool {
+	return provider == CIGitHub || provider == CIGitLab
+}
+
+func (pg *ProjectGenerator) CreateCIConfig(dirName string, config *CIConfig) error {
+	switch config.Provider {
+	case CIGitHub:
+		workflowsDir := filepath.Join(dirName, ".github", "workflows")
+		if err := os.MkdirAll(workflowsDir, 0750); err != nil {
+			return fmt.Errorf("failed to create workflows directory: %w", err)
+		}
+
+		if err := os.WriteFile(filepath.Join(workflowsDir, "ci.yml"), []byte(config.generateGitHubWorkflow()), 0600); err != nil {
+			return fmt.Errorf("failed to create ci.yml: %w", err)
+		}
+
+		if config.Template == constants.CLITemplate {
+			if err := os.WriteFile(filepath.Join(workflowsDir, "release.yml"), []byte(config.generateGitHubRelease()), 0600); err != nil {
+				return fmt.Errorf("failed to create release.yml: %w", err)
+			}
+		}
+	case CIGitLab:
+		goVersion := goVersionFromMod(filepath.Join(dirName, config.goDir(), "go.mod"))
+		if err := os.WriteFile(filepath.Join(dirName, ".gitlab-ci.yml"), []byte(config.generateGitLabCI(goVersion)), 0600); err != nil {
+			return fmt.Errorf("failed to create .gitlab-ci.yml: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported CI provider: %s", config.Provider)
+	}
+
+	return nil
+}
+
+// goDir is where the Go module lives relative to the repository root.
+func (ci *CIConfig) goDir() string {
+	if ci.Template == constants.WebTemplate {
+		return constants.APIDir
+	}
+	return "."
+}
+
+func (ci *CIConfig) hasFrontend() bool {
+	return ci.Template == constants.WebTemplate && ci.FrontendFramework != ""
+}
+
+// dockerContexts lists the directories that get a Dockerfile from gogen new.
+// The api, grpc and worker templates write theirs at the root.
+func (ci *CIConfig) dockerContexts() []string {
+	if !ci.UseDocker {
+		return nil
+	}
+
+	switch ci.Template {
+	case constants.WebTemplate:
+		contexts := []string{constants.APIDir}
+		if ci.hasFrontend() {
+			contexts = append(contexts, constants.FrontendDir)
+		}
+		return contexts
+	case constants.APITemplate, constants.GRPCTemplate, constants.WorkerTemplate:
+		return []string{"."}
+	default:
+		return nil
+	}
+}
+
+// imageName names the image built from context, after the template when the
+// Dockerfile is at the root.
+func (ci *CIConfig) imageName(context string) string {
+	if context == "." {
+		return ci.Template
+	}
+	return context
+}
+
+func (ci *CIConfig) lockFile() string {
+	if ci.Runtime == bun {
+		return "bun.lock"
+	}
+	return "package-lock.json"
+}
+
+func (ci *CIConfig) generateGitHubWorkflow() string {
+	goDir := ci.goDir()
+
+	var b strings.Builder
+	b.WriteString(`name: CI
+
+on:
+  push:
+    branches: [main]
+  pull_request:
+    branches: [main]
+
+jobs:
+  go:
+    name: Go
+    runs-on: ubuntu-latest
+`)
+	if goDir != "." {
+		fmt.Fprintf(&b, `    defaults:
+      run:
+        working-directory: %s
+`, goDir)
+	}
+	fmt.Fprintf(&b, `
+    steps:
+      - name: Checkout code
+        uses: actions/checkout@v4
+
+      - name: Set up Go
+        uses: actions/setup-go@v5
+        with:
+          go-version-file: %[1]s
+          cache-dependency-path: %[2]s
+
+      - name: Verify dependencies
+        run: go mod verify
+
+      - name: Run go vet
+        run: go vet ./...
+
+      - name: Run golangci-lint
+        uses: golangci/golangci-lint-action@v7
+        with:
+          version: %[3]s
+          working-directory: %[4]s
+          args: --timeout=5m
+
+      - name: Test
+        run: go test -race ./...
+
+      - name: Build
+        run: go build -v ./...
+`, filepath.ToSlash(filepath.Join(goDir, "go.mod")), filepath.ToSlash(filepath.Join(goDir, "go.sum")), golangciLintVersion, goDir)
+
+	if ci.Template == constants.LibTemplate {
+		b.WriteString(`
+  apicompat:
+    name: API compatibility
+    runs-on: ubuntu-latest
+
+    steps:
+      - name: Checkout code
+        uses: actions/checkout@v4
+        with:
+          fetch-depth: 0
+
+      - name: Set up Go
+        uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+
+      # Fails when the exported API breaks compatibility with the latest
+      # released version in a way its version number does not allow.
+      - name: Check API compatibility
+        run: go run golang.org/x/exp/cmd/gorelease@latest
+`)
+	}
+
+	if ci.hasFrontend() {
+		fmt.Fprintf(&b, `
+  frontend:
+    name: Frontend
+    runs-on: ubuntu-latest
+    defaults:
+      run:
+        working-directory: %s
+
+    steps:
+      - name: Checkout code
+        uses: actions/checkout@v4
+`, constants.FrontendDir)
+
+		if ci.Runtime == bun {
+			b.WriteString(`
+      - name: Set up Bun
+        uses: oven-sh/setup-bun@v2
+
+      - name: Install dependencies
+        run: bun install --frozen-lockfile
+
+      - name: Lint
+        run: if grep -q '"lint"' package.json; then bun run lint; fi
+
+      - name: Test
+        run: if grep -q '"test"' package.json; then bun run test; fi
+
+      - name: Build
+        run: bun run build
+`)
+		} else {
+			fmt.Fprintf(&b, `
+      - name: Set up Node.js
+        uses: actions/setup-node@v4
+        with:
+          node-version: 22
+          cache: npm
+          cache-dependency-path: %s/%s
+
+      - name: Install dependencies
+        run: npm ci
+
+      - name: Lint
+        run: npm run lint --if-present
+
+      - name: Test
+        run: npm test --if-present
+
+      - name: Build
+        run: npm run build
+`, constants.FrontendDir, ci.lockFile())
+		}
+	}
+
+	if contexts := ci.dockerContexts(); len(contexts) > 0 {
+		var matrix strings.Builder
+		for _, context := range contexts {
+			fmt.Fprintf(&matrix, "          - service: %s\n            context: %s\n", ci.imageName(context), context)
+		}
+
+		needs := "go"
+		if ci.hasFrontend() {
+			needs = "[go, frontend]"
+		}
+
+		fmt.Fprintf(&b, `
+  docker:
+    name: Docker image (${{ matrix.service }})
+    runs-on: ubuntu-latest
+    needs: %s
+    strategy:
+      matrix:
+        include:
+%s
+    steps:
+      - name: Checkout code
+        uses: actions/checkout@v4
+
+      - name: Set up Docker Buildx
+        uses: docker/setup-buildx-action@v3
+
+      - name: Build image
+        uses: docker/build-push-action@v6
+        with:
+          context: ${{ matrix.context }}
+          push: false
+          tags: ${{ github.event.repository.name }}-${{ matrix.service }}:${{ github.sha }}
+          cache-from: type=gha,scope=${{ matrix.service }}
+          cache-to: type=gha,mode=max,scope=${{ matrix.service }}
+`, needs, matrix.String())
+	}
+
+	return b.String()
+}
+
+func (ci *CIConfig) generateGitHubRelease() string {
+	return `name: Release
+
+on:
+  push:
+    tags: ["v*"]
+
+permissions:
+  contents: write
+
+jobs:
+  release:
+    name: Release
+    runs-on: ubuntu-latest
+
+    steps:
+      - name: Checkout code
+        uses: actions/checkout@v4
+        with:
+          fetch-depth: 0
+
+      - name: Set up Go
+        uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+
+      - name: Run GoReleaser
+        uses: goreleaser/goreleaser-action@v6
+        with:
+          distribution: goreleaser
+          version: "~> v2"
+          args: release --clean
+        env:
+          GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+`
+}
+
+func (ci *CIConfig) generateGitLabCI(goVersion string) string {
+	goDir := ci.goDir()
+
+	var b strings.Builder
+	b.WriteString(`stages:
+  - lint
+  - test
+  - build
+`)
+	if ci.Template == constants.CLITemplate {
+		b.WriteString("  - release\n")
+	}
+
+	fmt.Fprintf(&b, `
+variables:
+  GOPATH: $CI_PROJECT_DIR/.go
+  GOCACHE: $CI_PROJECT_DIR/.cache/go-build
+
+.go:
+  image: golang:%[1]s
+  cache:
+    key:
+      files:
+        - %[2]s
+    paths:
+      - .go/pkg/mod/
+      - .cache/go-build/
+  before_script:
+    - cd %[3]s
+
+go:vet:
+  extends: .go
+  stage: lint
+  script:
+    - go mod verify
+    - go vet ./...
+
+go:lint:
+  stage: lint
+  image: golangci/golangci-lint:%[4]s
+  script:
+    - cd %[3]s
+    - golangci-lint run --timeout=5m
+
+go:test:
+  extends: .go
+  stage: test
+  script:
+    - go test -race ./...
+
+go:build:
+  extends: .go
+  stage: build
+  script:
+    - go build -v ./...
+`, goVersion, filepath.ToSlash(filepath.Join(goDir, "go.sum")), goDir, golangciLintVersion)
+
+	if ci.Template == constants.LibTemplate {
+		b.WriteString(`
+go:apicompat:
+  extends: .go
+  stage: test
+  variables:
+    GIT_DEPTH: 0
+  script:
+    - go run golang.org/x/exp/cmd/gorelease@latest
+`)
+	}
+
+	if ci.hasFrontend() {
+		if ci.Runtime == bun {
+			fmt.Fprintf(&b, `
+.frontend:
+  image: oven/bun:1
+  cache:
+    key:
+      files:
+        - %[1]s/%[2]s
+    paths:
+      - .bun/
+  variables:
+    BUN_INSTALL_CACHE_DIR: $CI_PROJECT_DIR/.bun
+  before_script:
+    - cd %[1]s
+    - bun install --frozen-lockfile
+
+frontend:lint:
+  extends: .frontend
+  stage: lint
+  script:
+    - if grep -q '"lint"' package.json; then bun run lint; fi
+
+frontend:test:
+  extends: .frontend
+  stage: test
+  script:
+    - if grep -q '"test"' package.json; then bun run test; fi
+
+frontend:build:
+  extends: .frontend
+  stage: build
+  script:
+    - bun run build
+  artifacts:
+    paths:
+      - %[1]s/%[3]s/
+`, constants.FrontendDir, ci.lockFile(), frontendDistDir(ci.FrontendFramework))
+		} else {
+			fmt.Fprintf(&b, `
+.frontend:
+  image: node:22
+  cache:
+    key:
+      files:
+        - %[1]s/%[2]s
+    paths:
+      - .npm/
+  before_script:
+    - cd %[1]s
+    - npm ci --cache ../.npm --prefer-offline
+
+frontend:lint:
+  extends: .frontend
+  stage: lint
+  script:
+    - npm run lint --if-present
+
+frontend:test:
+  extends: .frontend
+  stage: test
+  script:
+    - npm test --if-present
+
+frontend:build:
+  extends: .frontend
+  stage: build
+  script:
+    - npm run build
+  artifacts:
+    paths:
+      - %[1]s/%[3]s/
+`, constants.FrontendDir, ci.lockFile(), frontendDistDir(ci.FrontendFramework))
+		}
+	}
+
+	for _, context := range ci.dockerContexts() {
+		fmt.Fprintf(&b, `
+docker:%[1]s:
+  stage: build
+  image: docker:27
+  services:
+    - docker:27-dind
+  variables:
+    DOCKER_TLS_CERTDIR: "/certs"
+  script:
+    - docker build -t $CI_REGISTRY_IMAGE/%[1]s:$CI_COMMIT_SHORT_SHA %[2]s
+`, ci.imageName(context), context)
+	}
+
+	if ci.Template == constants.CLITemplate {
+		b.WriteString(`
+release:
+  stage: release
+  image:
+    name: goreleaser/goreleaser:latest
+    entrypoint: [""]
+  rules:
+    - if: $CI_COMMIT_TAG
+  variables:
+    GIT_DEPTH: 0
+  # Requires a GITLAB_TOKEN CI/CD variable with api scope.
+  script:
+    - goreleaser release --clean
+`)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,552 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cliCommandsDir = "commands"
+
+var cliNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// cliFlagTypes maps the type in a --flag spec to the urfave/cli flag type and
+// the cli.Context getter used to read it.
+var cliFlagTypes = map[string]struct {
+	flag   string
+	getter string
+}{
+	"string":   {"StringFlag", "String"},
+	"int":      {"IntFlag", "Int"},
+	"int64":    {"Int64Flag", "Int64"},
+	"uint":     {"UintFlag", "Uint"},
+	"float64":  {"Float64Flag", "Float64"},
+	"bool":     {"BoolFlag", "Bool"},
+	"duration": {"DurationFlag", "Duration"},
+}
+
+type CLICommandFlag struct {
+	Name string
+	Type string
+	// Value is the Go literal for the default, empty for the zero value.
+	Value string
+	// Printed is the default as fmt prints it, used by the generated test.
+	Printed string
+}
+
+type CLICommandConfig struct {
+	Name  string
+	Usage string
+	Flags []CLICommandFlag
+}
+
+// NewCLICommandConfig validates the command name and parses flag specs of the
+// form name[:type[:default]], type defaults to string.
+func NewCLICommandConfig(name, usage string, flagSpecs []string) (*CLICommandConfig, error) {
+	if !cliNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid command name %q: use lowercase letters, digits and dashes", name)
+	}
+
+	if usage == "" {
+		usage = "Run the " + name + " command"
+	}
+
+	config := &CLICommandConfig{Name: name, Usage: usage}
+	seen := map[string]bool{}
+
+	for _, spec := range flagSpecs {
+		flag, err := parseCLICommandFlag(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[flag.Name] {
+			return nil, fmt.Errorf("duplicate flag %q", flag.Name)
+		}
+		seen[flag.Name] = true
+		config.Flags = append(config.Flags, flag)
+	}
+
+	return config, nil
+}
+
+func parseCLICommandFlag(spec string) (CLICommandFlag, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	flag := CLICommandFlag{Name: parts[0], Type: "string"}
+	if len(parts) > 1 && parts[1] != "" {
+		flag.Type = parts[1]
+	}
+
+	if !cliNamePattern.MatchString(flag.Name) {
+		return flag, fmt.Errorf("invalid flag name %q in %q", flag.Name, spec)
+	}
+	if _, ok := cliFlagTypes[flag.Type]; !ok {
+		return flag, fmt.Errorf("unsupported flag type %q in %q. Supported types: %s", flag.Type, spec, strings.Join(sortedKeys(cliFlagTypes), ", "))
+	}
+
+	def := ""
+	if len(parts) > 2 {
+		def = parts[2]
+	}
+
+	var value interface{}
+	var err error
+
+	switch flag.Type {
+	case "string":
+		value = def
+		if def != "" {
+			flag.Value = strconv.Quote(def)
+		}
+	case "int":
+		value, err = parseCLIDefault(def, 0, func(s string) (interface{}, error) { return strconv.Atoi(s) })
+	case "int64":
+		value, err = parseCLIDefault(def, int64(0), func(s string) (interface{}, error) { return strconv.ParseInt(s, 10, 64) })
+	case "uint":
+		value, err = parseCLIDefault(def, uint(0), func(s string) (interface{}, error) {
+			n, err := strconv.ParseUint(s, 10, 0)
+			return uint(n), err
+		})
+	case "float64":
+		value, err = parseCLIDefault(def, float64(0), func(s string) (interface{}, error) { return strconv.ParseFloat(s, 64) })
+	case "bool":
+		value, err = parseCLIDefault(def, false, func(s string) (interface{}, error) { return strconv.ParseBool(s) })
+	case "duration":
+		value, err = parseCLIDefault(def, time.Duration(0), func(s string) (interface{}, error) { return time.ParseDuration(s) })
+	}
+	if err != nil {
+		return flag, fmt.Errorf("invalid default for flag %q: %w", flag.Name, err)
+	}
+
+	flag.Printed = fmt.Sprint(value)
+	if flag.Type != "string" && def != "" {
+		flag.Value = goLiteral(value)
+	}
+
+	return flag, nil
+}
+
+func parseCLIDefault(def string, zero interface{}, parse func(string) (interface{}, error)) (interface{}, error) {
+	if def == "" {
+		return zero, nil
+	}
+	return parse(def)
+}
+
+// goLiteral renders a parsed default as Go source, durations are written in
+// the largest unit that divides them evenly.
+func goLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case time.Duration:
+		units := []struct {
+			name string
+			unit time.Duration
+		}{
+			{"Hour", time.Hour},
+			{"Minute", time.Minute},
+			{"Second", time.Second},
+			{"Millisecond", time.Millisecond},
+			{"Microsecond", time.Microsecond},
+		}
+		for _, u := range units {
+			if v%u.unit == 0 {
+				return fmt.Sprintf("%d * time.%s", v/u.unit, u.name)
+			}
+		}
+		return fmt.Sprintf("%d * time.Nanosecond", v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (cc *CLICommandConfig) funcName() string {
+	var b strings.Builder
+	for _, part := range strings.Split(cc.Name, "-") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+func (cc *CLICommandConfig) fileName() string {
+	return strings.ReplaceAll(cc.Name, "-", "_")
+}
+
+func (cc *CLICommandConfig) usesDuration() bool {
+	for _, flag := range cc.Flags {
+		if flag.Type == "duration" && flag.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// cliCommandsSlice is the []*cli.Command literal the new command is added to.
+type cliCommandsSlice struct {
+	path    string
+	file    *ast.File
+	fset    *token.FileSet
+	lit     *ast.CompositeLit
+	appFunc string
+}
+
+// CreateCLICommand adds a subcommand to a urfave/cli project in dirName: the
+// command literal goes into the app's Commands slice, its action into
+// commands/<name>.go and a test into <name>_test.go.
+func (pg *ProjectGenerator) CreateCLICommand(dirName string, config *CLICommandConfig) error {
+	modulePath, err := readModulePath(filepath.Join(dirName, "go.mod"))
+	if err != nil {
+		return err
+	}
+
+	target, err := findCLICommandsSlice(dirName)
+	if err != nil {
+		return err
+	}
+
+	if target.hasCommand(config.Name) {
+		return fmt.Errorf("command %q already exists in %s", config.Name, target.path)
+	}
+
+	actionPath := filepath.Join(dirName, cliCommandsDir, config.fileName()+".go")
+	if _, err := os.Stat(actionPath); err == nil {
+		return fmt.Errorf("%s already exists", actionPath)
+	}
+
+	if err := target.insert(config, modulePath+"/"+cliCommandsDir); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dirName, cliCommandsDir), 0750); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", cliCommandsDir, err)
+	}
+
+	if err := writeGoFile(actionPath, config.generateAction()); err != nil {
+		return err
+	}
+
+	if target.appFunc == "" {
+		fmt.Printf("Warning: the Commands slice in %s is not returned by a func() *cli.App, skipping the test\n", target.path)
+		return nil
+	}
+
+	return writeGoFile(filepath.Join(dirName, config.fileName()+"_test.go"), config.generateTest(target.appFunc))
+}
+
+func readModulePath(goModPath string) (string, error) {
+	file, err := os.Open(filepath.Clean(goModPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to open go.mod, run the command from the project root: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+
+	return "", fmt.Errorf("no module directive found in %s", goModPath)
+}
+
+func writeGoFile(path, content string) error {
+	source, err := format.Source([]byte(content))
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, source, 0600); err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return nil
+}
+
+// findCLICommandsSlice looks through the main package in dirName for a
+// Commands: []*cli.Command{...} field.
+func findCLICommandsSlice(dirName string) (*cliCommandsSlice, error) {
+	paths, err := filepath.Glob(filepath.Join(dirName, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		target := &cliCommandsSlice{path: path, file: file, fset: fset}
+		for _, decl := range file.Decls {
+			ast.Inspect(decl, func(n ast.Node) bool {
+				if target.lit != nil {
+					return false
+				}
+				kv, ok := n.(*ast.KeyValueExpr)
+				if !ok {
+					return true
+				}
+				if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Commands" {
+					return true
+				}
+				if lit, ok := kv.Value.(*ast.CompositeLit); ok && isCLICommandSlice(lit.Type) {
+					target.lit = lit
+					target.appFunc = appConstructor(decl)
+				}
+				return false
+			})
+			if target.lit != nil {
+				return target, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no urfave/cli Commands slice found in %s, gogen generate command only supports projects created with --cli-framework urfave", dirName)
+}
+
+func isCLICommandSlice(expr ast.Expr) bool {
+	array, ok := expr.(*ast.ArrayType)
+	if !ok || array.Len != nil {
+		return false
+	}
+	star, ok := array.Elt.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Command" {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "cli"
+}
+
+// appConstructor returns the name of decl when it is a func() *cli.App, the
+// generated test builds the app through it.
+func appConstructor(decl ast.Decl) string {
+	fn, ok := decl.(*ast.FuncDecl)
+	if !ok || fn.Recv != nil || fn.Type.Params.NumFields() != 0 || fn.Type.Results.NumFields() != 1 {
+		return ""
+	}
+	star, ok := fn.Type.Results.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return ""
+	}
+	if sel, ok := star.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "App" {
+		return fn.Name.Name
+	}
+	return ""
+}
+
+func (cs *cliCommandsSlice) hasCommand(name string) bool {
+	for _, elt := range cs.lit.Elts {
+		lit, ok := elt.(*ast.CompositeLit)
+		if !ok {
+			if unary, isUnary := elt.(*ast.UnaryExpr); isUnary {
+				lit, ok = unary.X.(*ast.CompositeLit)
+			}
+		}
+		if !ok {
+			continue
+		}
+		for _, field := range lit.Elts {
+			kv, ok := field.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok || (key.Name != "Name" && key.Name != "Aliases") {
+				continue
+			}
+			found := false
+			ast.Inspect(kv.Value, func(n ast.Node) bool {
+				if basic, ok := n.(*ast.BasicLit); ok && basic.Kind == token.STRING {
+					if value, err := strconv.Unquote(basic.Value); err == nil && value == name {
+						found = true
+					}
+				}
+				return !found
+			})
+			if found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type sourceEdit struct {
+	offset int
+	text   string
+}
+
+// insert splices the command literal and the missing imports into the file
+// at offsets taken from the AST, then gofmts the result. Splicing text keeps
+// the comments in the file where they are.
+func (cs *cliCommandsSlice) insert(config *CLICommandConfig, commandsImport string) error {
+	source, err := os.ReadFile(cs.path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", cs.path, err)
+	}
+
+	command := config.generateCommandLiteral()
+	rbrace := cs.fset.Position(cs.lit.Rbrace)
+	if n := len(cs.lit.Elts); n > 0 && cs.fset.Position(cs.lit.Elts[n-1].End()).Line == rbrace.Line {
+		command = ", " + command
+	} else {
+		command += ",\n"
+	}
+	edits := []sourceEdit{{offset: rbrace.Offset, text: command}}
+
+	if edit, needed := importEdit(cs.fset, cs.file, commandsImport, false); needed {
+		edits = append(edits, edit)
+	}
+	if config.usesDuration() {
+		if edit, needed := importEdit(cs.fset, cs.file, "time", true); needed {
+			edits = append(edits, edit)
+		}
+	}
+
+	formatted, err := format.Source(applyEdits(source, edits))
+	if err != nil {
+		return fmt.Errorf("failed to format %s after adding the command: %w", cs.path, err)
+	}
+
+	return os.WriteFile(cs.path, formatted, 0600)
+}
+
+// importEdit adds path to the import block. Standard library packages join
+// the first group, project packages get a group of their own at the end.
+func importEdit(fset *token.FileSet, file *ast.File, path string, stdlib bool) (sourceEdit, bool) {
+	quoted := strconv.Quote(path)
+	for _, spec := range file.Imports {
+		if spec.Path.Value == quoted {
+			return sourceEdit{}, false
+		}
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if !gen.Lparen.IsValid() {
+			return sourceEdit{offset: fset.Position(gen.End()).Offset, text: "\nimport " + quoted + "\n"}, true
+		}
+		if !stdlib {
+			return sourceEdit{offset: fset.Position(gen.Rparen).Offset, text: "\n\t" + quoted + "\n"}, true
+		}
+
+		// The first group ends at the first blank line between specs.
+		offset := fset.Position(gen.Lparen).Offset + 1
+		for i, spec := range gen.Specs {
+			if i > 0 && fset.Position(spec.Pos()).Line > fset.Position(gen.Specs[i-1].End()).Line+1 {
+				break
+			}
+			offset = fset.Position(spec.End()).Offset
+		}
+		return sourceEdit{offset: offset, text: "\n\t" + quoted}, true
+	}
+
+	return sourceEdit{offset: fset.Position(file.Name.End()).Offset, text: "\n\nimport " + quoted + "\n"}, true
+}
+
+// applyEdits splices edits into source, starting from the last offset so
+// the earlier ones stay valid.
+func applyEdits(source []byte, edits []sourceEdit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+	for _, edit := range edits {
+		source = append(source[:edit.offset], append([]byte(edit.text), source[edit.offset:]...)...)
+	}
+	return source
+}
+
+func (cc *CLICommandConfig) generateCommandLiteral() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "{\nName: %q,\nUsage: %q,\n", cc.Name, cc.Usage)
+	if len(cc.Flags) > 0 {
+		b.WriteString("Flags: []cli.Flag{\n")
+		for _, flag := range cc.Flags {
+			fmt.Fprintf(&b, "&cli.%s{\nName: %q,\nUsage: %q,\n", cliFlagTypes[flag.Type].flag, flag.Name, flag.Name+" for "+cc.Name)
+			if flag.Value != "" {
+				fmt.Fprintf(&b, "Value: %s,\n", flag.Value)
+			}
+			b.WriteString("},\n")
+		}
+		b.WriteString("},\n")
+	}
+	fmt.Fprintf(&b, "Action: %s.%s,\n}", cliCommandsDir, cc.funcName())
+	return b.String()
+}
+
+func (cc *CLICommandConfig) generateAction() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `package %[1]s
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// %[2]s is the action of the %[3]s command.
+func %[2]s(c *cli.Context) error {
+	fmt.Fprintln(c.App.Writer, "%[3]s called")
+`, cliCommandsDir, cc.funcName(), cc.Name)
+
+	for _, flag := range cc.Flags {
+		fmt.Fprintf(&b, "\tfmt.Fprintf(c.App.Writer, \"%s: %%v\\n\", c.%s(%q))\n", flag.Name, cliFlagTypes[flag.Type].getter, flag.Name)
+	}
+
+	b.WriteString("\n\treturn nil\n}\n")
+	return b.String()
+}
+
+func (cc *CLICommandConfig) generateTest(appFunc string) string {
+	want := cc.Name + " called\n"
+	for _, flag := range cc.Flags {
+		want += flag.Name + ": " + flag.Printed + "\n"
+	}
+
+	return fmt.Sprintf(`package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test%[1]sCommand(t *testing.T) {
+	var out bytes.Buffer
+
+	app := %[2]s()
+	app.Writer = &out
+
+	if err := app.Run([]string{app.Name, %[3]q}); err != nil {
+		t.Fatalf("%[4]s returned an error: %%v", err)
+	}
+
+	want := %[5]q
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output\ngot:  %%q\nwant: %%q", got, want)
+	}
+}
+`, cc.funcName(), appFunc, cc.Name, cc.Name, want)
+}
@@ -0,0 +1,540 @@
+package internal
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	CLIFrameworkUrfave = "urfave"
+	CLIFrameworkCobra  = "cobra"
+	CLIFrameworkStdlib = "stdlib-flag"
+)
+
+func IsCLIFramework(framework string) bool {
+	switch framework {
+	case CLIFrameworkUrfave, CLIFrameworkCobra, CLIFrameworkStdlib:
+		return true
+	default:
+		return false
+	}
+}
+
+// generateCobraFiles lays the project out the way cobra-cli does: main.go only
+// calls cmd.Execute and every subcommand lives in its own file under cmd/.
+// Shell completion comes from cobra's built-in completion command.
+func (pg *ProjectGenerator) generateCobraFiles(projectName, moduleName string) map[string]string {
+	return map[string]string{
+		"main.go":                          pg.generateCobraMainContent(moduleName),
+		filepath.Join("cmd", "root.go"):    pg.generateCobraRootContent(projectName),
+		filepath.Join("cmd", "greet.go"):   pg.generateCobraGreetContent(),
+		filepath.Join("cmd", "version.go"): pg.generateCobraVersionContent(),
+		filepath.Join("cmd", "man.go"):     pg.generateCobraManContent(),
+	}
+}
+
+func (pg *ProjectGenerator) generateCobraMainContent(moduleName string) string {
+	return `package main
+
+import "` + moduleName + `/cmd"
+
+// Set at build time with -ldflags, see the Makefile and .goreleaser.yaml.
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+func main() {
+	cmd.Execute(cmd.BuildInfo{
+		Version: version,
+		Commit:  commit,
+		Date:    date,
+	})
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCobraRootContent(projectName string) string {
+	envPrefix := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(projectName))
+
+	return `package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// BuildInfo carries the values injected into main at build time.
+type BuildInfo struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+var (
+	cfgFile   string
+	buildInfo BuildInfo
+)
+
+var rootCmd = &cobra.Command{
+	Use:          "` + projectName + `",
+	Short:        "A CLI application built with gogen",
+	SilenceUsage: true,
+}
+
+// Execute runs the root command. It is called by main.main and only needs to
+// happen once.
+func Execute(info BuildInfo) {
+	buildInfo = info
+	rootCmd.Version = info.Version
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.` + projectName + `.yaml)")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+	cobra.CheckErr(viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose")))
+}
+
+// initConfig reads the config file and environment variables. Flags win over
+// environment variables, which win over the config file.
+func initConfig() {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		home, err := os.UserHomeDir()
+		cobra.CheckErr(err)
+
+		viper.AddConfigPath(home)
+		viper.AddConfigPath(".")
+		viper.SetConfigType("yaml")
+		viper.SetConfigName(".` + projectName + `")
+	}
+
+	viper.SetEnvPrefix("` + envPrefix + `")
+	viper.AutomaticEnv()
+
+	if err := viper.ReadInConfig(); err == nil && viper.GetBool("verbose") {
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCobraGreetContent() string {
+	return `package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var greetCmd = &cobra.Command{
+	Use:     "greet",
+	Aliases: []string{"g"},
+	Short:   "Greet someone",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintf(cmd.OutOrStdout(), "Hello %s\n", viper.GetString("greet.name"))
+		return nil
+	},
+}
+
+func init() {
+	greetCmd.Flags().String("name", "World", "Name to greet")
+	cobra.CheckErr(viper.BindPFlag("greet.name", greetCmd.Flags().Lookup("name")))
+
+	rootCmd.AddCommand(greetCmd)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCobraVersionContent() string {
+	return `package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version information",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s (commit %s, built %s)\n",
+			rootCmd.Name(), buildInfo.Version, buildInfo.Commit, buildInfo.Date)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateCobraManContent() string {
+	return `package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var manCmd = &cobra.Command{
+	Use:    "man",
+	Short:  "Generate the man page",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		header := &doc.GenManHeader{
+			Title:   strings.ToUpper(rootCmd.Name()),
+			Section: "1",
+		}
+		return doc.GenMan(rootCmd, header, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(manCmd)
+}
+`
+}
+
+// generateStdlibCLIFiles produces a dependency-free CLI: a small command table
+// dispatched with one flag.FlagSet per subcommand.
+func (pg *ProjectGenerator) generateStdlibCLIFiles(projectName string) map[string]string {
+	return map[string]string{
+		"main.go":       pg.generateStdlibMainContent(projectName),
+		"completion.go": pg.generateStdlibCompletionContent(),
+	}
+}
+
+func (pg *ProjectGenerator) generateStdlibMainContent(projectName string) string {
+	return `package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	appName  = "` + projectName + `"
+	appUsage = "A CLI application built with gogen"
+)
+
+// Set at build time with -ldflags, see the Makefile and .goreleaser.yaml.
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+// action runs a command once its flags are parsed, args holds the remaining
+// positional arguments.
+type action func(args []string, stdout io.Writer) error
+
+type command struct {
+	name    string
+	aliases []string
+	usage   string
+	hidden  bool
+	// args lists the positional values offered by shell completion.
+	args []string
+	// setup declares the command flags on fs and returns the action to run.
+	setup func(fs *flag.FlagSet) action
+}
+
+func commands() []command {
+	return []command{
+		{
+			name:    "greet",
+			aliases: []string{"g"},
+			usage:   "Greet someone",
+			setup: func(fs *flag.FlagSet) action {
+				name := fs.String("name", "World", "Name to greet")
+				return func(_ []string, stdout io.Writer) error {
+					fmt.Fprintf(stdout, "Hello %s\n", *name)
+					return nil
+				}
+			},
+		},
+		{
+			name:  "version",
+			usage: "Print version information",
+			setup: func(*flag.FlagSet) action {
+				return func(_ []string, stdout io.Writer) error {
+					fmt.Fprintf(stdout, "%s %s (commit %s, built %s)\n", appName, version, commit, date)
+					return nil
+				}
+			},
+		},
+		{
+			name:  "completion",
+			usage: "Generate the shell completion script",
+			args:  []string{"bash", "zsh", "fish"},
+			setup: func(*flag.FlagSet) action {
+				return runCompletion
+			},
+		},
+		{
+			name:   "man",
+			usage:  "Generate the man page",
+			hidden: true,
+			setup: func(*flag.FlagSet) action {
+				return func(_ []string, stdout io.Writer) error {
+					return writeManPage(stdout)
+				}
+			},
+		},
+	}
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet(appName, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { printUsage(stderr) }
+	showVersion := fs.Bool("version", false, "Print the version")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if *showVersion {
+		fmt.Fprintf(stdout, "%s version %s\n", appName, version)
+		return nil
+	}
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		printUsage(stdout)
+		return nil
+	}
+
+	cmd, ok := findCommand(fs.Arg(0))
+	if !ok {
+		printUsage(stderr)
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+
+	cmdFlags := commandFlags(cmd)
+	cmdFlags.SetOutput(stderr)
+	act := cmd.setup(cmdFlags)
+
+	if err := cmdFlags.Parse(fs.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	return act(cmdFlags.Args(), stdout)
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+		for _, alias := range cmd.aliases {
+			if alias == name {
+				return cmd, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+func commandFlags(cmd command) *flag.FlagSet {
+	return flag.NewFlagSet(appName+" "+cmd.name, flag.ContinueOnError)
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "%s - %s\n\nUsage:\n  %s [--version] <command> [flags]\n\nCommands:\n", appName, appUsage, appName)
+	for _, cmd := range commands() {
+		if !cmd.hidden {
+			fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.usage)
+		}
+	}
+	fmt.Fprintf(w, "  %-12s %s\n\nRun '%s <command> -h' to list the flags of a command.\n", "help", "Show this help", appName)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStdlibCompletionContent() string {
+	return `package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// completionEntry is what the completion scripts and the man page need to
+// know about a visible command.
+type completionEntry struct {
+	names []string
+	usage string
+	flags []*flag.Flag
+	args  []string
+}
+
+func completionEntries() []completionEntry {
+	var entries []completionEntry
+	for _, cmd := range commands() {
+		if cmd.hidden {
+			continue
+		}
+
+		fs := commandFlags(cmd)
+		cmd.setup(fs)
+
+		entry := completionEntry{
+			names: append([]string{cmd.name}, cmd.aliases...),
+			usage: cmd.usage,
+			args:  cmd.args,
+		}
+		fs.VisitAll(func(f *flag.Flag) {
+			entry.flags = append(entry.flags, f)
+		})
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func runCompletion(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing shell, expected bash, zsh or fish")
+	}
+
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(stdout, completionEntries())
+	case "zsh":
+		writeZshCompletion(stdout, completionEntries())
+	case "fish":
+		writeFishCompletion(stdout, completionEntries())
+	default:
+		return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", args[0])
+	}
+
+	return nil
+}
+
+// words returns the flags and positional values completed after the command.
+func (e completionEntry) words() string {
+	words := append([]string{}, e.args...)
+	for _, f := range e.flags {
+		words = append(words, "--"+f.Name)
+	}
+	return strings.Join(words, " ")
+}
+
+func commandNames(entries []completionEntry) string {
+	names := []string{"help"}
+	for _, e := range entries {
+		names = append(names, e.names[0])
+	}
+	return strings.Join(names, " ")
+}
+
+// shellIdent turns the binary name into a valid shell function name.
+func shellIdent() string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, appName)
+}
+
+func writeBashCompletion(w io.Writer, entries []completionEntry) {
+	fn := "_" + shellIdent() + "_completions"
+
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "  local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(w, "  if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(w, "    COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", commandNames(entries))
+	fmt.Fprintf(w, "    return\n  fi\n\n  case \"${COMP_WORDS[1]}\" in\n")
+	for _, e := range entries {
+		if words := e.words(); words != "" {
+			fmt.Fprintf(w, "    %s) COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") ) ;;\n", strings.Join(e.names, "|"), words)
+		}
+	}
+	fmt.Fprintf(w, "  esac\n}\n\ncomplete -F %s %s\n", fn, appName)
+}
+
+func writeZshCompletion(w io.Writer, entries []completionEntry) {
+	fn := "_" + shellIdent()
+
+	fmt.Fprintf(w, "#compdef %s\n\n%s() {\n", appName, fn)
+	fmt.Fprintf(w, "  if (( CURRENT == 2 )); then\n    compadd -- %s\n    return\n  fi\n\n", commandNames(entries))
+	fmt.Fprintf(w, "  case \"${words[2]}\" in\n")
+	for _, e := range entries {
+		if words := e.words(); words != "" {
+			fmt.Fprintf(w, "    %s) compadd -- %s ;;\n", strings.Join(e.names, "|"), words)
+		}
+	}
+	fmt.Fprintf(w, "  esac\n}\n\ncompdef %s %s\n", fn, appName)
+}
+
+func writeFishCompletion(w io.Writer, entries []completionEntry) {
+	fmt.Fprintf(w, "complete -c %s -f\n", appName)
+	fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a help -d 'Show help'\n", appName)
+	for _, e := range entries {
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a %s -d %q\n", appName, e.names[0], e.usage)
+
+		seen := fmt.Sprintf("'__fish_seen_subcommand_from %s'", strings.Join(e.names, " "))
+		if len(e.args) > 0 {
+			fmt.Fprintf(w, "complete -c %s -n %s -a '%s'\n", appName, seen, strings.Join(e.args, " "))
+		}
+		for _, f := range e.flags {
+			fmt.Fprintf(w, "complete -c %s -n %s -l %s -d %q\n", appName, seen, f.Name, f.Usage)
+		}
+	}
+}
+
+// writeManPage renders a section 1 man page in roff from the command table.
+func writeManPage(w io.Writer) error {
+	roff := strings.NewReplacer("-", "\\-", "\\", "\\\\").Replace
+
+	fmt.Fprintf(w, ".TH %s 1 \"\" \"%s %s\" \"User Commands\"\n", strings.ToUpper(roff(appName)), roff(appName), roff(version))
+	fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", roff(appName), roff(appUsage))
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n[\\-\\-version] <command> [flags]\n", roff(appName))
+	fmt.Fprintf(w, ".SH COMMANDS\n")
+	for _, e := range completionEntries() {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roff(strings.Join(e.names, ", ")), roff(e.usage))
+		for _, f := range e.flags {
+			fmt.Fprintf(w, ".RS\n.TP\n.B \\-\\-%s\n%s (default: %s)\n.RE\n", roff(f.Name), roff(f.Usage), roff(f.DefValue))
+		}
+	}
+	return nil
+}
+`
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CreateCLIReleaseFiles writes the GoReleaser config and a Makefile with
+// cross-compile targets for CLI projects. The version variables they inject
+// through -ldflags live in main.go for every CLI framework.
+func (pg *ProjectGenerator) CreateCLIReleaseFiles(dirName, projectName string) error {
+	files := map[string]string{
+		".goreleaser.yaml": pg.generateGoreleaserContent(projectName),
+		"Makefile":         pg.generateCLIMakefile(projectName),
+	}
+
+	for _, fileName := range sortedKeys(files) {
+		if err := os.WriteFile(filepath.Join(dirName, fileName), []byte(files[fileName]), 0600); err != nil {
+			return fmt.Errorf("failed to create %s: %w", fileName, err)
+		}
+	}
+
+	return nil
+}
+
+func (pg *ProjectGenerator) generateCompletionContent() string {
+	return `package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func completionCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "completion",
+		Usage:     "Generate the shell completion script",
+		ArgsUsage: "<bash|zsh|fish>",
+		Description: ` + "`" + `Print a completion script for the given shell.
+
+  source <(app completion bash)
+  app completion zsh > "${fpath[1]}/_app"
+  app completion fish > ~/.config/fish/completions/app.fish` + "`" + `,
+		Action: func(c *cli.Context) error {
+			name := c.App.Name
+
+			switch c.Args().First() {
+			case "bash":
+				fmt.Fprint(c.App.Writer, strings.ReplaceAll(bashCompletion, "PROG", name))
+			case "zsh":
+				fmt.Fprint(c.App.Writer, strings.ReplaceAll(zshCompletion, "PROG", name))
+			case "fish":
+				script, err := c.App.ToFishCompletion()
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(c.App.Writer, script)
+			default:
+				return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", c.Args().First())
+			}
+
+			return nil
+		},
+	}
+}
+
+// The scripts are the ones shipped in urfave/cli's autocomplete directory,
+// they call the binary with --generate-bash-completion.
+const bashCompletion = ` + "`" + `#!/bin/bash
+
+_cli_bash_autocomplete() {
+  if [[ "${COMP_WORDS[0]}" != "source" ]]; then
+    local cur opts
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ "$cur" == "-"* ]]; then
+      opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} ${cur} --generate-bash-completion )
+    else
+      opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )
+    fi
+    COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
+    return 0
+  fi
+}
+
+complete -o bashdefault -o default -o nospace -F _cli_bash_autocomplete PROG
+` + "`" + `
+
+const zshCompletion = ` + "`" + `#compdef PROG
+
+_cli_zsh_autocomplete() {
+  local -a opts
+  local cur
+  cur=${words[-1]}
+  if [[ "$cur" == "-"* ]]; then
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} ${cur} --generate-bash-completion)}")
+  else
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} --generate-bash-completion)}")
+  fi
+
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+
+compdef _cli_zsh_autocomplete PROG
+` + "`" + `
+`
+}
+
+func (pg *ProjectGenerator) generateGoreleaserContent(projectName string) string {
+	return fmt.Sprintf(`version: 2
+project_name: %[1]s
+before:
+  hooks:
+    - go mod tidy
+    - sh -c "mkdir -p completions && for sh in bash zsh fish; do go run . completion $sh > completions/%[1]s.$sh; done"
+    - sh -c "mkdir -p manpages && go run . man | gzip -c > manpages/%[1]s.1.gz"
+builds:
+  - main: .
+    binary: %[1]s
+    env:
+      - CGO_ENABLED=0
+    goos:
+      - linux
+      - darwin
+      - windows
+    goarch:
+      - amd64
+      - arm64
+    flags:
+      - -trimpath
+    ldflags:
+      - -s -w -X main.version={{ .Version }} -X main.commit={{ .ShortCommit }} -X main.date={{ .Date }}
+archives:
+  - formats: ["tar.gz"]
+    format_overrides:
+      - goos: windows
+        formats: ["zip"]
+    name_template: "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}"
+    files:
+      - completions/*
+      - manpages/*
+checksum:
+  name_template: checksums.txt
+changelog:
+  use: git
+`, projectName)
+}
+
+func (pg *ProjectGenerator) generateCLIMakefile(projectName string) string {
+	return `BINARY_NAME=` + projectName + `
+VERSION ?= $(shell git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT ?= $(shell git rev-parse --short HEAD 2>/dev/null || echo none)
+DATE ?= $(shell date -u +%Y-%m-%dT%H:%M:%SZ)
+LDFLAGS=-s -w -X main.version=$(VERSION) -X main.commit=$(COMMIT) -X main.date=$(DATE)
+PLATFORMS=linux/amd64 linux/arm64 darwin/amd64 darwin/arm64 windows/amd64 windows/arm64
+
+.PHONY: all build build-all run test vet completions man release snapshot clean
+
+all: build
+
+build:
+	@mkdir -p bin
+	go build -trimpath -ldflags "$(LDFLAGS)" -o bin/$(BINARY_NAME) .
+
+build-all:
+	@mkdir -p bin
+	@for platform in $(PLATFORMS); do \
+		os=$${platform%/*}; arch=$${platform#*/}; \
+		ext=""; if [ "$$os" = "windows" ]; then ext=".exe"; fi; \
+		echo "Building $$os/$$arch"; \
+		CGO_ENABLED=0 GOOS=$$os GOARCH=$$arch go build -trimpath -ldflags "$(LDFLAGS)" \
+			-o bin/$(BINARY_NAME)-$$os-$$arch$$ext . || exit 1; \
+	done
+
+run: build
+	./bin/$(BINARY_NAME)
+
+test:
+	go test ./...
+
+vet:
+	go vet ./...
+
+completions:
+	@mkdir -p completions
+	@for sh in bash zsh fish; do go run . completion $$sh > completions/$(BINARY_NAME).$$sh; done
+
+man:
+	@mkdir -p manpages
+	go run . man | gzip -c > manpages/$(BINARY_NAME).1.gz
+
+release:
+	goreleaser release --clean
+
+snapshot:
+	goreleaser release --snapshot --clean
+
+clean:
+	rm -rf bin/ dist/ completions/ manpages/ tmp/
+`
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// goreleaserMain matches the main package of the build in .goreleaser.yaml.
+var goreleaserMain = regexp.MustCompile(`(?m)^\s+- main: (\S+)$`)
+
+// writeCLIProject writes the sources of a CLI project using framework to a
+// temporary directory and resolves its dependencies.
+func writeCLIProject(t *testing.T, framework string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	pg := NewProjectGenerator()
+	files := pg.generateCLIFiles("app", "example.com/app", framework)
+	files["go.mod"] = "module example.com/app\n\ngo 1.22\n"
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pg.CreateCLIReleaseFiles(dir, "app"); err != nil {
+		t.Fatal(err)
+	}
+
+	tidy := exec.Command("go", "mod", "tidy")
+	tidy.Dir = dir
+	if out, err := tidy.CombinedOutput(); err != nil {
+		t.Skipf("go mod tidy failed, the dependencies are not available: %v\n%s", err, out)
+	}
+	return dir
+}
+
+// TestGoreleaserBuild builds the generated CLIs the way GoReleaser does,
+// from the main package in .goreleaser.yaml with its flags.
+func TestGoreleaserBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated projects")
+	}
+
+	for _, framework := range []string{CLIFrameworkUrfave, CLIFrameworkCobra, CLIFrameworkStdlib} {
+		t.Run(framework, func(t *testing.T) {
+			dir := writeCLIProject(t, framework)
+
+			config, err := os.ReadFile(filepath.Join(dir, ".goreleaser.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			match := goreleaserMain.FindSubmatch(config)
+			if match == nil {
+				t.Fatalf(".goreleaser.yaml has no build main:\n%s", config)
+			}
+
+			build := exec.Command("go", "build", "-trimpath",
+				"-ldflags", "-s -w -X main.version=1.0.0 -X main.commit=abc1234 -X main.date=2024-01-01T00:00:00Z",
+				"-o", filepath.Join(t.TempDir(), "app"), string(match[1]))
+			build.Dir = dir
+			build.Env = append(os.Environ(), "CGO_ENABLED=0")
+			if out, err := build.CombinedOutput(); err != nil {
+				t.Fatalf("go build %s: %v\n%s", match[1], err, out)
+			}
+		})
+	}
+}
@@ -12,6 +12,10 @@ const (
 	svelte  = "svelte"
 	solidjs = "solidjs"
 	angular = "angular"
+	preact  = "preact"
+	lit     = "lit"
+	qwik    = "qwik"
+	astro   = "astro"
 )
 
 const (
@@ -68,6 +72,21 @@ func (pg *ProjectGenerator) CreateFrontendProject(framework, dirName string, use
 		}
 		cmd = pg.getSolidCommand(packageManager, dirName, mode)
 
+	case preact, lit:
+		template := framework
+		if useTypeScript {
+			template = framework + "-ts"
+		}
+		cmd = pg.getCreateCommand(packageManager, "create", "vite@latest", dirName, allowPrompts, "--template", template)
+
+	case qwik:
+		// Qwik starters are TypeScript only, passing both the starter and the
+		// directory keeps create-qwik from prompting.
+		cmd = pg.getCreateCommand(packageManager, "create", "qwik@latest", "empty", dirName)
+
+	case astro:
+		cmd = pg.getCreateCommand(packageManager, "create", "astro@latest", dirName, allowPrompts, "--template", "minimal", "--no-install", "--no-git", "--skip-houston", "--yes")
+
 	case angular:
 		args := []string{"new", dirName, "--routing=true", "--style=css", "--skip-git=true", "--package-manager=" + packageManager}
 		if useTypeScript {
@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const devContainerDir = ".devcontainer"
+
+type DevContainerConfig struct {
+	ProjectName       string
+	Template          string
+	Runtime           string
+	FrontendFramework string
+	APIPort           int
+	UseDocker         bool
+	Services          []string
+}
+
+type devContainerJSON struct {
+	Name              string                 `json:"name"`
+	Build             *devContainerBuild     `json:"build,omitempty"`
+	DockerComposeFile []string               `json:"dockerComposeFile,omitempty"`
+	Service           string                 `json:"service,omitempty"`
+	RunServices       []string               `json:"runServices,omitempty"`
+	WorkspaceFolder   string                 `json:"workspaceFolder"`
+	Features          map[string]interface{} `json:"features,omitempty"`
+	ForwardPorts      []int                  `json:"forwardPorts,omitempty"`
+	PostCreateCommand string                 `json:"postCreateCommand"`
+	Customizations    devContainerCustomize  `json:"customizations"`
+	RemoteUser        string                 `json:"remoteUser"`
+}
+
+type devContainerBuild struct {
+	Dockerfile string `json:"dockerfile"`
+}
+
+type devContainerCustomize struct {
+	VSCode devContainerVSCode `json:"vscode"`
+}
+
+type devContainerVSCode struct {
+	Extensions []string          `json:"extensions"`
+	Settings   map[string]string `json:"settings"`
+}
+
+// CreateDevContainer writes .devcontainer/ into dirName. Projects with Docker
+// enabled reuse the generated docker-compose.yml so the extra services are
+// reachable from inside the container.
+func (pg *ProjectGenerator) CreateDevContainer(dirName string, config *DevContainerConfig) error {
+	targetDir := filepath.Join(dirName, devContainerDir)
+	if err := os.MkdirAll(targetDir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", devContainerDir, err)
+	}
+
+	goModPath := filepath.Join(dirName, "go.mod")
+	if config.Template == constants.WebTemplate {
+		goModPath = filepath.Join(dirName, constants.APIDir, "go.mod")
+	}
+
+	files := map[string]string{
+		"Dockerfile": config.generateDockerfile(goVersionFromMod(goModPath)),
+	}
+
+	var devContainer bytes.Buffer
+	encoder := json.NewEncoder(&devContainer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config.generateDevContainerJSON()); err != nil {
+		return fmt.Errorf("failed to encode devcontainer.json: %w", err)
+	}
+	files["devcontainer.json"] = devContainer.String()
+
+	if config.UseDocker {
+		files["docker-compose.devcontainer.yml"] = config.generateCompose()
+	}
+
+	for _, fileName := range sortedKeys(files) {
+		if err := os.WriteFile(filepath.Join(targetDir, fileName), []byte(files[fileName]), 0600); err != nil {
+			return fmt.Errorf("failed to create %s: %w", fileName, err)
+		}
+	}
+
+	return nil
+}
+
+func (dc *DevContainerConfig) hasFrontend() bool {
+	return dc.Template == constants.WebTemplate && dc.FrontendFramework != ""
+}
+
+func (dc *DevContainerConfig) generateDockerfile(goVersion string) string {
+	// The devcontainer images are only tagged with major.minor versions.
+	if parts := strings.Split(goVersion, "."); len(parts) > 2 {
+		goVersion = parts[0] + "." + parts[1]
+	}
+
+	bunInstall := ""
+	if dc.hasFrontend() && dc.Runtime == bun {
+		bunInstall = `
+USER vscode
+
+RUN curl -fsSL https://bun.sh/install | bash
+
+ENV PATH="/home/vscode/.bun/bin:${PATH}"
+
+USER root
+`
+	}
+
+	return fmt.Sprintf(`FROM mcr.microsoft.com/devcontainers/go:1-%s-bookworm
+
+RUN apt-get update && export DEBIAN_FRONTEND=noninteractive && \
+    apt-get install -y --no-install-recommends unzip && \
+    rm -rf /var/lib/apt/lists/*
+
+RUN go install github.com/air-verse/air@latest && \
+    curl -sSfL https://raw.githubusercontent.com/golangci/golangci-lint/master/install.sh | \
+      sh -s -- -b "$(go env GOPATH)/bin"
+%s`, goVersion, bunInstall)
+}
+
+func (dc *DevContainerConfig) generateDevContainerJSON() *devContainerJSON {
+	apiPort := dc.APIPort
+	if apiPort == 0 {
+		apiPort = DefaultAPIPort
+	}
+
+	workspace := "/workspaces/" + composeName(dc.ProjectName)
+
+	devContainer := &devContainerJSON{
+		Name:            dc.ProjectName,
+		WorkspaceFolder: workspace,
+		RemoteUser:      "vscode",
+		Customizations: devContainerCustomize{
+			VSCode: devContainerVSCode{
+				Extensions: []string{"golang.go"},
+				Settings: map[string]string{
+					"go.lintTool": "golangci-lint",
+					"terminal.integrated.defaultProfile.linux": "bash",
+				},
+			},
+		},
+	}
+
+	if dc.UseDocker {
+		devContainer.DockerComposeFile = []string{"../docker-compose.yml", "docker-compose.devcontainer.yml"}
+		devContainer.Service = "devcontainer"
+		devContainer.RunServices = append([]string{"devcontainer"}, dc.Services...)
+	} else {
+		devContainer.Build = &devContainerBuild{Dockerfile: "Dockerfile"}
+	}
+
+	switch dc.Template {
+	case constants.WebTemplate:
+		devContainer.ForwardPorts = []int{apiPort}
+		devContainer.PostCreateCommand = "cd api && go mod download"
+
+		if dc.hasFrontend() {
+			devContainer.ForwardPorts = append(devContainer.ForwardPorts, frontendDevPort(dc.FrontendFramework))
+			installCommand := "npm install"
+			if dc.Runtime == bun {
+				installCommand = "bun install"
+			} else {
+				devContainer.Features = map[string]interface{}{
+					"ghcr.io/devcontainers/features/node:1": map[string]string{"version": "22"},
+				}
+			}
+			devContainer.PostCreateCommand += " && cd ../frontend && " + installCommand
+			devContainer.Customizations.VSCode.Extensions = append(devContainer.Customizations.VSCode.Extensions,
+				append([]string{"dbaeumer.vscode-eslint", "esbenp.prettier-vscode"}, frontendExtensions(dc.FrontendFramework)...)...)
+		}
+	case constants.APITemplate:
+		devContainer.ForwardPorts = []int{apiPort}
+		devContainer.PostCreateCommand = "go mod download"
+	default:
+		devContainer.PostCreateCommand = "go mod download"
+	}
+
+	return devContainer
+}
+
+func (dc *DevContainerConfig) generateCompose() string {
+	return fmt.Sprintf(`services:
+  devcontainer:
+    build:
+      context: .devcontainer
+      dockerfile: Dockerfile
+    volumes:
+      - .:/workspaces/%s:cached
+    command: sleep infinity
+    networks:
+      - default
+`, composeName(dc.ProjectName))
+}
+
+func frontendExtensions(framework string) []string {
+	switch framework {
+	case vue:
+		return []string{"Vue.volar"}
+	case svelte:
+		return []string{"svelte.svelte-vscode"}
+	case angular:
+		return []string{"Angular.ng-template"}
+	case astro:
+		return []string{"astro-build.astro-vscode"}
+	case lit:
+		return []string{"runem.lit-plugin"}
+	default:
+		return nil
+	}
+}
@@ -4,87 +4,111 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	constants "github.com/luigimorel/gogen/consants"
 )
 
-var runTimeContent = `
-FROM oven/bun:1-alpine AS builder
-
-WORKDIR /app
-
-COPY package.json bun.lock ./
-
-RUN bun install --frozen-lockfile
-
-COPY . .
-
-RUN bun run build
-
-FROM oven/bun:1-alpine
-
-RUN addgroup -g 1001 -S user
-
-RUN adduser -S user -u 1001 -G user
-
-WORKDIR /app
-
-RUN chown -R user:user /app
-
-USER user
-
-COPY --chown=user:user package.json bun.lock ./
-
-RUN bun install --frozen-lockfile
-
-COPY --from=builder --chown=user:user /app/dist ./dist
-
-EXPOSE 4173
-
-CMD ["bun", "run", "preview", "--host", "0.0.0.0", "--port", "4173"]
-`
-
-func (pg *ProjectGenerator) CreateDockerfile(dirName, dirType, runtime string) error {
-	var dockerContent string
-	var dockerIgnoreContent string
-
-	if dirType == constants.APIDir {
-		dockerContent = `FROM golang:1.21-alpine AS builder
-
-WORKDIR /app
+const (
+	DockerBaseDistroless = "distroless"
+	DockerBaseScratch    = "scratch"
+	DockerBaseAlpine     = "alpine"
+)
 
-RUN apk add --no-cache git
+// defaultGoVersion is used for the builder image when the go directive
+// cannot be read from go.mod.
+const defaultGoVersion = "1.21"
 
-COPY go.mod go.sum ./
+var healthcheckContent = fmt.Sprintf(`package main
 
-RUN go mod download
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
 
-COPY . .
+// healthcheck probes the readiness of the API from inside the container. The
+// runtime image has no shell or wget, so the Docker HEALTHCHECK runs this
+// binary instead.
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
 
-RUN CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -o main .
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get("http://127.0.0.1:" + port + "%s")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck failed: %%v\n", err)
+		os.Exit(1)
+	}
+	_ = resp.Body.Close()
 
-FROM alpine:latest
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "healthcheck failed: HTTP %%d\n", resp.StatusCode)
+		os.Exit(1)
+	}
+}
+`, ReadinessPath)
 
-RUN apk --no-cache add ca-certificates
+// generateGRPCHealthcheckContent is the healthcheck binary for gRPC projects,
+// it asks the standard gRPC health service whether the server is serving.
+func generateGRPCHealthcheckContent(port int) string {
+	return fmt.Sprintf(`package main
 
-RUN addgroup -g 1001 -S appgroup && \
-    adduser -S appuser -u 1001 -G appgroup
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
 
-WORKDIR /root/
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
 
-COPY --from=builder /app/main .
+// healthcheck probes the gRPC health service from inside the container. The
+// runtime image has no shell or grpc_health_probe, so the Docker HEALTHCHECK
+// runs this binary instead.
+func main() {
+	if err := check(); err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck failed: %%v\n", err)
+		os.Exit(1)
+	}
+}
 
-COPY --from=builder /app/.env* ./
+func check() error {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = "%d"
+	}
 
-RUN chown -R appuser:appgroup /root
+	conn, err := grpc.NewClient("127.0.0.1:"+port, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
 
-USER appuser
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
 
-EXPOSE 8080
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("status %%s", resp.GetStatus())
+	}
+	return nil
+}
+`, port)
+}
 
-CMD ["./main"]
-`
-		dockerIgnoreContent = `# Binaries
+// goDockerignoreContent keeps build output, editor files and .env files out of
+// the build context of Go images.
+var goDockerignoreContent = `# Binaries
 *.exe
 *.exe~
 *.dll
@@ -119,47 +143,48 @@ README*
 Dockerfile*
 docker-compose*
 .dockerignore
+.env
+.env.*
 `
-	} else {
-		if runtime == "bun" {
-			dockerContent = runTimeContent
-		} else {
-			dockerContent = `FROM node:22-alpine AS builder
 
-WORKDIR /app
-
-COPY package*.json ./
-
-RUN npm ci
-
-COPY . .
-
-RUN npm run build
-
-FROM node:22-alpine
-
-RUN addgroup -g 1001 -S user
-
-RUN adduser -S user -u 1001 -G user
-
-WORKDIR /app
-
-RUN chown -R user:user /app
-
-USER user
-
-COPY --chown=user:user package*.json ./
+func IsDockerBase(base string) bool {
+	switch base {
+	case DockerBaseDistroless, DockerBaseScratch, DockerBaseAlpine:
+		return true
+	default:
+		return false
+	}
+}
 
-RUN npm ci --only=production && npm install vite
+func (pg *ProjectGenerator) CreateDockerfile(dirName, dirType string, config *WebProjectConfig) error {
+	var dockerContent string
+	var dockerIgnoreContent string
 
-COPY --from=builder --chown=user:user /app/dist ./dist
+	if dirType == constants.WorkerTemplate {
+		dockerContent = pg.generateWorkerDockerfile(goVersionFromMod(filepath.Join(dirName, "go.mod")), config, ".")
+		dockerIgnoreContent = goDockerignoreContent
+	} else if dirType == constants.APIDir || dirType == constants.GRPCTemplate {
+		healthcheck := healthcheckContent
+		if dirType == constants.GRPCTemplate {
+			healthcheck = generateGRPCHealthcheckContent(config.GRPCPort)
+		}
 
-EXPOSE 4173
+		healthcheckDir := filepath.Join(dirName, "cmd", "healthcheck")
+		if err := os.MkdirAll(healthcheckDir, 0750); err != nil {
+			return fmt.Errorf("failed to create healthcheck directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(healthcheckDir, "main.go"), []byte(healthcheck), 0600); err != nil {
+			return fmt.Errorf("failed to create healthcheck command: %w", err)
+		}
 
-# Vite preview port is 4173. You can change to nginx if needed.
-CMD ["npm", "run", "preview", "--", "--host", "0.0.0.0", "--port", "4173"]
-`
+		dockerContent = pg.generateAPIDockerfile(goVersionFromMod(filepath.Join(dirName, "go.mod")), config)
+		dockerIgnoreContent = goDockerignoreContent
+	} else {
+		if err := pg.CreateFrontendServerConfig(dirName, config); err != nil {
+			return err
 		}
+
+		dockerContent = pg.generateFrontendDockerfile(config)
 		dockerIgnoreContent = `node_modules
 		# Dependencies
 node_modules/
@@ -238,117 +263,184 @@ docker-compose*
 	return nil
 }
 
-func (pg *ProjectGenerator) CreateDockerComposeFile(dirName string) error {
-	var dockerComposeContent string
-	var dockerComposeOverrideContent string
-
-	dockerComposeContent = `services:
-  api:
-    build:
-      context: ./api
-      dockerfile: Dockerfile
-    container_name: audits-api
-    ports:
-      - "8080:8080"
-    environment:
-      - PORT=8080
-      - ENV=production
-    volumes:
-      - ./api/.env:/root/.env:ro
-    networks:
-      - default
-    restart: unless-stopped
-    healthcheck:
-      test:
-        [
-          "CMD",
-          "wget",
-          "--quiet",
-          "--tries=1",
-          "--spider",
-          "http://localhost:8080/health",
-        ]
-      interval: 30s
-      timeout: 10s
-      retries: 3
-      start_period: 40s
-
-  frontend:
-    build:
-      context: ./frontend
-      dockerfile: Dockerfile
-    container_name: audits-frontend
-    ports:
-      - "4173:4173"
-    depends_on:
-      api:
-        condition: service_healthy
-    networks:
-      - default
-    restart: unless-stopped
-    healthcheck:
-      test:
-        [
-          "CMD",
-          "wget",
-          "--quiet",
-          "--tries=1",
-          "--spider",
-          "http://localhost:4173",
-        ]
-      interval: 30s
-      timeout: 10s
-      retries: 3
-      start_period: 40s
-
-networks:
-  default:
-    driver: bridge
-
-volumes:
-  api-data:
-    driver: local
-`
+func (pg *ProjectGenerator) generateAPIDockerfile(goVersion string, config *WebProjectConfig) string {
+	base := config.DockerBase
+	if base == "" {
+		base = DockerBaseDistroless
+	}
+
+	port := config.APIPort
+	if port == 0 {
+		port = DefaultAPIPort
+	}
+
+	expose := strconv.Itoa(port)
+	if config.GRPCPort != 0 {
+		expose = strconv.Itoa(config.GRPCPort)
+		if config.UseGateway {
+			expose += " " + strconv.Itoa(port)
+		}
+	}
+
+	builderPackages := dockerBuilderPackages(base)
+	runtimeStage := dockerRuntimeStage(base, "/out/main /out/healthcheck")
+
+	return fmt.Sprintf(`# syntax=docker/dockerfile:1
+
+ARG GO_VERSION=%s
+
+FROM golang:${GO_VERSION}-alpine AS builder
+%s
+%s
+
+ARG VERSION=dev
+
+RUN --mount=type=cache,target=/go/pkg/mod \
+    --mount=type=cache,target=/root/.cache/go-build \
+    CGO_ENABLED=0 GOOS=linux go build -trimpath \
+      -ldflags="-s -w -X main.version=${VERSION}" \
+      -o /out/main . && \
+    CGO_ENABLED=0 GOOS=linux go build -trimpath -ldflags="-s -w" \
+      -o /out/healthcheck ./cmd/healthcheck
+
+# Configuration and secrets are provided at runtime through the environment,
+# .env files are never copied into the image.
+%s
+
+EXPOSE %s
+
+HEALTHCHECK --interval=30s --timeout=5s --start-period=10s --retries=3 \
+    CMD ["/app/healthcheck"]
+
+ENTRYPOINT ["/app/main"]
+`, goVersion, builderPackages, dockerSourceStage(config), runtimeStage, expose)
+}
+
+// generateWorkerDockerfile builds the worker in pkg. Workers serve no port, so
+// the image has no EXPOSE or HEALTHCHECK and the orchestrator restarts it when
+// the process exits.
+func (pg *ProjectGenerator) generateWorkerDockerfile(goVersion string, config *WebProjectConfig, pkg string) string {
+	base := config.DockerBase
+	if base == "" {
+		base = DockerBaseDistroless
+	}
+
+	return fmt.Sprintf(`# syntax=docker/dockerfile:1
+
+ARG GO_VERSION=%s
+
+FROM golang:${GO_VERSION}-alpine AS builder
+%s
+%s
+
+ARG VERSION=dev
+
+RUN --mount=type=cache,target=/go/pkg/mod \
+    --mount=type=cache,target=/root/.cache/go-build \
+    CGO_ENABLED=0 GOOS=linux go build -trimpath \
+      -ldflags="-s -w -X main.version=${VERSION}" \
+      -o /out/worker %s
+
+# Configuration and secrets are provided at runtime through the environment,
+# .env files are never copied into the image.
+%s
+
+ENTRYPOINT ["/app/worker"]
+`, goVersion, dockerBuilderPackages(base), dockerSourceStage(config), pkg, dockerRuntimeStage(base, "/out/worker"))
+}
 
-	dockerComposeOverrideContent = `services:
-  api:
-    build:
-      target: builder
-    volumes:
-      - ./api:/app
-      - /app/tmp
-    environment:
-      - ENV=development
-      - GO_ENV=development
-    command: ["sh", "-c", "go mod download && go run main.go"]
-    ports:
-      - "8080:8080"
-      - "2345:2345" #Delve debugger
-
-  frontend:
-    build:
-      target: builder
-    volumes:
-      - ./frontend:/app
-      - /app/node_modules
-      - /app/dist
-    environment:
-      - NODE_ENV=development
-    command: ["npm", "run", "dev", "--", "--host", "0.0.0.0"]
-    ports:
-      - "5173:5173"
+// dockerSourceStage copies the module into the builder and downloads its
+// dependencies first, so they stay cached until go.mod changes. Workspace
+// services are built from the workspace root, which also holds the shared pkg
+// module their go.mod replaces with ../../pkg.
+func dockerSourceStage(config *WebProjectConfig) string {
+	if config.WorkspaceDir == "" {
+		return `WORKDIR /app
 
+COPY go.mod go.sum ./
+
+RUN --mount=type=cache,target=/go/pkg/mod \
+    go mod download
+
+COPY . .`
+	}
+
+	dir := filepath.ToSlash(config.WorkspaceDir)
+	return `WORKDIR /src/` + dir + `
+
+COPY pkg/ /src/pkg/
+COPY ` + dir + `/go.mod ` + dir + `/go.sum ./
+
+RUN --mount=type=cache,target=/go/pkg/mod \
+    go mod download
+
+COPY ` + dir + `/ ./`
+}
+
+// dockerBuilderPackages installs what the scratch image needs to copy from the
+// builder, the other bases ship certificates and time zones themselves.
+func dockerBuilderPackages(base string) string {
+	if base != DockerBaseScratch {
+		return ""
+	}
+	return `
+RUN apk add --no-cache ca-certificates tzdata
 `
+}
 
-	dockerComposefilePath := dirName + "/docker-compose.yml"
-	if err := os.WriteFile(dockerComposefilePath, []byte(dockerComposeContent), 0600); err != nil {
-		return fmt.Errorf("failed to create Dockerfile: %w", err)
+// dockerRuntimeStage is the final image for the given base, with binaries
+// copied from the builder into /app.
+func dockerRuntimeStage(base, binaries string) string {
+	switch base {
+	case DockerBaseScratch:
+		return `FROM scratch
+
+COPY --from=builder /etc/ssl/certs/ca-certificates.crt /etc/ssl/certs/
+
+COPY --from=builder /usr/share/zoneinfo /usr/share/zoneinfo
+
+WORKDIR /app
+
+COPY --from=builder ` + binaries + ` ./
+
+USER 65532:65532`
+	case DockerBaseAlpine:
+		return `FROM alpine:3.20
+
+RUN apk --no-cache add ca-certificates tzdata && \
+    addgroup -g 1001 -S appgroup && \
+    adduser -S appuser -u 1001 -G appgroup
+
+WORKDIR /app
+
+COPY --from=builder --chown=appuser:appgroup ` + binaries + ` ./
+
+USER appuser`
+	default:
+		return `FROM gcr.io/distroless/static-debian12:nonroot
+
+WORKDIR /app
+
+COPY --from=builder ` + binaries + ` ./
+
+USER nonroot:nonroot`
+	}
+}
+
+// goVersionFromMod reads the go directive from go.mod so the builder image
+// matches the version the module was written against.
+func goVersionFromMod(goModPath string) string {
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return defaultGoVersion
 	}
 
-	composeOverrideFilePath := dirName + "/docker-compose.override.yml"
-	if err := os.WriteFile(composeOverrideFilePath, []byte(dockerComposeOverrideContent), 0600); err != nil {
-		return fmt.Errorf("failed to create docker-compose.override.yml: %w", err)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "go" {
+			return fields[1]
+		}
 	}
 
-	return nil
+	return defaultGoVersion
 }
@@ -0,0 +1,657 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	DefaultAPIPort      = 8080
+	DefaultFrontendPort = 4173
+	DefaultGRPCPort     = 9090
+)
+
+// frontendContainerPort is the port nginx, Caddy or the static server listen
+// on inside the frontend image, it is mapped to the configured frontend port
+// on the host.
+const frontendContainerPort = 80
+
+const (
+	ServicePostgres   = "postgres"
+	ServiceRedis      = "redis"
+	ServiceMailpit    = "mailpit"
+	ServiceMinio      = "minio"
+	ServiceJaeger     = "jaeger"
+	ServicePrometheus = "prometheus"
+	ServiceGrafana    = "grafana"
+)
+
+// ComposeServices lists the optional services that can be added next to the
+// API in the generated docker-compose.yml.
+var ComposeServices = []string{
+	ServicePostgres,
+	ServiceRedis,
+	ServiceMailpit,
+	ServiceMinio,
+	ServiceJaeger,
+	ServicePrometheus,
+	ServiceGrafana,
+}
+
+// Ports Prometheus and Grafana listen on. On the host they move to the next
+// free port when the project already uses them, see hostPort.
+const (
+	prometheusPort = 9090
+	grafanaPort    = 3000
+)
+
+type ComposeConfig struct {
+	ProjectName string
+	// Context is the build context of the api service, relative to the
+	// directory docker-compose.yml is written to.
+	Context           string
+	APIPort           int
+	GRPCPort          int
+	FrontendPort      int
+	FrontendFramework string
+	Runtime           string
+	Services          []string
+	// WorkerDockerfile adds a worker service built from this Dockerfile in
+	// Context. WorkerOnly leaves out the api service, for worker projects.
+	WorkerDockerfile string
+	WorkerOnly       bool
+	// UseRateLimit keeps the buckets of the API's rate limiter in the redis
+	// service when there is one.
+	UseRateLimit bool
+	// UseCache keeps the values of the API's cache in the redis service
+	// when there is one.
+	UseCache bool
+}
+
+type composeService struct {
+	definition string
+	apiEnv     []string
+	volume     string
+	// files are config files the service mounts, by path relative to the
+	// directory docker-compose.yml is written to.
+	files map[string]string
+}
+
+func NewComposeConfig(config *WebProjectConfig) *ComposeConfig {
+	cc := &ComposeConfig{
+		ProjectName:       config.ProjectName,
+		Context:           "./" + constants.APIDir,
+		APIPort:           config.APIPort,
+		FrontendPort:      config.FrontendPort,
+		FrontendFramework: config.FrontendFramework,
+		Runtime:           config.Runtime,
+		Services:          config.Services,
+		UseRateLimit:      config.UseRateLimit,
+		UseCache:          config.UseCache,
+	}
+
+	if cc.APIPort == 0 {
+		cc.APIPort = DefaultAPIPort
+	}
+	if cc.FrontendPort == 0 {
+		cc.FrontendPort = DefaultFrontendPort
+	}
+
+	// gRPC projects live at the repository root and only serve HTTP when the
+	// gateway is enabled.
+	if config.GRPCPort != 0 {
+		cc.Context = "."
+		cc.GRPCPort = config.GRPCPort
+		if !config.UseGateway {
+			cc.APIPort = 0
+		}
+	}
+
+	return cc
+}
+
+func IsComposeService(name string) bool {
+	for _, service := range ComposeServices {
+		if service == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (pg *ProjectGenerator) CreateDockerComposeFile(dirName string, config *ComposeConfig) error {
+	dockerComposeContent, err := config.generateComposeContent()
+	if err != nil {
+		return err
+	}
+
+	dockerComposefilePath := filepath.Join(dirName, "docker-compose.yml")
+	if err := os.WriteFile(dockerComposefilePath, []byte(dockerComposeContent), 0600); err != nil {
+		return fmt.Errorf("failed to create docker-compose.yml: %w", err)
+	}
+
+	composeOverrideFilePath := filepath.Join(dirName, "docker-compose.override.yml")
+	if err := os.WriteFile(composeOverrideFilePath, []byte(config.generateComposeOverrideContent()), 0600); err != nil {
+		return fmt.Errorf("failed to create docker-compose.override.yml: %w", err)
+	}
+
+	for _, service := range config.Services {
+		extra, err := config.extraService(service)
+		if err != nil {
+			return err
+		}
+		if err := writeProjectFiles(dirName, extra.files); err != nil {
+			return fmt.Errorf("failed to create %s config: %w", service, err)
+		}
+	}
+
+	return nil
+}
+
+func (cc *ComposeConfig) generateComposeContent() (string, error) {
+	name := composeName(cc.ProjectName)
+
+	var extras []*composeService
+	var apiEnv, volumes []string
+	for _, service := range cc.Services {
+		extra, err := cc.extraService(service)
+		if err != nil {
+			return "", err
+		}
+		extras = append(extras, extra)
+		apiEnv = append(apiEnv, extra.apiEnv...)
+		if extra.volume != "" {
+			volumes = append(volumes, extra.volume)
+		}
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "name: %s\n\nservices:\n", name)
+	if !cc.WorkerOnly {
+		cc.writeAPIService(&b, name, apiEnv)
+	}
+	if cc.WorkerDockerfile != "" {
+		if !cc.WorkerOnly {
+			b.WriteString("\n")
+		}
+		b.WriteString(cc.generateWorkerService(apiEnv))
+	}
+
+	if cc.FrontendFramework != "" {
+		fmt.Fprintf(&b, `
+  frontend:
+    build:
+      context: ./frontend
+      dockerfile: Dockerfile
+    container_name: %s-frontend
+    ports:
+      - "%d:%d"
+    depends_on:
+      api:
+        condition: service_healthy
+    networks:
+      - default
+    restart: unless-stopped
+`, name, cc.FrontendPort, frontendContainerPort)
+		writeHealthcheck(&b, fmt.Sprintf(`["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://127.0.0.1:%d/"]`, frontendContainerPort), "30s", "10s", 3, "40s")
+	}
+
+	for _, extra := range extras {
+		b.WriteString("\n" + extra.definition)
+	}
+
+	b.WriteString(`
+networks:
+  default:
+    driver: bridge
+`)
+
+	if len(volumes) > 0 {
+		b.WriteString("\nvolumes:\n")
+		for _, volume := range volumes {
+			fmt.Fprintf(&b, "  %s:\n    driver: local\n", volume)
+		}
+	}
+
+	return b.String(), nil
+}
+
+func (cc *ComposeConfig) writeAPIService(b *strings.Builder, name string, env []string) {
+	fmt.Fprintf(b, `  api:
+    build:
+      context: %s
+      dockerfile: Dockerfile
+    container_name: %s-api
+`, cc.Context, name)
+	cc.writePorts(b)
+	b.WriteString("    environment:\n")
+	if cc.APIPort != 0 {
+		fmt.Fprintf(b, "      - PORT=%d\n", cc.APIPort)
+	}
+	if cc.GRPCPort != 0 {
+		fmt.Fprintf(b, "      - GRPC_PORT=%d\n", cc.GRPCPort)
+	}
+	b.WriteString("      - ENV=production\n")
+	for _, e := range env {
+		fmt.Fprintf(b, "      - %s\n", e)
+	}
+	writeEnvFile(b, cc.Context+"/.env")
+	cc.writeDependsOn(b)
+	b.WriteString(`    networks:
+      - default
+    restart: unless-stopped
+`)
+	writeHealthcheck(b, `["CMD", "/app/healthcheck"]`, "30s", "10s", 3, "40s")
+}
+
+// generateWorkerService is the worker service definition. It gets the same
+// environment and dependencies as the api but serves no port.
+func (cc *ComposeConfig) generateWorkerService(env []string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `  worker:
+    build:
+      context: %s
+      dockerfile: %s
+    container_name: %s-worker
+    environment:
+      - ENV=production
+`, cc.Context, cc.WorkerDockerfile, composeName(cc.ProjectName))
+	for _, e := range env {
+		fmt.Fprintf(&b, "      - %s\n", e)
+	}
+	writeEnvFile(&b, cc.Context+"/.env")
+	cc.writeDependsOn(&b)
+	b.WriteString(`    networks:
+      - default
+    restart: unless-stopped
+`)
+
+	return b.String()
+}
+
+// generateWorkerOverride runs the worker from source in development.
+func (cc *ComposeConfig) generateWorkerOverride() string {
+	pkg := "./cmd/worker"
+	if cc.WorkerOnly {
+		pkg = "."
+	}
+
+	return fmt.Sprintf(`  worker:
+    build:
+      target: builder
+    volumes:
+      - %s:/app
+    environment:
+      - ENV=development
+      - GO_ENV=development
+    command: ["sh", "-c", "go mod download && go run %s"]
+`, cc.Context, pkg)
+}
+
+// serviceEnv is the environment the extra services add to the api and the
+// worker.
+func (cc *ComposeConfig) serviceEnv() ([]string, error) {
+	var env []string
+	for _, service := range cc.Services {
+		extra, err := cc.extraService(service)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, extra.apiEnv...)
+	}
+	return env, nil
+}
+
+// writeEnvFile passes the variables of the .env file at path to the service.
+// Mounting the file would not do, the API writes it readable by its owner
+// only and the images run as another user. The variables in environment
+// take precedence, and the file may be missing, as in a fresh clone.
+func writeEnvFile(b *strings.Builder, path string) {
+	fmt.Fprintf(b, `    env_file:
+      - path: %s
+        required: false
+`, path)
+}
+
+func (cc *ComposeConfig) writeDependsOn(b *strings.Builder) {
+	var dependencies []string
+	for _, service := range cc.Services {
+		// Prometheus and Grafana watch the api, it does not wait for them.
+		if service != ServicePrometheus && service != ServiceGrafana {
+			dependencies = append(dependencies, service)
+		}
+	}
+	if len(dependencies) == 0 {
+		return
+	}
+
+	b.WriteString("    depends_on:\n")
+	for _, service := range dependencies {
+		fmt.Fprintf(b, "      %s:\n        condition: service_healthy\n", service)
+	}
+}
+
+func (cc *ComposeConfig) generateComposeOverrideContent() string {
+	if cc.WorkerOnly {
+		return "services:\n" + cc.generateWorkerOverride()
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `services:
+  api:
+    build:
+      target: builder
+    volumes:
+      - %s:/app
+      - /app/tmp
+    environment:
+      - ENV=development
+      - GO_ENV=development
+    command: ["sh", "-c", "go mod download && go run main.go"]
+`, cc.Context)
+	cc.writePorts(&b)
+	b.WriteString(`      - "2345:2345" #Delve debugger
+`)
+	// The builder stage runs from source and has no healthcheck binary, but
+	// busybox wget and the Go toolchain are available there.
+	if cc.APIPort != 0 {
+		writeHealthcheck(&b, fmt.Sprintf(`["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:%d%s"]`, cc.APIPort, cc.healthPath()), "30s", "10s", 3, "40s")
+	} else {
+		writeHealthcheck(&b, `["CMD", "go", "run", "./cmd/healthcheck"]`, "30s", "10s", 3, "40s")
+	}
+
+	if cc.WorkerDockerfile != "" {
+		b.WriteString("\n" + cc.generateWorkerOverride())
+	}
+
+	if cc.FrontendFramework != "" {
+		devPort := frontendDevPort(cc.FrontendFramework)
+		packageManager := "npm"
+		if cc.Runtime == bun {
+			packageManager = bun
+		}
+
+		fmt.Fprintf(&b, `
+  frontend:
+    build:
+      target: builder
+    volumes:
+      - ./frontend:/app
+      - /app/node_modules
+      - /app/dist
+    environment:
+      - NODE_ENV=development
+    command: ["%s", "run", "dev", "--", "--host", "0.0.0.0"]
+    ports:
+      - "%d:%d"
+`, packageManager, devPort, devPort)
+	}
+
+	return b.String()
+}
+
+// healthPath is the path the api is probed on, the grpc-gateway serves
+// /health next to the gRPC health service instead of the health package.
+func (cc *ComposeConfig) healthPath() string {
+	if cc.GRPCPort != 0 {
+		return "/health"
+	}
+	return ReadinessPath
+}
+
+func (cc *ComposeConfig) writePorts(b *strings.Builder) {
+	b.WriteString("    ports:\n")
+	if cc.APIPort != 0 {
+		fmt.Fprintf(b, "      - \"%d:%d\"\n", cc.APIPort, cc.APIPort)
+	}
+	if cc.GRPCPort != 0 {
+		fmt.Fprintf(b, "      - \"%d:%d\"\n", cc.GRPCPort, cc.GRPCPort)
+	}
+}
+
+func (cc *ComposeConfig) extraService(service string) (*composeService, error) {
+	name := composeName(cc.ProjectName)
+	dbName := strings.ReplaceAll(name, "-", "_")
+
+	switch service {
+	case ServicePostgres:
+		var b strings.Builder
+		fmt.Fprintf(&b, `  postgres:
+    image: postgres:16-alpine
+    container_name: %s-postgres
+    environment:
+      - POSTGRES_USER=%s
+      - POSTGRES_PASSWORD=%s
+      - POSTGRES_DB=%s
+    ports:
+      - "5432:5432"
+    volumes:
+      - postgres-data:/var/lib/postgresql/data
+    networks:
+      - default
+    restart: unless-stopped
+`, name, dbName, dbName, dbName)
+		writeHealthcheck(&b, fmt.Sprintf(`["CMD-SHELL", "pg_isready -U %s -d %s"]`, dbName, dbName), "10s", "5s", 5, "10s")
+		return &composeService{
+			definition: b.String(),
+			apiEnv:     []string{fmt.Sprintf("DATABASE_URL=postgres://%s:%s@postgres:5432/%s?sslmode=disable", dbName, dbName, dbName)},
+			volume:     "postgres-data",
+		}, nil
+
+	case ServiceRedis:
+		var b strings.Builder
+		fmt.Fprintf(&b, `  redis:
+    image: redis:7-alpine
+    container_name: %s-redis
+    command: ["redis-server", "--appendonly", "yes"]
+    ports:
+      - "6379:6379"
+    volumes:
+      - redis-data:/data
+    networks:
+      - default
+    restart: unless-stopped
+`, name)
+		writeHealthcheck(&b, `["CMD", "redis-cli", "ping"]`, "10s", "5s", 5, "5s")
+		apiEnv := []string{"REDIS_URL=redis://redis:6379/0"}
+		if cc.UseRateLimit {
+			apiEnv = append(apiEnv, "RATE_LIMIT_STORE=redis")
+		}
+		if cc.UseCache {
+			apiEnv = append(apiEnv, "CACHE_STORE=redis")
+		}
+		return &composeService{
+			definition: b.String(),
+			apiEnv:     apiEnv,
+			volume:     "redis-data",
+		}, nil
+
+	case ServiceMailpit:
+		var b strings.Builder
+		fmt.Fprintf(&b, `  mailpit:
+    image: axllent/mailpit:latest
+    container_name: %s-mailpit
+    ports:
+      - "1025:1025"
+      - "8025:8025"
+    networks:
+      - default
+    restart: unless-stopped
+`, name)
+		writeHealthcheck(&b, `["CMD", "/mailpit", "readyz"]`, "10s", "5s", 5, "5s")
+		return &composeService{
+			definition: b.String(),
+			apiEnv:     []string{"SMTP_HOST=mailpit", "SMTP_PORT=1025"},
+		}, nil
+
+	case ServiceMinio:
+		var b strings.Builder
+		fmt.Fprintf(&b, `  minio:
+    image: minio/minio:latest
+    container_name: %s-minio
+    command: ["server", "/data", "--console-address", ":9001"]
+    environment:
+      - MINIO_ROOT_USER=minioadmin
+      - MINIO_ROOT_PASSWORD=minioadmin
+    ports:
+      - "9000:9000"
+      - "9001:9001"
+    volumes:
+      - minio-data:/data
+    networks:
+      - default
+    restart: unless-stopped
+`, name)
+		writeHealthcheck(&b, `["CMD", "mc", "ready", "local"]`, "10s", "5s", 5, "10s")
+		return &composeService{
+			definition: b.String(),
+			apiEnv: []string{
+				"S3_ENDPOINT=http://minio:9000",
+				"S3_ACCESS_KEY=minioadmin",
+				"S3_SECRET_KEY=minioadmin",
+			},
+			volume: "minio-data",
+		}, nil
+
+	case ServiceJaeger:
+		var b strings.Builder
+		fmt.Fprintf(&b, `  jaeger:
+    image: jaegertracing/all-in-one:1.62.0
+    container_name: %s-jaeger
+    environment:
+      - COLLECTOR_OTLP_ENABLED=true
+    ports:
+      - "16686:16686"
+      - "4317:4317"
+      - "4318:4318"
+    networks:
+      - default
+    restart: unless-stopped
+`, name)
+		writeHealthcheck(&b, `["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:14269/"]`, "10s", "5s", 5, "5s")
+		return &composeService{
+			definition: b.String(),
+			apiEnv:     []string{"OTEL_EXPORTER_OTLP_ENDPOINT=http://jaeger:4318"},
+		}, nil
+
+	case ServicePrometheus:
+		var b strings.Builder
+		fmt.Fprintf(&b, `  prometheus:
+    image: prom/prometheus:v3.0.1
+    container_name: %s-prometheus
+    volumes:
+      - ./observability/prometheus.yml:/etc/prometheus/prometheus.yml:ro
+      - prometheus-data:/prometheus
+    ports:
+      - "%d:%d"
+    networks:
+      - default
+    restart: unless-stopped
+`, name, cc.hostPort(prometheusPort), prometheusPort)
+		writeHealthcheck(&b, fmt.Sprintf(`["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:%d/-/ready"]`, prometheusPort), "10s", "5s", 5, "10s")
+		return &composeService{
+			definition: b.String(),
+			volume:     "prometheus-data",
+			files: map[string]string{
+				filepath.Join("observability", "prometheus.yml"): cc.generatePrometheusConfig(),
+			},
+		}, nil
+
+	case ServiceGrafana:
+		var b strings.Builder
+		fmt.Fprintf(&b, `  grafana:
+    image: grafana/grafana:11.3.1
+    container_name: %s-grafana
+    environment:
+      - GF_AUTH_ANONYMOUS_ENABLED=true
+      - GF_AUTH_ANONYMOUS_ORG_ROLE=Admin
+      - GF_AUTH_DISABLE_LOGIN_FORM=true
+    volumes:
+      - ./observability/grafana/provisioning:/etc/grafana/provisioning:ro
+      - ./observability/grafana/dashboards:/var/lib/grafana/dashboards:ro
+      - grafana-data:/var/lib/grafana
+    ports:
+      - "%d:%d"
+    networks:
+      - default
+    restart: unless-stopped
+`, name, cc.hostPort(grafanaPort), grafanaPort)
+		writeHealthcheck(&b, fmt.Sprintf(`["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:%d/api/health"]`, grafanaPort), "10s", "5s", 5, "10s")
+		grafanaDir := filepath.Join("observability", "grafana")
+		return &composeService{
+			definition: b.String(),
+			volume:     "grafana-data",
+			files: map[string]string{
+				filepath.Join(grafanaDir, "provisioning", "datasources", "datasources.yml"): cc.generateGrafanaDatasources(),
+				filepath.Join(grafanaDir, "provisioning", "dashboards", "dashboards.yml"):   grafanaDashboardProvider,
+				filepath.Join(grafanaDir, "dashboards", "http.json"):                        grafanaHTTPDashboard,
+			},
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported compose service: %s", service)
+	}
+}
+
+// hostPort is the first port from port on that the api, gRPC server and
+// frontend do not already publish on the host.
+func (cc *ComposeConfig) hostPort(port int) int {
+	used := map[int]bool{cc.APIPort: true, cc.GRPCPort: true}
+	if cc.FrontendFramework != "" {
+		used[cc.FrontendPort] = true
+		used[frontendDevPort(cc.FrontendFramework)] = true
+	}
+	for used[port] {
+		port++
+	}
+	return port
+}
+
+func writeHealthcheck(b *strings.Builder, test, interval, timeout string, retries int, startPeriod string) {
+	fmt.Fprintf(b, `    healthcheck:
+      test: %s
+      interval: %s
+      timeout: %s
+      retries: %d
+      start_period: %s
+`, test, interval, timeout, retries, startPeriod)
+}
+
+// composeName turns a project name into something docker accepts as a
+// compose project and container name.
+func composeName(projectName string) string {
+	name := strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, filepath.Base(projectName))
+
+	name = strings.Trim(name, "-_")
+	if name == "" {
+		return "app"
+	}
+	return name
+}
+
+func frontendDevPort(framework string) int {
+	switch framework {
+	case astro:
+		return 4321
+	case angular:
+		return 4200
+	case solidjs:
+		return 3000
+	default:
+		return 5173
+	}
+}
@@ -4,20 +4,36 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	constants "github.com/luigimorel/gogen/consants"
 )
 
 func (pg *ProjectGenerator) CreateEnvFile(dirType, dirName string) error {
+	return pg.createEnvFile(dirType, dirName, APIStyleREST, "")
+}
+
+// createEnvFile writes the env files of dirType. A frontend talking to a
+// graphql API gets the GraphQL endpoint as its API URL.
+func (pg *ProjectGenerator) createEnvFile(dirType, dirName, apiStyle, framework string) error {
+	prefix := frontendEnvPrefix(framework)
+
 	var envContent string
 	if dirType == constants.APIDir {
 		envContent = `PORT=8080`
+	} else if apiStyle == APIStyleGraphQL {
+		envContent = prefix + `API_URL=http://localhost:8080` + GraphQLEndpoint + `
+` + prefix + `API_BASE_PATH=
+
+# Development
+` + prefix + `NODE_ENV=development
+`
 	} else {
-		envContent = `VITE_API_URL=http://localhost:8080
-VITE_API_BASE_PATH=/api
+		envContent = prefix + `API_URL=http://localhost:8080
+` + prefix + `API_BASE_PATH=/api
 
 # Development
-VITE_NODE_ENV=development
+` + prefix + `NODE_ENV=development
 `
 	}
 
@@ -44,21 +60,38 @@ VITE_NODE_ENV=development
 	return nil
 }
 
+// appendEnvBlock adds block to the env file at path. Packages use it to list
+// their optional settings, commented out, in .env.example.
+func appendEnvBlock(path, block string) error {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	env := string(content)
+	if env != "" && !strings.HasSuffix(env, "\n") {
+		env += "\n"
+	}
+	return os.WriteFile(path, []byte(env+"\n"+block), 0600)
+}
+
 func (pg *ProjectGenerator) CreateEnvConfig(dirName, framework string, useTypeScript bool) error {
 	if framework == angular {
 		return nil
 	}
 
+	// Qwik starters are TypeScript only.
 	fileExt := "js"
-	if useTypeScript {
+	if useTypeScript || framework == qwik {
 		fileExt = "ts"
 	}
 
+	prefix := frontendEnvPrefix(framework)
 	configContent := `/// <reference types="vite/client" />
 export const config = {
-  apiUrl: import.meta.env.VITE_API_URL,
-  apiBasePath: import.meta.env.VITE_API_BASE_PATH,
-  nodeEnv: import.meta.env.VITE_NODE_ENV,
+  apiUrl: import.meta.env.` + prefix + `API_URL,
+  apiBasePath: import.meta.env.` + prefix + `API_BASE_PATH,
+  nodeEnv: import.meta.env.` + prefix + `NODE_ENV,
 };
 
 export default config;
@@ -71,6 +104,17 @@ export default config;
 	return nil
 }
 
+// frontendEnvPrefix is the prefix of the env variables framework exposes to
+// code running in the browser. Astro and Qwik only expose PUBLIC_ ones.
+func frontendEnvPrefix(framework string) string {
+	switch framework {
+	case astro, qwik:
+		return "PUBLIC_"
+	default:
+		return "VITE_"
+	}
+}
+
 func (pg *ProjectGenerator) CreateGitignoreFile(dirType, dirName string) error {
 	var gitignoreContent string
 
@@ -81,12 +125,57 @@ func (pg *ProjectGenerator) CreateGitignoreFile(dirType, dirName string) error {
 .env.production.local
 .env.*.local
 tmp
+`
+	case "grpc", "worker", "workspace":
+		gitignoreContent = `.env
+.env.local
+.env.production.local
+.env.*.local
+tmp
+bin/
 `
 	case "cli":
 		gitignoreContent = `# Binaries for programs and plugins
 *.exe
 tmp
-main`
+main
+bin/
+
+# Release artifacts
+dist/
+completions/
+manpages/
+`
+	case "lambda":
+		gitignoreContent = `.env
+.env.local
+.env.*.local
+tmp
+bin/
+.aws-sam/
+.serverless/
+`
+	case "wasm":
+		gitignoreContent = `# Built by make build and make build-wasi
+*.wasm
+bin/
+`
+	case "lib":
+		gitignoreContent = `# Test binaries and profiles
+*.test
+*.out
+*.prof
+`
+	case "tui":
+		gitignoreContent = `# Binaries for programs and plugins
+*.exe
+tmp
+main
+bin/
+
+# Written when running with DEBUG=1
+debug.log
+`
 	default:
 		gitignoreContent = `logs
 *.log
@@ -0,0 +1,291 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const (
+	FrontendServerNginx  = "nginx"
+	FrontendServerCaddy  = "caddy"
+	FrontendServerStatic = "static"
+)
+
+func IsFrontendServer(server string) bool {
+	switch server {
+	case FrontendServerNginx, FrontendServerCaddy, FrontendServerStatic:
+		return true
+	default:
+		return false
+	}
+}
+
+// CreateFrontendServerConfig writes the web server configuration copied into
+// the frontend image. The static server is configured through environment
+// variables in the Dockerfile and needs no file.
+func (pg *ProjectGenerator) CreateFrontendServerConfig(dirName string, config *WebProjectConfig) error {
+	apiPort := config.APIPort
+	if apiPort == 0 {
+		apiPort = DefaultAPIPort
+	}
+
+	var fileName, content string
+	switch frontendServer(config) {
+	case FrontendServerNginx:
+		fileName = "nginx.conf"
+		content = fmt.Sprintf(`server {
+    listen 80;
+    listen [::]:80;
+    server_name _;
+
+    root /usr/share/nginx/html;
+    index index.html;
+
+    gzip on;
+    gzip_static on;
+    gzip_vary on;
+    gzip_min_length 1024;
+    gzip_types text/plain text/css application/javascript application/json image/svg+xml;
+
+    brotli on;
+    brotli_static on;
+    brotli_types text/plain text/css application/javascript application/json image/svg+xml;
+
+    # The trailing slash of proxy_pass replaces the /api/ prefix, the API
+    # serves its routes at the root.
+    location /api/ {
+        proxy_pass http://api:%d/;
+        proxy_http_version 1.1;
+        proxy_set_header Host $host;
+        proxy_set_header X-Real-IP $remote_addr;
+        proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+        proxy_set_header X-Forwarded-Proto $scheme;
+    }
+
+    # Hashed build assets never change, everything else is revalidated.
+    location /assets/ {
+        add_header Cache-Control "public, max-age=31536000, immutable";
+        try_files $uri =404;
+    }
+
+    location = /index.html {
+        add_header Cache-Control "no-cache";
+    }
+
+    location / {
+        try_files $uri $uri/ /index.html;
+    }
+}
+`, apiPort)
+	case FrontendServerCaddy:
+		fileName = "Caddyfile"
+		content = fmt.Sprintf(`:80 {
+	root * /srv
+	encode zstd gzip
+
+	# handle_path strips the /api prefix, the API serves its routes at the root.
+	handle_path /api/* {
+		reverse_proxy api:%d
+	}
+
+	handle {
+		# Hashed build assets never change, everything else is revalidated.
+		header /assets/* Cache-Control "public, max-age=31536000, immutable"
+		header /index.html Cache-Control "no-cache"
+
+		try_files {path} /index.html
+		file_server {
+			precompressed br gzip
+		}
+	}
+}
+`, apiPort)
+	default:
+		return nil
+	}
+
+	if err := os.WriteFile(filepath.Join(dirName, fileName), []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to create %s: %w", fileName, err)
+	}
+
+	return nil
+}
+
+func (pg *ProjectGenerator) generateFrontendDockerfile(config *WebProjectConfig) string {
+	var builderStage string
+	if config.Runtime == bun {
+		builderStage = `FROM oven/bun:1-alpine AS builder
+
+WORKDIR /app
+
+COPY package.json bun.lock ./
+
+RUN bun install --frozen-lockfile
+
+COPY . .
+
+RUN bun run build`
+	} else {
+		builderStage = `FROM node:22-alpine AS builder
+
+WORKDIR /app
+
+COPY package*.json ./
+
+RUN npm ci
+
+COPY . .
+
+RUN npm run build`
+	}
+
+	distDir := frontendDistDir(config.FrontendFramework)
+
+	var serverStage string
+	switch frontendServer(config) {
+	case FrontendServerCaddy:
+		serverStage = fmt.Sprintf(`# Caddy cannot brotli encode on the fly, so the assets are compressed ahead of time.
+RUN apk add --no-cache brotli && \
+    find %[1]s -type f \( -name '*.js' -o -name '*.css' -o -name '*.html' -o -name '*.svg' -o -name '*.json' \) \
+      -exec gzip -k -9 {} \; -exec brotli -k -q 11 {} \;
+
+FROM caddy:2-alpine
+
+COPY Caddyfile /etc/caddy/Caddyfile
+
+COPY --from=builder /app/%[1]s /srv
+
+EXPOSE 80`, distDir)
+	case FrontendServerStatic:
+		serverStage = fmt.Sprintf(`FROM joseluisq/static-web-server:2-alpine
+
+ENV SERVER_PORT=80 \
+    SERVER_ROOT=/public \
+    SERVER_FALLBACK_PAGE=/public/index.html \
+    SERVER_COMPRESSION=true \
+    SERVER_CACHE_CONTROL_HEADERS=true \
+    SERVER_HEALTH=true
+
+COPY --from=builder /app/%s /public
+
+EXPOSE 80`, distDir)
+	default:
+		serverStage = fmt.Sprintf(`RUN apk add --no-cache brotli && \
+    find %[1]s -type f \( -name '*.js' -o -name '*.css' -o -name '*.html' -o -name '*.svg' -o -name '*.json' \) \
+      -exec gzip -k -9 {} \; -exec brotli -k -q 11 {} \;
+
+# The nginx package from Alpine is used because the official image ships without brotli.
+FROM alpine:3.20
+
+RUN apk add --no-cache nginx nginx-mod-http-brotli && \
+    ln -sf /dev/stdout /var/log/nginx/access.log && \
+    ln -sf /dev/stderr /var/log/nginx/error.log
+
+COPY nginx.conf /etc/nginx/http.d/default.conf
+
+COPY --from=builder /app/%[1]s /usr/share/nginx/html
+
+EXPOSE 80
+
+CMD ["nginx", "-g", "daemon off;"]`, distDir)
+	}
+
+	return builderStage + "\n\n" + serverStage + "\n"
+}
+
+func frontendServer(config *WebProjectConfig) string {
+	if config.FrontendServer == "" {
+		return FrontendServerNginx
+	}
+	return config.FrontendServer
+}
+
+// frontendDistDir is where the production build ends up, relative to the
+// frontend directory.
+func frontendDistDir(framework string) string {
+	switch framework {
+	case angular:
+		return "dist/frontend/browser"
+	case svelte:
+		return "build"
+	default:
+		return "dist"
+	}
+}
+
+// configureStaticBuild makes the frameworks that render on a server by
+// default build a static site into frontendDistDir, which the frontend image
+// serves. SvelteKit gets adapter-static with an index.html fallback, so the
+// routes render in the browser, and Qwik City its static adapter.
+func (pg *ProjectGenerator) configureStaticBuild(framework, dirName, runtime string, useTypeScript bool) error {
+	packageManager := runtime
+	if runtime == node {
+		packageManager = "npm"
+	}
+
+	var cmd *exec.Cmd
+	switch framework {
+	case svelte:
+		if runtime == bun {
+			cmd = exec.Command(bun, "add", "-d", "@sveltejs/adapter-static")
+		} else {
+			cmd = exec.Command("npm", "install", "-D", "@sveltejs/adapter-static")
+		}
+	case qwik:
+		args := []string{"run", "qwik", "add", "static"}
+		if runtime != bun {
+			args = append(args, "--")
+		}
+		cmd = exec.Command(packageManager, append(args, "--skipConfirmation=true")...)
+	default:
+		return nil
+	}
+
+	cmd.Dir = dirName
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to add the static adapter: %w", err)
+	}
+
+	if framework != svelte {
+		return nil
+	}
+
+	if err := os.WriteFile(filepath.Join(dirName, "svelte.config.js"), []byte(svelteStaticConfig), 0600); err != nil {
+		return fmt.Errorf("failed to update svelte.config.js: %w", err)
+	}
+
+	layout := filepath.Join(dirName, "src", "routes", "+layout.js")
+	if useTypeScript {
+		layout = filepath.Join(dirName, "src", "routes", "+layout.ts")
+	}
+	content, err := os.ReadFile(filepath.Clean(layout))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", layout, err)
+	}
+	content = append(content, "// Render in the browser, the static build has no server.\nexport const ssr = false;\n"...)
+	if err := os.WriteFile(layout, content, 0600); err != nil {
+		return fmt.Errorf("failed to update %s: %w", layout, err)
+	}
+
+	return nil
+}
+
+const svelteStaticConfig = `import adapter from '@sveltejs/adapter-static';
+import { vitePreprocess } from '@sveltejs/vite-plugin-svelte';
+
+/** @type {import('@sveltejs/kit').Config} */
+const config = {
+	preprocess: vitePreprocess(),
+	kit: {
+		// Pages that are not prerendered fall back to index.html and render
+		// in the browser.
+		adapter: adapter({ fallback: 'index.html' })
+	}
+};
+
+export default config;
+`
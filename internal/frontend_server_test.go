@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFrontendServerProxy runs the generated nginx and Caddy configs in
+// front of a fake API and checks the path the API receives for a request to
+// /api/greetings. The servers are found on PATH, the test skips the ones that
+// are not installed.
+func TestFrontendServerProxy(t *testing.T) {
+	for _, server := range []string{FrontendServerNginx, FrontendServerCaddy} {
+		t.Run(server, func(t *testing.T) {
+			bin, err := exec.LookPath(server)
+			if err != nil {
+				t.Skipf("%s is not installed", server)
+			}
+
+			received := make(chan string, 1)
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case received <- r.URL.RequestURI():
+				default:
+				}
+				fmt.Fprint(w, "hello")
+			}))
+			t.Cleanup(api.Close)
+
+			dir := t.TempDir()
+			config := &WebProjectConfig{FrontendServer: server, APIPort: api.Listener.Addr().(*net.TCPAddr).Port}
+			if err := NewProjectGenerator().CreateFrontendServerConfig(dir, config); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<!doctype html>"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			port := freePort(t)
+			var cmd *exec.Cmd
+			if server == FrontendServerNginx {
+				cmd = nginxCommand(t, bin, dir, port)
+			} else {
+				cmd = caddyCommand(t, bin, dir, port)
+			}
+			logPath := filepath.Join(dir, "server.log")
+			log, err := os.Create(logPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer log.Close()
+			cmd.Stdout = log
+			cmd.Stderr = log
+			if err := cmd.Start(); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+			})
+
+			body := getWhenUp(t, fmt.Sprintf("http://127.0.0.1:%d/api/greetings?name=gopher", port), logPath)
+			if body != "hello" {
+				t.Errorf("got body %q from the proxy, want the API's", body)
+			}
+			select {
+			case path := <-received:
+				if path != "/greetings?name=gopher" {
+					t.Errorf("the API received %s, want /greetings?name=gopher", path)
+				}
+			default:
+				t.Error("the request did not reach the API")
+			}
+		})
+	}
+}
+
+// nginxCommand runs the generated server block in a minimal nginx.conf. The
+// brotli directives are dropped, stock nginx is built without the module.
+func nginxCommand(t *testing.T, bin, dir string, port int) *exec.Cmd {
+	t.Helper()
+
+	server, err := os.ReadFile(filepath.Join(dir, "nginx.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(server), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "brotli") || strings.HasPrefix(trimmed, "listen [::]") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	conf := strings.NewReplacer(
+		"listen 80;", fmt.Sprintf("listen 127.0.0.1:%d;", port),
+		"root /usr/share/nginx/html;", "root "+dir+";",
+		"http://api:", "http://127.0.0.1:",
+	).Replace(strings.Join(lines, "\n"))
+	if err := os.WriteFile(filepath.Join(dir, "server.conf"), []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	main := fmt.Sprintf(`daemon off;
+pid %[1]s/nginx.pid;
+error_log stderr;
+events {}
+http {
+    access_log off;
+    client_body_temp_path %[1]s/client_body;
+    proxy_temp_path %[1]s/proxy;
+    fastcgi_temp_path %[1]s/fastcgi;
+    uwsgi_temp_path %[1]s/uwsgi;
+    scgi_temp_path %[1]s/scgi;
+    include %[1]s/server.conf;
+}
+`, dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.conf"), []byte(main), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return exec.Command(bin, "-p", dir, "-c", filepath.Join(dir, "main.conf"))
+}
+
+// caddyCommand runs the generated Caddyfile on port, with the admin API off.
+func caddyCommand(t *testing.T, bin, dir string, port int) *exec.Cmd {
+	t.Helper()
+
+	caddyfile, err := os.ReadFile(filepath.Join(dir, "Caddyfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := "{\n\tadmin off\n}\n\n" + strings.NewReplacer(
+		":80 {", fmt.Sprintf(":%d {", port),
+		"root * /srv", "root * "+dir,
+		"reverse_proxy api:", "reverse_proxy 127.0.0.1:",
+	).Replace(string(caddyfile))
+	if err := os.WriteFile(filepath.Join(dir, "Caddyfile"), []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return exec.Command(bin, "run", "--config", filepath.Join(dir, "Caddyfile"), "--adapter", "caddyfile")
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// getWhenUp returns the body of url once the server answers, failing with
+// the server's log when it does not within a few seconds.
+func getWhenUp(t *testing.T, url, logPath string) string {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return string(body)
+		}
+		if time.Now().After(deadline) {
+			log, _ := os.ReadFile(logPath)
+			t.Fatalf("GET %s: %v\n%s", url, err, log)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
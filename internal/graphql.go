@@ -0,0 +1,717 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+// API styles of the api and web templates.
+const (
+	APIStyleREST    = "rest"
+	APIStyleGraphQL = "graphql"
+)
+
+// Paths the graph package is served on by the graphql API style.
+const (
+	GraphQLEndpoint       = "/graphql"
+	GraphQLPlaygroundPath = "/playground"
+)
+
+func IsAPIStyle(style string) bool {
+	return style == APIStyleREST || style == APIStyleGraphQL
+}
+
+// createGraphQLFiles writes gqlgen.yml and the graph package into the API
+// module in baseDir, including what gqlgen generates from the schema so the
+// project builds before go generate runs.
+func (pg *ProjectGenerator) createGraphQLFiles(baseDir, modulePath string) error {
+	dir := "graph"
+	files := map[string]string{
+		"gqlgen.yml":                                 pg.generateGraphQLConfigContent(modulePath),
+		filepath.Join(dir, "schema.graphqls"):        pg.generateGraphQLSchemaContent(),
+		filepath.Join(dir, "resolver.go"):            pg.generateGraphQLResolverContent(),
+		filepath.Join(dir, "schema.resolvers.go"):    pg.generateGraphQLSchemaResolversContent(modulePath),
+		filepath.Join(dir, "store.go"):               pg.generateGraphQLStoreContent(modulePath),
+		filepath.Join(dir, "loaders.go"):             pg.generateGraphQLLoadersContent(modulePath),
+		filepath.Join(dir, "handler.go"):             pg.generateGraphQLHandlerContent(),
+		filepath.Join(dir, "resolver_test.go"):       pg.generateGraphQLTestContent(modulePath),
+		filepath.Join(dir, "generated.go"):           pg.generateGraphQLExecContent(modulePath),
+		filepath.Join(dir, "model", "todo.go"):       pg.generateGraphQLTodoModelContent(),
+		filepath.Join(dir, "model", "models_gen.go"): pg.generateGraphQLModelsContent(),
+	}
+
+	if err := writeProjectFiles(baseDir, files); err != nil {
+		return fmt.Errorf("failed to create graph package: %w", err)
+	}
+
+	return nil
+}
+
+// addGraphQLDependencies pins gqlgen as a tool of the module in dir, at the
+// version generated.go was written by, and adds dataloadgen.
+func addGraphQLDependencies(dir string) error {
+	commands := [][]string{
+		{"go", "get", "-tool", "github.com/99designs/gqlgen@" + gqlgenVersion},
+		{"go", "get", "github.com/vikstrous/dataloadgen@" + dataloadgenVersion},
+	}
+
+	for _, args := range commands {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to add %s: %w", args[len(args)-1], err)
+		}
+	}
+
+	return nil
+}
+
+// generateGraphQLRoutesContent returns cmd/web/routes.go for the graphql API
+// style: the graph handler replaces the REST sample handlers, next to the
+// playground and the health probes.
+func (rg *RouterGenerator) generateGraphQLRoutesContent(modulePath, router string) string {
+	var routes string
+	switch router {
+	case "chi":
+		routes = `import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"` + modulePath + `/graph"
+	"` + modulePath + `/internal/health"
+	"` + modulePath + `/internal/httpx"
+)
+
+func SetupRoutes() *chi.Mux {
+	r := chi.NewRouter()
+
+	// Middleware
+	r.Use(middleware.Logger)
+	r.Use(middleware.Recoverer)
+
+	r.NotFound(httpx.NotFoundHandler().ServeHTTP)
+	r.MethodNotAllowed(httpx.MethodNotAllowedHandler().ServeHTTP)
+
+	api := graph.NewHandler(graph.NewStore())
+
+	// Routes
+	r.Method(http.MethodGet, "/", httpx.HandlerFunc(homeHandler))
+	r.Method(http.MethodGet, "` + GraphQLEndpoint + `", api)
+	r.Method(http.MethodPost, "` + GraphQLEndpoint + `", api)
+	r.Method(http.MethodGet, "` + GraphQLPlaygroundPath + `", graph.PlaygroundHandler("` + GraphQLEndpoint + `"))
+	r.Method(http.MethodGet, "/livez", health.LiveHandler())
+	r.Method(http.MethodGet, "/readyz", health.ReadyHandler())
+
+	return r
+}
+`
+	case "gorilla":
+		routes = `import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"` + modulePath + `/graph"
+	"` + modulePath + `/internal/health"
+	"` + modulePath + `/internal/httpx"
+)
+
+func SetupRoutes() *mux.Router {
+	r := mux.NewRouter()
+
+	r.NotFoundHandler = httpx.NotFoundHandler()
+	r.MethodNotAllowedHandler = httpx.MethodNotAllowedHandler()
+
+	api := graph.NewHandler(graph.NewStore())
+
+	// Routes
+	r.Handle("/", httpx.HandlerFunc(homeHandler)).Methods("GET")
+	r.Handle("` + GraphQLEndpoint + `", api).Methods("GET", "POST")
+	r.Handle("` + GraphQLPlaygroundPath + `", graph.PlaygroundHandler("` + GraphQLEndpoint + `")).Methods("GET")
+	r.Handle("/livez", health.LiveHandler()).Methods("GET")
+	r.Handle("/readyz", health.ReadyHandler()).Methods("GET")
+
+	return r
+}
+`
+	case "httprouter":
+		routes = `import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"` + modulePath + `/graph"
+	"` + modulePath + `/internal/health"
+	"` + modulePath + `/internal/httpx"
+)
+
+func SetupRoutes() *httprouter.Router {
+	router := httprouter.New()
+
+	router.NotFound = httpx.NotFoundHandler()
+	router.MethodNotAllowed = httpx.MethodNotAllowedHandler()
+
+	api := graph.NewHandler(graph.NewStore())
+
+	// Routes
+	router.Handler(http.MethodGet, "/", httpx.HandlerFunc(homeHandler))
+	router.Handler(http.MethodGet, "` + GraphQLEndpoint + `", api)
+	router.Handler(http.MethodPost, "` + GraphQLEndpoint + `", api)
+	router.Handler(http.MethodGet, "` + GraphQLPlaygroundPath + `", graph.PlaygroundHandler("` + GraphQLEndpoint + `"))
+	router.Handler(http.MethodGet, "/livez", health.LiveHandler())
+	router.Handler(http.MethodGet, "/readyz", health.ReadyHandler())
+
+	return router
+}
+`
+	default:
+		return `package web
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"` + modulePath + `/graph"
+	"` + modulePath + `/internal/health"
+	"` + modulePath + `/internal/httpx"
+)
+
+func SetupRoutes() {
+	api := graph.NewHandler(graph.NewStore())
+
+	http.Handle("/", httpx.HandlerFunc(homeHandler))
+	http.Handle("` + GraphQLEndpoint + `", allow(api, http.MethodGet, http.MethodPost))
+	http.Handle("` + GraphQLPlaygroundPath + `", allow(graph.PlaygroundHandler("` + GraphQLEndpoint + `"), http.MethodGet))
+	http.Handle("/livez", health.LiveHandler())
+	http.Handle("/readyz", health.ReadyHandler())
+}
+
+// allow answers requests with any other method than methods with a 405.
+func allow(next http.Handler, methods ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(methods, r.Method) {
+			w.Header().Set("Allow", strings.Join(methods, ", "))
+			httpx.MethodNotAllowedHandler().ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type messageResponse struct {
+	Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+func homeHandler(w http.ResponseWriter, r *http.Request) error {
+	// "/" matches every path nothing else does.
+	if r.URL.Path != "/" {
+		httpx.NotFoundHandler().ServeHTTP(w, r)
+		return nil
+	}
+
+	return httpx.Encode(w, http.StatusOK, messageResponse{Message: "Hello from web server"})
+}
+`
+	}
+
+	return `package web
+
+` + routes + `
+type messageResponse struct {
+	Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+func homeHandler(w http.ResponseWriter, r *http.Request) error {
+	return httpx.Encode(w, http.StatusOK, messageResponse{Message: "Hello from web server"})
+}
+`
+}
+
+func (pg *ProjectGenerator) generateGraphQLConfigContent(modulePath string) string {
+	return `# gqlgen reads the schema in graph/*.graphqls and writes graph/generated.go
+# and graph/model/models_gen.go. Run go generate ./... after changing the
+# schema and implement the new resolvers it adds to graph/*.resolvers.go.
+schema:
+  - graph/*.graphqls
+
+exec:
+  package: graph
+  layout: single-file
+  filename: graph/generated.go
+
+model:
+  package: model
+  filename: graph/model/models_gen.go
+
+resolver:
+  package: graph
+  layout: follow-schema
+  dir: graph
+  filename_template: "{name}.resolvers.go"
+
+# Types declared in graph/model are used as they are, Todo keeps the id of
+# its user so the user field gets a resolver backed by the dataloader.
+autobind:
+  - "` + modulePath + `/graph/model"
+
+models:
+  ID:
+    model:
+      - github.com/99designs/gqlgen/graphql.ID
+`
+}
+
+func (pg *ProjectGenerator) generateGraphQLSchemaContent() string {
+	return `# The schema of the API. gqlgen generates the Go types and the executor from
+# it, see gqlgen.yml.
+
+type Todo {
+  id: ID!
+  text: String!
+  done: Boolean!
+  user: User!
+}
+
+type User {
+  id: ID!
+  name: String!
+}
+
+type Query {
+  todos: [Todo!]!
+  todo(id: ID!): Todo
+}
+
+input NewTodo {
+  text: String!
+  userId: ID!
+}
+
+type Mutation {
+  createTodo(input: NewTodo!): Todo!
+  completeTodo(id: ID!): Todo!
+}
+`
+}
+
+func (pg *ProjectGenerator) generateGraphQLTodoModelContent() string {
+	return `package model
+
+// Todo is declared by hand instead of generated: it keeps the id of its user
+// rather than the user, which the Todo.user resolver loads.
+type Todo struct {
+	ID     string ` + "`" + `json:"id"` + "`" + `
+	Text   string ` + "`" + `json:"text"` + "`" + `
+	Done   bool   ` + "`" + `json:"done"` + "`" + `
+	UserID string ` + "`" + `json:"userId"` + "`" + `
+}
+`
+}
+
+func (pg *ProjectGenerator) generateGraphQLResolverContent() string {
+	return `// Package graph serves the GraphQL API. schema.graphqls is the source of
+// truth: gqlgen generates generated.go and model/models_gen.go from it and
+// adds a resolver to schema.resolvers.go for every new field, which keeps
+// the code already written there.
+package graph
+
+//go:generate go tool gqlgen generate
+
+// Resolver is the root resolver, it holds what the resolvers in
+// *.resolvers.go share. gqlgen does not overwrite this file.
+type Resolver struct {
+	Store *Store
+}
+`
+}
+
+// generateGraphQLSchemaResolversContent returns schema.resolvers.go as
+// gqlgen rewrites it, so go generate leaves the implemented resolvers as
+// they are.
+func (pg *ProjectGenerator) generateGraphQLSchemaResolversContent(modulePath string) string {
+	imports := gqlgenImports(`"context"`, strconv.Quote(modulePath+"/graph/model"))
+
+	return `package graph
+
+// This file will be automatically regenerated based on the schema, any resolver
+// implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.95
+
+import (
+` + imports + `)
+
+// CreateTodo is the resolver for the createTodo field.
+func (r *mutationResolver) CreateTodo(ctx context.Context, input model.NewTodo) (*model.Todo, error) {
+	return r.Store.CreateTodo(ctx, input)
+}
+
+// CompleteTodo is the resolver for the completeTodo field.
+func (r *mutationResolver) CompleteTodo(ctx context.Context, id string) (*model.Todo, error) {
+	return r.Store.CompleteTodo(ctx, id)
+}
+
+// Todos is the resolver for the todos field.
+func (r *queryResolver) Todos(ctx context.Context) ([]*model.Todo, error) {
+	return r.Store.Todos(ctx)
+}
+
+// Todo is the resolver for the todo field.
+func (r *queryResolver) Todo(ctx context.Context, id string) (*model.Todo, error) {
+	return r.Store.Todo(ctx, id)
+}
+
+// User is the resolver for the user field.
+func (r *todoResolver) User(ctx context.Context, obj *model.Todo) (*model.User, error) {
+	return For(ctx).UserByID.Load(ctx, obj.UserID)
+}
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Todo returns TodoResolver implementation.
+func (r *Resolver) Todo() TodoResolver { return &todoResolver{r} }
+
+type (
+	mutationResolver struct{ *Resolver }
+	queryResolver    struct{ *Resolver }
+	todoResolver     struct{ *Resolver }
+)
+`
+}
+
+func (pg *ProjectGenerator) generateGraphQLStoreContent(modulePath string) string {
+	return `package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"` + modulePath + `/graph/model"
+)
+
+// Store keeps the todos and users in memory. Replace it with your database,
+// the resolvers only use its methods.
+type Store struct {
+	mu     sync.RWMutex
+	todos  map[string]*model.Todo
+	users  map[string]*model.User
+	nextID int
+}
+
+// NewStore returns a store with two sample users and a todo each.
+func NewStore() *Store {
+	s := &Store{
+		todos: make(map[string]*model.Todo),
+		users: map[string]*model.User{
+			"1": {ID: "1", Name: "Ada"},
+			"2": {ID: "2", Name: "Grace"},
+		},
+	}
+	s.add("Write the schema", "1")
+	s.add("Implement the resolvers", "2")
+	return s
+}
+
+// Todos returns every todo, oldest first.
+func (s *Store) Todos(ctx context.Context) ([]*model.Todo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	todos := make([]*model.Todo, 0, len(s.todos))
+	for _, todo := range s.todos {
+		copied := *todo
+		todos = append(todos, &copied)
+	}
+	sort.Slice(todos, func(i, j int) bool {
+		a, _ := strconv.Atoi(todos[i].ID)
+		b, _ := strconv.Atoi(todos[j].ID)
+		return a < b
+	})
+	return todos, nil
+}
+
+// Todo returns the todo with id, or nil when there is none.
+func (s *Store) Todo(ctx context.Context, id string) (*model.Todo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	todo, ok := s.todos[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *todo
+	return &copied, nil
+}
+
+// CreateTodo adds a todo for an existing user.
+func (s *Store) CreateTodo(ctx context.Context, input model.NewTodo) (*model.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[input.UserID]; !ok {
+		return nil, fmt.Errorf("user %s not found", input.UserID)
+	}
+	copied := *s.add(input.Text, input.UserID)
+	return &copied, nil
+}
+
+// CompleteTodo marks the todo with id as done.
+func (s *Store) CompleteTodo(ctx context.Context, id string) (*model.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, ok := s.todos[id]
+	if !ok {
+		return nil, fmt.Errorf("todo %s not found", id)
+	}
+	todo.Done = true
+	copied := *todo
+	return &copied, nil
+}
+
+// UsersByIDs returns the users with ids in the same order, with an error
+// in place of the ones that do not exist. The user dataloader calls it
+// once for all the users a request needs.
+func (s *Store) UsersByIDs(ctx context.Context, ids []string) ([]*model.User, []error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]*model.User, len(ids))
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		user, ok := s.users[id]
+		if !ok {
+			errs[i] = fmt.Errorf("user %s not found", id)
+			continue
+		}
+		copied := *user
+		users[i] = &copied
+	}
+	return users, errs
+}
+
+// add stores a new todo, the caller holds the write lock.
+func (s *Store) add(text, userID string) *model.Todo {
+	s.nextID++
+	todo := &model.Todo{ID: strconv.Itoa(s.nextID), Text: text, UserID: userID}
+	s.todos[todo.ID] = todo
+	return todo
+}
+`
+}
+
+func (pg *ProjectGenerator) generateGraphQLLoadersContent(modulePath string) string {
+	return `package graph
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/vikstrous/dataloadgen"
+
+	"` + modulePath + `/graph/model"
+)
+
+type loadersKey struct{}
+
+// Loaders batch the lookups resolvers make while a request runs, so listing
+// n todos loads their users in one call instead of n.
+type Loaders struct {
+	UserByID *dataloadgen.Loader[string, *model.User]
+}
+
+// NewLoaders returns loaders for a single request. They cache what they
+// load, so they must not be shared between requests.
+func NewLoaders(store *Store) *Loaders {
+	return &Loaders{
+		UserByID: dataloadgen.NewLoader(store.UsersByIDs, dataloadgen.WithWait(time.Millisecond)),
+	}
+}
+
+// Middleware gives every request its own Loaders.
+func Middleware(store *Store, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, NewLoaders(store))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// For returns the Loaders Middleware added to ctx.
+func For(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateGraphQLHandlerContent() string {
+	return `package graph
+
+import (
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// NewHandler serves the GraphQL API over GET and POST, backed by store.
+func NewHandler(store *Store) http.Handler {
+	return Middleware(store, newServer(store))
+}
+
+func newServer(store *Store) *handler.Server {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{Store: store}}))
+
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New[string](100),
+	})
+
+	return srv
+}
+
+// PlaygroundHandler serves the GraphQL playground, sending queries to
+// endpoint.
+func PlaygroundHandler(endpoint string) http.Handler {
+	return playground.Handler("GraphQL playground", endpoint)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateGraphQLTestContent(modulePath string) string {
+	return `package graph
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/vikstrous/dataloadgen"
+
+	"` + modulePath + `/graph/model"
+)
+
+type todosResponse struct {
+	Todos []struct {
+		ID   string
+		Text string
+		Done bool
+		User struct {
+			ID   string
+			Name string
+		}
+	}
+}
+
+func TestTodos(t *testing.T) {
+	c := client.New(NewHandler(NewStore()))
+
+	var resp todosResponse
+	c.MustPost(` + "`" + `{ todos { id text done user { id name } } }` + "`" + `, &resp)
+
+	if len(resp.Todos) != 2 {
+		t.Fatalf("got %d todos, want 2", len(resp.Todos))
+	}
+	if got := resp.Todos[0].User.Name; got != "Ada" {
+		t.Errorf("first todo user = %q, want %q", got, "Ada")
+	}
+	if got := resp.Todos[1].User.Name; got != "Grace" {
+		t.Errorf("second todo user = %q, want %q", got, "Grace")
+	}
+}
+
+func TestCreateAndCompleteTodo(t *testing.T) {
+	c := client.New(NewHandler(NewStore()))
+
+	var created struct {
+		CreateTodo struct {
+			ID   string
+			Done bool
+			User struct{ Name string }
+		}
+	}
+	c.MustPost(` + "`" + `mutation($text: String!, $userId: ID!) {
+		createTodo(input: {text: $text, userId: $userId}) { id done user { name } }
+	}` + "`" + `, &created, client.Var("text", "Ship it"), client.Var("userId", "2"))
+
+	if created.CreateTodo.Done || created.CreateTodo.User.Name != "Grace" {
+		t.Fatalf("createTodo = %+v", created.CreateTodo)
+	}
+
+	var completed struct {
+		CompleteTodo struct{ Done bool }
+	}
+	c.MustPost(` + "`" + `mutation($id: ID!) { completeTodo(id: $id) { done } }` + "`" + `,
+		&completed, client.Var("id", created.CreateTodo.ID))
+
+	if !completed.CompleteTodo.Done {
+		t.Error("completeTodo did not mark the todo as done")
+	}
+}
+
+func TestCreateTodoUnknownUser(t *testing.T) {
+	c := client.New(NewHandler(NewStore()))
+
+	var resp struct{ CreateTodo struct{ ID string } }
+	err := c.Post(` + "`" + `mutation { createTodo(input: {text: "x", userId: "404"}) { id } }` + "`" + `, &resp)
+	if err == nil {
+		t.Fatal("expected an error for an unknown user")
+	}
+}
+
+func TestUsersLoadInOneBatch(t *testing.T) {
+	store := NewStore()
+	for i := 0; i < 10; i++ {
+		if _, err := store.CreateTodo(context.Background(), model.NewTodo{Text: "todo", UserID: "1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var batches atomic.Int32
+	fetch := func(ctx context.Context, ids []string) ([]*model.User, []error) {
+		batches.Add(1)
+		return store.UsersByIDs(ctx, ids)
+	}
+
+	srv := newServer(store)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loaders := &Loaders{UserByID: dataloadgen.NewLoader(fetch, dataloadgen.WithWait(10*time.Millisecond))}
+		srv.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loadersKey{}, loaders)))
+	})
+
+	var resp todosResponse
+	client.New(h).MustPost(` + "`" + `{ todos { user { name } } }` + "`" + `, &resp)
+
+	if len(resp.Todos) != 12 {
+		t.Fatalf("got %d todos, want 12", len(resp.Todos))
+	}
+	if got := batches.Load(); got != 1 {
+		t.Errorf("users loaded in %d batches, want 1", got)
+	}
+}
+`
+}
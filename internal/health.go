@@ -0,0 +1,463 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Probe paths served by the health package of generated APIs.
+const (
+	LivenessPath  = "/livez"
+	ReadinessPath = "/readyz"
+)
+
+// createHealthFiles writes internal/health into the API module in baseDir.
+// The routes of every router register its liveness and readiness handlers.
+func (pg *ProjectGenerator) createHealthFiles(baseDir string) error {
+	dir := filepath.Join("internal", "health")
+	files := map[string]string{
+		filepath.Join(dir, "health.go"):      pg.generateHealthContent(),
+		filepath.Join(dir, "checks.go"):      pg.generateHealthChecksContent(),
+		filepath.Join(dir, "health_test.go"): pg.generateHealthTestContent(),
+	}
+
+	if err := writeProjectFiles(baseDir, files); err != nil {
+		return fmt.Errorf("failed to create health package: %w", err)
+	}
+
+	return nil
+}
+
+func (pg *ProjectGenerator) generateHealthContent() string {
+	return `// Package health serves the liveness and readiness endpoints of the API.
+//
+// Liveness (/livez) only reports that the process is up and serving
+// requests. It never depends on other systems, so an outage of the database
+// does not get every replica restarted. Readiness (/readyz) runs the checks
+// registered by the components the API depends on, typically from main:
+//
+//	health.Register("postgres", 2*time.Second, health.PingCheck(db))
+//	health.Register("redis", time.Second, func(ctx context.Context) error {
+//		return rdb.Ping(ctx).Err()
+//	})
+//	health.Register("billing", 3*time.Second, health.HTTPCheck(http.DefaultClient, billingURL+"/livez"))
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout applies to checks registered without a timeout.
+const DefaultTimeout = 2 * time.Second
+
+// DefaultCacheTTL is how long the Default registry reuses the result of a
+// run, so a burst of probes from several load balancers runs the checks once.
+const DefaultCacheTTL = time.Second
+
+// CheckFunc reports whether a dependency is usable. It should return once
+// ctx is done.
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Status    string  ` + "`" + `json:"status"` + "`" + `
+	LatencyMS float64 ` + "`" + `json:"latency_ms"` + "`" + `
+	Error     string  ` + "`" + `json:"error,omitempty"` + "`" + `
+}
+
+// Report is the JSON body of the liveness and readiness endpoints.
+type Report struct {
+	Status    string                 ` + "`" + `json:"status"` + "`" + `
+	Checks    map[string]CheckResult ` + "`" + `json:"checks,omitempty"` + "`" + `
+	CheckedAt time.Time              ` + "`" + `json:"checked_at"` + "`" + `
+}
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      CheckFunc
+}
+
+// Registry holds the readiness checks and the result of their last run.
+type Registry struct {
+	cacheTTL time.Duration
+
+	mu     sync.Mutex
+	checks []check
+
+	// runMu is held while the checks run, callers arriving meanwhile wait
+	// for that run and share its result.
+	runMu  sync.Mutex
+	last   *Report
+	lastAt time.Time
+}
+
+// NewRegistry returns an empty registry that reuses results for cacheTTL.
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{cacheTTL: cacheTTL}
+}
+
+// Default is the registry behind the package-level functions and the
+// handlers the routes register.
+var Default = NewRegistry(DefaultCacheTTL)
+
+// Register adds a readiness check to the Default registry.
+func Register(name string, timeout time.Duration, fn CheckFunc) {
+	Default.Register(name, timeout, fn)
+}
+
+// LiveHandler serves liveness for the Default registry.
+func LiveHandler() http.Handler {
+	return Default.LiveHandler()
+}
+
+// ReadyHandler serves readiness for the Default registry.
+func ReadyHandler() http.Handler {
+	return Default.ReadyHandler()
+}
+
+// Register adds a check that fails when fn returns an error or takes longer
+// than timeout. A timeout of zero means DefaultTimeout.
+func (r *Registry) Register(name string, timeout time.Duration, fn CheckFunc) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check{name: name, timeout: timeout, fn: fn})
+}
+
+// Check runs every registered check concurrently, or returns the result of
+// the previous run when it is younger than the cache TTL.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	if r.last != nil && time.Since(r.lastAt) < r.cacheTTL {
+		return *r.last
+	}
+
+	// The result is shared with other callers, so it must not be cut short
+	// because the caller that happened to trigger the run went away.
+	report := r.run(context.WithoutCancel(ctx))
+	r.last, r.lastAt = &report, time.Now()
+	return report
+}
+
+func (r *Registry) run(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := make([]check, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.Unlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusOK,
+		Checks:    make(map[string]CheckResult, len(checks)),
+		CheckedAt: time.Now().UTC(),
+	}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c check) run(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LiveHandler always reports ok while the server is able to answer.
+func (r *Registry) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeReport(w, Report{Status: StatusOK, CheckedAt: time.Now().UTC()})
+	})
+}
+
+// ReadyHandler reports the registered checks, with 503 Service Unavailable
+// when any of them fails.
+func (r *Registry) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Check(req.Context()))
+	})
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != StatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateHealthChecksContent() string {
+	return `package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Pinger is implemented by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingCheck checks a database connection pool.
+func PingCheck(db Pinger) CheckFunc {
+	return db.PingContext
+}
+
+// HTTPCheck checks a downstream HTTP service, any status below 400 from url
+// counts as healthy.
+func HTTPCheck(client *http.Client, url string) CheckFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("%s returned HTTP %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateHealthTestContent() string {
+	return `package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func serve(t *testing.T, h http.Handler) (int, Report) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	return rec.Code, report
+}
+
+func TestLiveHandlerIgnoresChecks(t *testing.T) {
+	r := NewRegistry(0)
+	r.Register("down", 0, func(context.Context) error { return errors.New("down") })
+
+	code, report := serve(t, r.LiveHandler())
+	if code != http.StatusOK || report.Status != StatusOK {
+		t.Errorf("got %d %q, want 200 ok", code, report.Status)
+	}
+	if len(report.Checks) != 0 {
+		t.Errorf("liveness ran checks: %v", report.Checks)
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	r := NewRegistry(0)
+
+	code, report := serve(t, r.ReadyHandler())
+	if code != http.StatusOK || report.Status != StatusOK {
+		t.Errorf("without checks got %d %q, want 200 ok", code, report.Status)
+	}
+
+	r.Register("db", time.Second, func(context.Context) error { return nil })
+	r.Register("cache", time.Second, func(context.Context) error { return errors.New("connection refused") })
+
+	code, report = serve(t, r.ReadyHandler())
+	if code != http.StatusServiceUnavailable || report.Status != StatusFail {
+		t.Errorf("got %d %q, want 503 fail", code, report.Status)
+	}
+	if got := report.Checks["db"]; got.Status != StatusOK || got.Error != "" {
+		t.Errorf("db = %+v, want ok", got)
+	}
+	if got := report.Checks["cache"]; got.Status != StatusFail || got.Error != "connection refused" {
+		t.Errorf("cache = %+v, want fail with the check error", got)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	r := NewRegistry(0)
+	r.Register("slow", 20*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	r.Register("stuck", 20*time.Millisecond, func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	report := r.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Check took %s, want it bounded by the check timeouts", elapsed)
+	}
+
+	for _, name := range []string{"slow", "stuck"} {
+		got := report.Checks[name]
+		if got.Status != StatusFail {
+			t.Errorf("%s = %+v, want fail", name, got)
+		}
+		if got.LatencyMS < 20 {
+			t.Errorf("%s latency = %vms, want at least the timeout", name, got.LatencyMS)
+		}
+	}
+	if !strings.Contains(report.Checks["stuck"].Error, "timed out") {
+		t.Errorf("stuck error = %q, want a timeout", report.Checks["stuck"].Error)
+	}
+}
+
+func TestCheckCachesResults(t *testing.T) {
+	var runs atomic.Int32
+	r := NewRegistry(time.Hour)
+	r.Register("db", time.Second, func(context.Context) error {
+		runs.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Check(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if n := runs.Load(); n != 1 {
+		t.Errorf("check ran %d times for concurrent probes, want 1", n)
+	}
+
+	r.cacheTTL = 0
+	r.Check(context.Background())
+	if n := runs.Load(); n != 2 {
+		t.Errorf("check ran %d times after the cache expired, want 2", n)
+	}
+}
+
+func TestCheckOutlivesCaller(t *testing.T) {
+	r := NewRegistry(time.Hour)
+	r.Register("db", time.Second, func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if report := r.Check(ctx); report.Status != StatusOK {
+		t.Errorf("got %+v, want the shared result unaffected by the caller's context", report)
+	}
+}
+
+type pinger struct{ err error }
+
+func (p pinger) PingContext(context.Context) error { return p.err }
+
+func TestPingCheck(t *testing.T) {
+	if err := PingCheck(pinger{})(context.Background()); err != nil {
+		t.Errorf("PingCheck() = %v, want nil", err)
+	}
+	want := errors.New("no connection")
+	if err := PingCheck(pinger{err: want})(context.Background()); !errors.Is(err, want) {
+		t.Errorf("PingCheck() = %v, want %v", err, want)
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	if err := HTTPCheck(srv.Client(), srv.URL+"/up")(context.Background()); err != nil {
+		t.Errorf("HTTPCheck(/up) = %v, want nil", err)
+	}
+	if err := HTTPCheck(srv.Client(), srv.URL+"/down")(context.Background()); err == nil {
+		t.Error("HTTPCheck(/down) = nil, want an error")
+	}
+}
+`
+}
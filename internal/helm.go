@@ -0,0 +1,285 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (pg *ProjectGenerator) createHelmChart(dirName string, config *K8sConfig) error {
+	chartDir := filepath.Join(dirName, helmDir, config.ProjectName)
+	templatesDir := filepath.Join(chartDir, "templates")
+	if err := os.MkdirAll(templatesDir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", templatesDir, err)
+	}
+
+	files := map[string]string{
+		filepath.Join(chartDir, "Chart.yaml"):       config.generateChart(),
+		filepath.Join(chartDir, "values.yaml"):      config.generateValues(),
+		filepath.Join(chartDir, ".helmignore"):      ".git/\n.gitignore\n*.swp\n*.tmp\n",
+		filepath.Join(templatesDir, "_helpers.tpl"): config.generateHelpers(),
+		filepath.Join(templatesDir, "ingress.yaml"): config.generateHelmIngress(),
+		filepath.Join(templatesDir, "NOTES.txt"):    config.generateHelmNotes(),
+	}
+
+	for _, component := range config.Components {
+		files[filepath.Join(templatesDir, component.Name+"-deployment.yaml")] = config.generateHelmDeployment(component)
+		files[filepath.Join(templatesDir, component.Name+"-service.yaml")] = config.generateHelmService(component)
+		if len(component.Config) > 0 {
+			files[filepath.Join(templatesDir, component.Name+"-configmap.yaml")] = config.generateHelmConfigMap(component)
+		}
+	}
+
+	for _, path := range sortedKeys(files) {
+		if err := os.WriteFile(path, []byte(files[path]), 0600); err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func (kc *K8sConfig) generateChart() string {
+	return `apiVersion: v2
+name: ` + kc.ProjectName + `
+description: A Helm chart for ` + kc.ProjectName + `
+type: application
+version: 0.1.0
+appVersion: "0.1.0"
+`
+}
+
+func (kc *K8sConfig) generateValues() string {
+	var b strings.Builder
+
+	for _, component := range kc.Components {
+		fmt.Fprintf(&b, `%s:
+  replicaCount: 2
+  image:
+    repository: %s
+    tag: latest
+    pullPolicy: IfNotPresent
+  port: %d
+  livenessPath: %s
+  readinessPath: %s
+  resources:
+    requests:
+      cpu: 100m
+      memory: 64Mi
+    limits:
+      cpu: 500m
+      memory: 256Mi
+`, component.Name, kc.resourceName(component), component.Port, component.LivenessPath, component.ReadinessPath)
+
+		if len(component.Config) > 0 {
+			b.WriteString("  config:\n")
+			for _, key := range sortedKeys(component.Config) {
+				fmt.Fprintf(&b, "    %s: %q\n", key, component.Config[key])
+			}
+		}
+		if len(component.Secrets) > 0 {
+			fmt.Fprintf(&b, "  # Name of an existing Secret holding %s\n", strings.Join(component.Secrets, ", "))
+			b.WriteString("  existingSecret: \"\"\n")
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, `ingress:
+  enabled: true
+  className: nginx
+  host: %s.local
+`, kc.ProjectName)
+
+	return b.String()
+}
+
+func (kc *K8sConfig) generateHelpers() string {
+	name := kc.ProjectName
+	return `{{- define "` + name + `.fullname" -}}
+{{- if .Values.fullnameOverride }}
+{{- .Values.fullnameOverride | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+
+{{- define "` + name + `.selectorLabels" -}}
+app.kubernetes.io/name: {{ .Chart.Name }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+
+{{- define "` + name + `.labels" -}}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" }}
+{{ include "` + name + `.selectorLabels" . }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+`
+}
+
+func (kc *K8sConfig) generateHelmDeployment(component K8sComponent) string {
+	name := kc.ProjectName
+	c := component.Name
+
+	envFrom := ""
+	if len(component.Config) > 0 || len(component.Secrets) > 0 {
+		envFrom = `          envFrom:
+`
+		if len(component.Config) > 0 {
+			envFrom += `            - configMapRef:
+                name: {{ include "` + name + `.fullname" . }}-` + c + `
+`
+		}
+		if len(component.Secrets) > 0 {
+			envFrom += `            {{- with .Values.` + c + `.existingSecret }}
+            - secretRef:
+                name: {{ . }}
+            {{- end }}
+`
+		}
+	}
+
+	return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "` + name + `.fullname" . }}-` + c + `
+  labels:
+    {{- include "` + name + `.labels" . | nindent 4 }}
+    app.kubernetes.io/component: ` + c + `
+spec:
+  replicas: {{ .Values.` + c + `.replicaCount }}
+  selector:
+    matchLabels:
+      {{- include "` + name + `.selectorLabels" . | nindent 6 }}
+      app.kubernetes.io/component: ` + c + `
+  template:
+    metadata:
+      labels:
+        {{- include "` + name + `.selectorLabels" . | nindent 8 }}
+        app.kubernetes.io/component: ` + c + `
+    spec:
+      containers:
+        - name: ` + c + `
+          image: "{{ .Values.` + c + `.image.repository }}:{{ .Values.` + c + `.image.tag }}"
+          imagePullPolicy: {{ .Values.` + c + `.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: {{ .Values.` + c + `.port }}
+` + envFrom + `          livenessProbe:
+            httpGet:
+              path: {{ .Values.` + c + `.livenessPath }}
+              port: http
+            initialDelaySeconds: 10
+            periodSeconds: 15
+          readinessProbe:
+            httpGet:
+              path: {{ .Values.` + c + `.readinessPath }}
+              port: http
+            initialDelaySeconds: 5
+            periodSeconds: 10
+          resources:
+            {{- toYaml .Values.` + c + `.resources | nindent 12 }}
+`
+}
+
+func (kc *K8sConfig) generateHelmService(component K8sComponent) string {
+	name := kc.ProjectName
+	c := component.Name
+
+	return `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "` + name + `.fullname" . }}-` + c + `
+  labels:
+    {{- include "` + name + `.labels" . | nindent 4 }}
+    app.kubernetes.io/component: ` + c + `
+spec:
+  type: ClusterIP
+  selector:
+    {{- include "` + name + `.selectorLabels" . | nindent 4 }}
+    app.kubernetes.io/component: ` + c + `
+  ports:
+    - name: http
+      port: {{ .Values.` + c + `.port }}
+      targetPort: http
+`
+}
+
+func (kc *K8sConfig) generateHelmConfigMap(component K8sComponent) string {
+	name := kc.ProjectName
+	c := component.Name
+
+	return `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "` + name + `.fullname" . }}-` + c + `
+  labels:
+    {{- include "` + name + `.labels" . | nindent 4 }}
+data:
+  {{- range $key, $value := .Values.` + c + `.config }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+`
+}
+
+func (kc *K8sConfig) generateHelmIngress() string {
+	name := kc.ProjectName
+	root, prefixed := kc.ingressGroups()
+
+	var docs []string
+	if len(root) > 0 {
+		docs = append(docs, kc.generateHelmIngressResource(`{{ include "`+name+`.fullname" . }}`, "", root))
+	}
+	for _, component := range prefixed {
+		docs = append(docs, kc.generateHelmIngressResource(`{{ include "`+name+`.fullname" . }}-`+component.Name, ingressRewriteAnnotations, []K8sComponent{component}))
+	}
+
+	return `{{- if .Values.ingress.enabled }}
+` + strings.Join(docs, "---\n") + `{{- end }}
+`
+}
+
+func (kc *K8sConfig) generateHelmIngressResource(resourceName, annotations string, components []K8sComponent) string {
+	name := kc.ProjectName
+
+	var paths strings.Builder
+	for _, component := range components {
+		path, pathType := ingressPath(component)
+		paths.WriteString(`          - path: ` + path + `
+            pathType: ` + pathType + `
+            backend:
+              service:
+                name: {{ include "` + name + `.fullname" . }}-` + component.Name + `
+                port:
+                  name: http
+`)
+	}
+
+	if annotations != "" {
+		annotations = "  annotations:\n" + annotations
+	}
+
+	return `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: ` + resourceName + `
+  labels:
+    {{- include "` + name + `.labels" . | nindent 4 }}
+` + annotations + `spec:
+  ingressClassName: {{ .Values.ingress.className }}
+  rules:
+    - host: {{ .Values.ingress.host }}
+      http:
+        paths:
+` + paths.String()
+}
+
+func (kc *K8sConfig) generateHelmNotes() string {
+	return `{{- if .Values.ingress.enabled }}
+` + kc.ProjectName + ` is available at http://{{ .Values.ingress.host }}
+{{- else }}
+Run "kubectl port-forward svc/{{ include "` + kc.ProjectName + `.fullname" . }}-` + kc.Components[0].Name + ` 8080:{{ .Values.` + kc.Components[0].Name + `.port }}" to reach the service.
+{{- end }}
+`
+}
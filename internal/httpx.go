@@ -0,0 +1,529 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// createHTTPXFiles writes internal/httpx into the API module in baseDir.
+// The routes of every router decode, encode and report errors through it.
+func (pg *ProjectGenerator) createHTTPXFiles(baseDir string) error {
+	dir := filepath.Join("internal", "httpx")
+	files := map[string]string{
+		filepath.Join(dir, "json.go"):       pg.generateHTTPXJSONContent(),
+		filepath.Join(dir, "errors.go"):     pg.generateHTTPXErrorsContent(),
+		filepath.Join(dir, "validate.go"):   pg.generateHTTPXValidateContent(),
+		filepath.Join(dir, "httpx_test.go"): pg.generateHTTPXTestContent(),
+	}
+
+	if err := writeProjectFiles(baseDir, files); err != nil {
+		return fmt.Errorf("failed to create httpx package: %w", err)
+	}
+
+	return nil
+}
+
+func (pg *ProjectGenerator) generateHTTPXJSONContent() string {
+	return `// Package httpx holds the JSON helpers and the error responses shared by
+// the HTTP handlers.
+//
+// Handlers are HandlerFunc values that return an error instead of writing
+// one. Errors built with the constructors in this package, such as NotFound
+// or Errorf, become RFC 7807 problem+json responses with their status, any
+// other error becomes a 500 and is logged without leaking its message:
+//
+//	func getUser(w http.ResponseWriter, r *http.Request) error {
+//		user, err := store.User(r.Context(), id)
+//		if errors.Is(err, store.ErrNotFound) {
+//			return httpx.NotFound("user %s does not exist", id)
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		return httpx.Encode(w, http.StatusOK, user)
+//	}
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MaxBodyBytes is the largest request body Decode accepts.
+const MaxBodyBytes = 1 << 20
+
+// HandlerFunc is an http.Handler that reports failures by returning them.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteError(w, r, err)
+	}
+}
+
+// Encode writes v as a JSON response with the given status.
+func Encode(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode reads a single JSON value from the request body into v and then
+// validates it, see Validate. It accepts at most MaxBodyBytes and rejects
+// unknown fields.
+func Decode(w http.ResponseWriter, r *http.Request, v any) error {
+	return DecodeWithLimit(w, r, v, MaxBodyBytes)
+}
+
+// DecodeWithLimit is Decode with a custom body size limit in bytes.
+func DecodeWithLimit(w http.ResponseWriter, r *http.Request, v any, maxBytes int64) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || mediaType != "application/json" {
+			return Errorf(http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		}
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err, maxBytes)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err, maxBytes)
+		}
+		return BadRequest("request body must contain a single JSON value")
+	}
+
+	return Validate(v)
+}
+
+func decodeError(err error, maxBytes int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return BadRequest("request body must not be empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return BadRequest("request body contains malformed JSON")
+	case errors.As(err, &syntaxErr):
+		return BadRequest("request body contains malformed JSON at position %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return BadRequest("field %q must be of type %s", typeErr.Field, typeErr.Type)
+		}
+		return BadRequest("request body must be of type %s", typeErr.Type)
+	case errors.As(err, &maxBytesErr):
+		return Errorf(http.StatusRequestEntityTooLarge, "request body must not be larger than %d bytes", maxBytes)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return BadRequest("request body contains unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return fmt.Errorf("failed to decode request body: %w", err)
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateHTTPXErrorsContent() string {
+	return `package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// Error is an error with the HTTP status it should be answered with. Detail
+// is shown to the client, Err is only logged.
+type Error struct {
+	Status int
+	Detail string
+	Fields []FieldError
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Detail, e.Err)
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// FieldError describes why a single request field is invalid.
+type FieldError struct {
+	Field   string ` + "`" + `json:"field"` + "`" + `
+	Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+// Errorf returns an *Error with the given status and a formatted detail.
+func Errorf(status int, format string, args ...any) *Error {
+	return &Error{Status: status, Detail: fmt.Sprintf(format, args...)}
+}
+
+func BadRequest(format string, args ...any) *Error {
+	return Errorf(http.StatusBadRequest, format, args...)
+}
+
+func Unauthorized(format string, args ...any) *Error {
+	return Errorf(http.StatusUnauthorized, format, args...)
+}
+
+func Forbidden(format string, args ...any) *Error {
+	return Errorf(http.StatusForbidden, format, args...)
+}
+
+func NotFound(format string, args ...any) *Error {
+	return Errorf(http.StatusNotFound, format, args...)
+}
+
+func Conflict(format string, args ...any) *Error {
+	return Errorf(http.StatusConflict, format, args...)
+}
+
+// Problem is an RFC 7807 problem details object. Errors is an extension
+// member listing invalid fields.
+type Problem struct {
+	Type     string       ` + "`" + `json:"type"` + "`" + `
+	Title    string       ` + "`" + `json:"title"` + "`" + `
+	Status   int          ` + "`" + `json:"status"` + "`" + `
+	Detail   string       ` + "`" + `json:"detail,omitempty"` + "`" + `
+	Instance string       ` + "`" + `json:"instance,omitempty"` + "`" + `
+	Errors   []FieldError ` + "`" + `json:"errors,omitempty"` + "`" + `
+}
+
+// StatusOf returns the status err should be answered with.
+func StatusOf(err error) int {
+	var appErr *Error
+	switch {
+	case errors.As(err, &appErr):
+		return appErr.Status
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteError answers r with err as application/problem+json. Server errors
+// are logged and their message is replaced by the status text.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status := StatusOf(err)
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: r.URL.Path,
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		problem.Detail = appErr.Detail
+		problem.Errors = appErr.Fields
+	}
+
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"error", err,
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// NotFoundHandler answers every request with a 404 problem.
+func NotFoundHandler() http.Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return NotFound("no route for %s", r.URL.Path)
+	})
+}
+
+// MethodNotAllowedHandler answers every request with a 405 problem. It leaves
+// the Allow header to the caller, httprouter sets it before calling it.
+func MethodNotAllowedHandler() http.Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return Errorf(http.StatusMethodNotAllowed, "method %s is not allowed on %s", r.Method, r.URL.Path)
+	})
+}
+`
+}
+
+func (pg *ProjectGenerator) generateHTTPXValidateContent() string {
+	return `package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON name, the one the client sent.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		default:
+			return name
+		}
+	})
+
+	return v
+}
+
+// Validate checks the validate struct tags of v, see
+// https://pkg.go.dev/github.com/go-playground/validator/v10 for the tags.
+// Invalid fields are reported in a 422 *Error. Values that are not structs
+// are not validated.
+func Validate(v any) error {
+	if reflect.Indirect(reflect.ValueOf(v)).Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := validate.Struct(v)
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		// The namespace starts with the name of the struct type.
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		fields = append(fields, FieldError{Field: field, Message: fieldMessage(fe)})
+	}
+
+	return &Error{
+		Status: http.StatusUnprocessableEntity,
+		Detail: "request validation failed",
+		Fields: fields,
+	}
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	case "len":
+		return fmt.Sprintf("must be exactly %s%s", fe.Param(), unit)
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	default:
+		return fmt.Sprintf("failed the %q validation", fe.Tag())
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateHTTPXTestContent() string {
+	return `package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type signup struct {
+	Email string   ` + "`" + `json:"email" validate:"required,email"` + "`" + `
+	Name  string   ` + "`" + `json:"name" validate:"required,max=5"` + "`" + `
+	Tags  []string ` + "`" + `json:"tags" validate:"max=2"` + "`" + `
+	Plan  string   ` + "`" + `json:"plan,omitempty" validate:"omitempty,oneof=free pro"` + "`" + `
+}
+
+// serve runs h and decodes the problem it answers with.
+func serve(t *testing.T, h HandlerFunc, r *http.Request) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	var problem Problem
+	if rec.Code >= http.StatusBadRequest {
+		if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("Content-Type = %q, want application/problem+json", ct)
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+			t.Fatalf("failed to decode problem: %v", err)
+		}
+	}
+	return rec, problem
+}
+
+func decodeSignup(w http.ResponseWriter, r *http.Request) error {
+	var req signup
+	if err := DecodeWithLimit(w, r, &req, 128); err != nil {
+		return err
+	}
+	return Encode(w, http.StatusCreated, req)
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantDetail  string
+	}{
+		{"valid", "application/json", ` + "`" + `{"email":"a@example.com","name":"Ada"}` + "`" + `, http.StatusCreated, ""},
+		{"charset", "application/json; charset=utf-8", ` + "`" + `{"email":"a@example.com","name":"Ada"}` + "`" + `, http.StatusCreated, ""},
+		{"empty", "application/json", "", http.StatusBadRequest, "request body must not be empty"},
+		{"malformed", "application/json", ` + "`" + `{"email":}` + "`" + `, http.StatusBadRequest, "request body contains malformed JSON at position 10"},
+		{"truncated", "application/json", ` + "`" + `{"email":"a@example.com"` + "`" + `, http.StatusBadRequest, "request body contains malformed JSON"},
+		{"wrong type", "application/json", ` + "`" + `{"name":42}` + "`" + `, http.StatusBadRequest, ` + "`" + `field "name" must be of type string` + "`" + `},
+		{"unknown field", "application/json", ` + "`" + `{"admin":true}` + "`" + `, http.StatusBadRequest, ` + "`" + `request body contains unknown field "admin"` + "`" + `},
+		{"two values", "application/json", ` + "`" + `{"email":"a@example.com","name":"Ada"}{}` + "`" + `, http.StatusBadRequest, "request body must contain a single JSON value"},
+		{"too large", "application/json", ` + "`" + `{"name":"` + "`" + ` + strings.Repeat("a", 200) + ` + "`" + `"}` + "`" + `, http.StatusRequestEntityTooLarge, "request body must not be larger than 128 bytes"},
+		{"not json", "text/plain", "hello", http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			rec, problem := serve(t, decodeSignup, r)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if problem.Detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", problem.Detail, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestDecodeValidates(t *testing.T) {
+	body := ` + "`" + `{"email":"not-an-email","tags":["a","b","c"],"plan":"gold"}` + "`" + `
+	r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(body))
+
+	rec, problem := serve(t, decodeSignup, r)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", rec.Code)
+	}
+
+	want := []FieldError{
+		{Field: "email", Message: "must be a valid email address"},
+		{Field: "name", Message: "is required"},
+		{Field: "tags", Message: "must be at most 2 items"},
+		{Field: "plan", Message: "must be one of: free, pro"},
+	}
+	if len(problem.Errors) != len(want) {
+		t.Fatalf("errors = %+v, want %+v", problem.Errors, want)
+	}
+	for i := range want {
+		if problem.Errors[i] != want[i] {
+			t.Errorf("errors[%d] = %+v, want %+v", i, problem.Errors[i], want[i])
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantDetail string
+	}{
+		{"app error", NotFound("user %d does not exist", 7), http.StatusNotFound, "user 7 does not exist"},
+		{"wrapped", errors.Join(errors.New("lookup"), Conflict("email taken")), http.StatusConflict, "email taken"},
+		{"cause is hidden", &Error{Status: http.StatusBadGateway, Detail: "billing is unavailable", Err: errors.New("dial tcp: refused")}, http.StatusBadGateway, "billing is unavailable"},
+		{"internal", errors.New("pq: password authentication failed"), http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := HandlerFunc(func(http.ResponseWriter, *http.Request) error { return tt.err })
+
+			rec, problem := serve(t, h, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			want := Problem{
+				Type:     "about:blank",
+				Title:    http.StatusText(tt.wantStatus),
+				Status:   tt.wantStatus,
+				Detail:   tt.wantDetail,
+				Instance: "/users/7",
+			}
+			if problem.Type != want.Type || problem.Title != want.Title || problem.Status != want.Status ||
+				problem.Detail != want.Detail || problem.Instance != want.Instance {
+				t.Errorf("problem = %+v, want %+v", problem, want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := Encode(rec, http.StatusAccepted, map[string]string{"status": "queued"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want 202", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != ` + "`" + `{"status":"queued"}` + "`" + ` {
+		t.Errorf("body = %s", got)
+	}
+}
+`
+}
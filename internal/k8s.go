@@ -0,0 +1,433 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	k8sDir  = "k8s"
+	helmDir = "helm"
+)
+
+// placeholderSecretKey is the key of the API Secret template when
+// .env.example has no secret-looking keys.
+const placeholderSecretKey = "APP_SECRET"
+
+type K8sConfig struct {
+	ProjectName string
+	UseHelm     bool
+	Components  []K8sComponent
+}
+
+// K8sComponent is a single deployable service, mirroring a service in the
+// generated docker-compose.yml.
+type K8sComponent struct {
+	Name          string
+	Port          int
+	LivenessPath  string
+	ReadinessPath string
+	IngressPath   string
+	Config        map[string]string
+	Secrets       []string
+}
+
+// NewK8sConfig inspects the project in rootDir and returns one component for
+// the API and, for web projects, one for the frontend.
+func NewK8sConfig(rootDir, projectName string, useHelm bool) (*K8sConfig, error) {
+	config := &K8sConfig{
+		ProjectName: dnsName(filepath.Base(projectName)),
+		UseHelm:     useHelm,
+	}
+
+	apiDir := rootDir
+	isWeb := dirExists(filepath.Join(rootDir, constants.APIDir))
+	if isWeb {
+		apiDir = filepath.Join(rootDir, constants.APIDir)
+	}
+
+	if _, err := os.Stat(filepath.Join(apiDir, "go.mod")); err != nil {
+		return nil, fmt.Errorf("no go.mod found in %s - please run this command in a gogen project directory", apiDir)
+	}
+
+	env, err := readEnvExample(filepath.Join(apiDir, ".env.example"))
+	if err != nil {
+		return nil, err
+	}
+
+	api := K8sComponent{
+		Name:          constants.APIDir,
+		Port:          DefaultAPIPort,
+		LivenessPath:  LivenessPath,
+		ReadinessPath: ReadinessPath,
+		IngressPath:   "/",
+		Config:        map[string]string{},
+	}
+	// APIs generated before internal/health existed only serve /health.
+	if !dirExists(filepath.Join(apiDir, "internal", "health")) {
+		api.LivenessPath = "/health"
+		api.ReadinessPath = "/health"
+	}
+	if port, err := strconv.Atoi(env["PORT"]); err == nil {
+		api.Port = port
+	}
+	for _, key := range sortedKeys(env) {
+		if isSecretKey(key) {
+			api.Secrets = append(api.Secrets, key)
+		} else {
+			api.Config[key] = env[key]
+		}
+	}
+	// The Secret template is written even when .env.example has nothing
+	// secret yet, with a placeholder key to replace.
+	if len(api.Secrets) == 0 {
+		api.Secrets = []string{placeholderSecretKey}
+	}
+
+	if isWeb && dirExists(filepath.Join(rootDir, constants.FrontendDir)) {
+		api.IngressPath = "/api"
+		config.Components = append(config.Components, api, K8sComponent{
+			Name:          constants.FrontendDir,
+			Port:          frontendContainerPort,
+			LivenessPath:  "/",
+			ReadinessPath: "/",
+			IngressPath:   "/",
+		})
+	} else {
+		config.Components = append(config.Components, api)
+	}
+
+	return config, nil
+}
+
+func (pg *ProjectGenerator) CreateK8sManifests(dirName string, config *K8sConfig) error {
+	if dirExists(filepath.Join(dirName, k8sDir)) {
+		return fmt.Errorf("%s already exists in %s", k8sDir, dirName)
+	}
+	chartDir := filepath.Join(helmDir, config.ProjectName)
+	if config.UseHelm && dirExists(filepath.Join(dirName, chartDir)) {
+		return fmt.Errorf("%s already exists in %s", chartDir, dirName)
+	}
+
+	var resources []string
+
+	for _, component := range config.Components {
+		componentDir := filepath.Join(dirName, k8sDir, component.Name)
+		if err := os.MkdirAll(componentDir, 0750); err != nil {
+			return fmt.Errorf("failed to create %s directory: %w", componentDir, err)
+		}
+
+		files := map[string]string{
+			"deployment.yaml": config.generateDeployment(component),
+			"service.yaml":    config.generateService(component),
+		}
+		if len(component.Config) > 0 {
+			files["configmap.yaml"] = config.generateConfigMap(component)
+		}
+		if len(component.Secrets) > 0 {
+			// The template is kept out of kustomization.yaml, real values
+			// should be created with kubectl or a secrets manager.
+			files["secret.example.yaml"] = config.generateSecret(component)
+		}
+
+		for _, fileName := range sortedKeys(files) {
+			if err := os.WriteFile(filepath.Join(componentDir, fileName), []byte(files[fileName]), 0600); err != nil {
+				return fmt.Errorf("failed to create %s: %w", fileName, err)
+			}
+			if fileName != "secret.example.yaml" {
+				resources = append(resources, component.Name+"/"+fileName)
+			}
+		}
+	}
+
+	resources = append(resources, "ingress.yaml")
+	if err := os.WriteFile(filepath.Join(dirName, k8sDir, "ingress.yaml"), []byte(config.generateIngress()), 0600); err != nil {
+		return fmt.Errorf("failed to create ingress.yaml: %w", err)
+	}
+
+	kustomization := "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\n\nresources:\n"
+	for _, resource := range resources {
+		kustomization += "  - " + resource + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(dirName, k8sDir, "kustomization.yaml"), []byte(kustomization), 0600); err != nil {
+		return fmt.Errorf("failed to create kustomization.yaml: %w", err)
+	}
+
+	if config.UseHelm {
+		if err := pg.createHelmChart(dirName, config); err != nil {
+			return fmt.Errorf("failed to create helm chart: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (kc *K8sConfig) resourceName(component K8sComponent) string {
+	return dnsName(kc.ProjectName + "-" + component.Name)
+}
+
+func (kc *K8sConfig) generateDeployment(component K8sComponent) string {
+	name := kc.resourceName(component)
+
+	envFrom := ""
+	if len(component.Config) > 0 || len(component.Secrets) > 0 {
+		envFrom = "          envFrom:\n"
+		if len(component.Config) > 0 {
+			envFrom += "            - configMapRef:\n                name: " + name + "\n"
+		}
+		if len(component.Secrets) > 0 {
+			envFrom += "            - secretRef:\n                name: " + name + "\n                optional: true\n"
+		}
+	}
+
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s
+  labels:
+    app.kubernetes.io/name: %[1]s
+    app.kubernetes.io/part-of: %[2]s
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: %[1]s
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: %[1]s
+        app.kubernetes.io/part-of: %[2]s
+    spec:
+      containers:
+        - name: %[3]s
+          image: %[1]s:latest
+          imagePullPolicy: IfNotPresent
+          ports:
+            - name: http
+              containerPort: %[4]d
+%[5]s          livenessProbe:
+            httpGet:
+              path: %[6]s
+              port: http
+            initialDelaySeconds: 10
+            periodSeconds: 15
+          readinessProbe:
+            httpGet:
+              path: %[7]s
+              port: http
+            initialDelaySeconds: 5
+            periodSeconds: 10
+          resources:
+            requests:
+              cpu: 100m
+              memory: 64Mi
+            limits:
+              cpu: 500m
+              memory: 256Mi
+`, name, kc.ProjectName, component.Name, component.Port, envFrom, component.LivenessPath, component.ReadinessPath)
+}
+
+func (kc *K8sConfig) generateService(component K8sComponent) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s
+  labels:
+    app.kubernetes.io/name: %[1]s
+    app.kubernetes.io/part-of: %[2]s
+spec:
+  type: ClusterIP
+  selector:
+    app.kubernetes.io/name: %[1]s
+  ports:
+    - name: http
+      port: %[3]d
+      targetPort: http
+`, kc.resourceName(component), kc.ProjectName, component.Port)
+}
+
+func (kc *K8sConfig) generateConfigMap(component K8sComponent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+data:
+`, kc.resourceName(component))
+	for _, key := range sortedKeys(component.Config) {
+		fmt.Fprintf(&b, "  %s: %q\n", key, component.Config[key])
+	}
+	return b.String()
+}
+
+func (kc *K8sConfig) generateSecret(component K8sComponent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `# Template only, fill in the values and apply it separately:
+#   kubectl apply -f %s/%s/secret.example.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: %s
+type: Opaque
+stringData:
+`, k8sDir, component.Name, kc.resourceName(component))
+	for _, key := range component.Secrets {
+		if key == placeholderSecretKey {
+			b.WriteString("  # Replace with the secret environment variables of the " + component.Name + ".\n")
+		}
+		fmt.Fprintf(&b, "  %s: \"change-me\"\n", key)
+	}
+	return b.String()
+}
+
+// ingressRewriteAnnotations make ingress-nginx strip the prefix matched by
+// the path of ingressPath, /api/greetings reaching the API as /greetings.
+const ingressRewriteAnnotations = `    nginx.ingress.kubernetes.io/use-regex: "true"
+    nginx.ingress.kubernetes.io/rewrite-target: /$2
+`
+
+// ingressGroups splits the components into the ones served at the root of
+// the host and the ones served under a prefix such as /api. The API does not
+// serve that prefix itself, and ingress-nginx applies a rewrite to every path
+// of an Ingress, so each prefixed component gets an Ingress of its own.
+func (kc *K8sConfig) ingressGroups() (root, prefixed []K8sComponent) {
+	for _, component := range kc.Components {
+		if component.IngressPath == "/" {
+			root = append(root, component)
+		} else {
+			prefixed = append(prefixed, component)
+		}
+	}
+	return root, prefixed
+}
+
+// ingressPath returns the path and path type of component in its Ingress.
+func ingressPath(component K8sComponent) (path, pathType string) {
+	if component.IngressPath == "/" {
+		return "/", "Prefix"
+	}
+	return strings.TrimSuffix(component.IngressPath, "/") + "(/|$)(.*)", "ImplementationSpecific"
+}
+
+func (kc *K8sConfig) generateIngress() string {
+	root, prefixed := kc.ingressGroups()
+
+	var docs []string
+	if len(root) > 0 {
+		docs = append(docs, kc.generateIngressResource(kc.ProjectName, "", root))
+	}
+	for _, component := range prefixed {
+		docs = append(docs, kc.generateIngressResource(kc.resourceName(component), ingressRewriteAnnotations, []K8sComponent{component}))
+	}
+
+	return strings.Join(docs, "---\n")
+}
+
+func (kc *K8sConfig) generateIngressResource(name, annotations string, components []K8sComponent) string {
+	var paths strings.Builder
+	for _, component := range components {
+		path, pathType := ingressPath(component)
+		fmt.Fprintf(&paths, `          - path: %s
+            pathType: %s
+            backend:
+              service:
+                name: %s
+                port:
+                  name: http
+`, path, pathType, kc.resourceName(component))
+	}
+
+	if annotations != "" {
+		annotations = "  annotations:\n" + annotations
+	}
+
+	return fmt.Sprintf(`apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: %[1]s
+  labels:
+    app.kubernetes.io/part-of: %[2]s
+%[3]sspec:
+  ingressClassName: nginx
+  rules:
+    - host: %[2]s.local
+      http:
+        paths:
+%[4]s`, name, kc.ProjectName, annotations, paths.String())
+}
+
+func readEnvExample(path string) (map[string]string, error) {
+	env := map[string]string{}
+
+	file, err := os.Open(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return env, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Printf("Warning: failed to close %s: %v\n", path, err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		env[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+
+	return env, scanner.Err()
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToUpper(key)
+	for _, marker := range []string{"SECRET", "PASSWORD", "TOKEN", "KEY", "DSN", "DATABASE_URL"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// dnsName turns name into an RFC 1123 label, which Kubernetes requires of
+// resource names and Helm of chart names: lowercase letters, digits and '-',
+// starting and ending with a letter or digit, at most 63 characters.
+func dnsName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, name)
+
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	name = strings.Trim(name, "-")
+	if name == "" {
+		return "app"
+	}
+	return name
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
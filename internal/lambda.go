@@ -0,0 +1,698 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	LambdaConfigSAM        = "sam"
+	LambdaConfigServerless = "serverless"
+)
+
+func IsLambdaConfig(config string) bool {
+	return config == LambdaConfigSAM || config == LambdaConfigServerless
+}
+
+// lambdaFixtures are the API Gateway events written to events/, by file name
+// and request path.
+var lambdaFixtures = map[string]string{
+	"get-root.json":   "/",
+	"get-livez.json":  LivenessPath,
+	"get-readyz.json": ReadinessPath,
+}
+
+// CreateLambdaProject writes an HTTP API that runs on AWS Lambda. The routes
+// come from the same RouterGenerator as the api template, main.go serves them
+// through internal/adapter when started by the Lambda runtime and over plain
+// HTTP everywhere else. cmd/invoke runs them against the event fixtures in
+// events/, so nothing needs AWS until deploying.
+func (pg *ProjectGenerator) CreateLambdaProject(config *WebProjectConfig) error {
+	moduleName := config.ModuleName
+	if moduleName == "" {
+		moduleName = config.ProjectName
+	}
+
+	var routesContent string
+	switch config.Router {
+	case "chi":
+		routesContent = pg.RouterGenerator.generateChiContent(moduleName)
+	case "gorilla":
+		routesContent = pg.RouterGenerator.generateGorillaContent(moduleName)
+	case "httprouter":
+		routesContent = pg.RouterGenerator.generateHttpRouterContent(moduleName)
+	default:
+		routesContent = pg.RouterGenerator.generateStdlibContent(moduleName)
+	}
+
+	adapterDir := filepath.Join("internal", "adapter")
+	functionDir := filepath.Join("internal", "function")
+	env := fmt.Sprintf("PORT=%d\n", DefaultAPIPort)
+	files := map[string]string{
+		"main.go":      pg.generateLambdaMainContent(moduleName),
+		"Makefile":     pg.generateLambdaMakefile(config.LambdaConfig),
+		".env":         env,
+		".env.example": env,
+
+		filepath.Join("cmd", "web", "routes.go"):       routesContent,
+		filepath.Join("cmd", "invoke", "main.go"):      pg.generateLambdaInvokeContent(moduleName),
+		filepath.Join(adapterDir, "adapter.go"):        pg.generateLambdaAdapterContent(),
+		filepath.Join(adapterDir, "adapter_test.go"):   pg.generateLambdaAdapterTestContent(),
+		filepath.Join(functionDir, "function.go"):      pg.generateLambdaFunctionContent(moduleName, config.Router),
+		filepath.Join(functionDir, "function_test.go"): pg.generateLambdaFunctionTestContent(moduleName),
+	}
+	for name, path := range lambdaFixtures {
+		files[filepath.Join("events", name)] = generateLambdaEventContent(path)
+	}
+
+	if config.LambdaConfig == LambdaConfigServerless {
+		files["serverless.yml"] = pg.generateServerlessConfig(config.ProjectName)
+	} else {
+		files["template.yaml"] = pg.generateSAMTemplate(config.ProjectName)
+	}
+
+	if err := writeProjectFiles(".", files); err != nil {
+		return err
+	}
+
+	if err := pg.createHealthFiles("."); err != nil {
+		return err
+	}
+
+	if err := pg.createHTTPXFiles("."); err != nil {
+		return err
+	}
+
+	if err := pg.CreateAirFile(".", constants.LambdaTemplate); err != nil {
+		fmt.Printf("Warning: failed to create .air.toml file: %v\n", err)
+	}
+
+	if err := pg.CreateGitignoreFile(constants.LambdaTemplate, "."); err != nil {
+		fmt.Printf("Warning: failed to create .gitignore file: %v\n", err)
+	}
+
+	cmd := exec.Command("go", "mod", "tidy")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	}
+
+	if err := pg.InitGitRepository(config.ProjectName, constants.LambdaTemplate); err != nil {
+		fmt.Printf("Warning: failed to initialize git repository: %v\n", err)
+	}
+
+	return nil
+}
+
+func (pg *ProjectGenerator) generateLambdaMainContent(moduleName string) string {
+	return `package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/joho/godotenv"
+
+	"` + moduleName + `/internal/adapter"
+	"` + moduleName + `/internal/function"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+func main() {
+	handler := function.Handler()
+
+	// The Lambda runtime sets AWS_LAMBDA_RUNTIME_API. Anywhere else, locally
+	// or on container based FaaS platforms, the routes are served over HTTP.
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		lambda.Start(adapter.Handler(handler))
+		return
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, reading configuration from the environment")
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	port = ":" + port
+
+	fmt.Printf("Starting server %s on http://localhost%s\n", version, port)
+	log.Fatal(http.ListenAndServe(port, handler))
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLambdaFunctionContent(moduleName, router string) string {
+	build := "handler = web.SetupRoutes()"
+	if router != "chi" && router != "gorilla" && router != "httprouter" {
+		build = `web.SetupRoutes()
+		handler = http.DefaultServeMux`
+	}
+
+	return `// Package function builds the handler main.go serves, shared with
+// cmd/invoke and the tests so they exercise the deployed routes.
+package function
+
+import (
+	"net/http"
+	"sync"
+
+	"` + moduleName + `/cmd/web"
+)
+
+var (
+	once    sync.Once
+	handler http.Handler
+)
+
+// Handler returns the routes from cmd/web. Routes are set up on the first
+// call only, so it is safe to call from several places.
+func Handler() http.Handler {
+	once.Do(func() {
+		` + build + `
+	})
+	return handler
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLambdaFunctionTestContent(moduleName string) string {
+	return `package function_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"` + moduleName + `/internal/adapter"
+	"` + moduleName + `/internal/function"
+)
+
+// TestFixtures runs every event in events/ through the Lambda handler, the
+// same way cmd/invoke and sam local invoke do.
+func TestFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "events", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no event fixtures found in events/")
+	}
+
+	handler := adapter.Handler(function.Handler())
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var event events.APIGatewayV2HTTPRequest
+			if err := json.Unmarshal(data, &event); err != nil {
+				t.Fatalf("invalid fixture: %v", err)
+			}
+
+			res, err := handler(context.Background(), event)
+			if err != nil {
+				t.Fatalf("handler returned an error: %v", err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want %d, body: %s", res.StatusCode, http.StatusOK, res.Body)
+			}
+		})
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLambdaInvokeContent(moduleName string) string {
+	return `// Command invoke runs the Lambda handler against API Gateway event
+// fixtures and prints the responses, without AWS or Docker:
+//
+//	go run ./cmd/invoke events/get-readyz.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"` + moduleName + `/internal/adapter"
+	"` + moduleName + `/internal/function"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: invoke <event.json>...")
+		os.Exit(2)
+	}
+
+	handler := adapter.Handler(function.Handler())
+	for _, path := range os.Args[1:] {
+		if err := invoke(handler, path); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func invoke(handler adapter.LambdaHandler, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var event events.APIGatewayV2HTTPRequest
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("invalid event: %w", err)
+	}
+
+	res, err := handler(context.Background(), event)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n%s\n", path, out)
+	return nil
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLambdaAdapterContent() string {
+	return `// Package adapter serves an http.Handler on AWS Lambda. It converts API
+// Gateway HTTP API events (payload format 2.0, also sent by Lambda function
+// URLs) to requests, and the handler's responses back to events.
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// LambdaHandler is the function passed to lambda.Start.
+type LambdaHandler func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)
+
+// Handler returns a LambdaHandler that serves every event with h.
+func Handler(h http.Handler) LambdaHandler {
+	return func(ctx context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		req, err := NewRequest(ctx, event)
+		if err != nil {
+			return events.APIGatewayV2HTTPResponse{}, err
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return NewResponse(rec.Result())
+	}
+}
+
+// NewRequest converts an API Gateway event to an http.Request.
+func NewRequest(ctx context.Context, event events.APIGatewayV2HTTPRequest) (*http.Request, error) {
+	body := []byte(event.Body)
+	if event.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(event.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode request body: %w", err)
+		}
+		body = decoded
+	}
+
+	target := event.RawPath
+	if target == "" {
+		target = "/"
+	}
+	if event.RawQueryString != "" {
+		target += "?" + event.RawQueryString
+	}
+
+	req, err := http.NewRequestWithContext(ctx, event.RequestContext.HTTP.Method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Payload format 2.0 joins repeated headers with commas and sends
+	// cookies separately.
+	for name, value := range event.Headers {
+		req.Header.Set(name, value)
+	}
+	for _, cookie := range event.Cookies {
+		req.Header.Add("Cookie", cookie)
+	}
+
+	req.Host = event.RequestContext.DomainName
+	req.RemoteAddr = event.RequestContext.HTTP.SourceIP
+	req.RequestURI = target
+	return req, nil
+}
+
+// NewResponse converts a response to an API Gateway response. Bodies that are
+// not text are base64 encoded.
+func NewResponse(res *http.Response) (events.APIGatewayV2HTTPResponse, error) {
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	out := events.APIGatewayV2HTTPResponse{
+		StatusCode: res.StatusCode,
+		Headers:    make(map[string]string, len(res.Header)),
+	}
+	for name, values := range res.Header {
+		if name == "Set-Cookie" {
+			out.Cookies = values
+			continue
+		}
+		out.Headers[name] = strings.Join(values, ",")
+	}
+
+	// Like net/http, sniff the content type of handlers that do not set one.
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" && len(body) > 0 {
+		contentType = http.DetectContentType(body)
+		out.Headers["Content-Type"] = contentType
+	}
+
+	if len(body) == 0 || isText(contentType) {
+		out.Body = string(body)
+	} else {
+		out.Body = base64.StdEncoding.EncodeToString(body)
+		out.IsBase64Encoded = true
+	}
+	return out, nil
+}
+
+func isText(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, suffix := range []string{"json", "xml", "javascript", "x-www-form-urlencoded"} {
+		if strings.HasSuffix(mediaType, suffix) {
+			return true
+		}
+	}
+	return false
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLambdaAdapterTestContent() string {
+	return `package adapter
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandlerRequest(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	event := events.APIGatewayV2HTTPRequest{
+		RawPath:         "/items",
+		RawQueryString:  "page=2",
+		Headers:         map[string]string{"content-type": "application/octet-stream", "x-request-id": "abc"},
+		Cookies:         []string{"session=1"},
+		Body:            base64.StdEncoding.EncodeToString([]byte{0x00, 0xff}),
+		IsBase64Encoded: true,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			DomainName: "api.example.com",
+			HTTP:       events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodPost, SourceIP: "203.0.113.1"},
+		},
+	}
+
+	res, err := handler(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want %d", res.StatusCode, http.StatusCreated)
+	}
+
+	if got.Method != http.MethodPost || got.URL.Path != "/items" || got.URL.Query().Get("page") != "2" {
+		t.Errorf("request = %s %s, want POST /items?page=2", got.Method, got.URL)
+	}
+	if got.Host != "api.example.com" {
+		t.Errorf("host = %q, want %q", got.Host, "api.example.com")
+	}
+	if got.Header.Get("X-Request-Id") != "abc" {
+		t.Errorf("X-Request-Id = %q, want %q", got.Header.Get("X-Request-Id"), "abc")
+	}
+	if cookie, err := got.Cookie("session"); err != nil || cookie.Value != "1" {
+		t.Errorf("session cookie = %v, %v", cookie, err)
+	}
+	if string(gotBody) != "\x00\xff" {
+		t.Errorf("body = %q, want the decoded bytes", gotBody)
+	}
+}
+
+func TestHandlerResponse(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		body            []byte
+		wantBody        string
+		wantBase64      bool
+		wantContentType string
+	}{
+		{name: "json", contentType: "application/json", body: []byte(` + "`" + `{"ok":true}` + "`" + `), wantBody: ` + "`" + `{"ok":true}` + "`" + `},
+		{name: "text", contentType: "text/plain; charset=utf-8", body: []byte("OK"), wantBody: "OK"},
+		{name: "binary", contentType: "image/png", body: []byte{0x89, 0x50}, wantBody: base64.StdEncoding.EncodeToString([]byte{0x89, 0x50}), wantBase64: true},
+		{name: "sniffed", body: []byte("OK"), wantBody: "OK", wantContentType: "text/plain; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+				// Writing the header first keeps the recorder from setting
+				// a content type itself.
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(tt.body)
+			}))
+
+			res, err := handler(context.Background(), events.APIGatewayV2HTTPRequest{
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodGet},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Body != tt.wantBody || res.IsBase64Encoded != tt.wantBase64 {
+				t.Errorf("body = %q (base64 %t), want %q (base64 %t)", res.Body, res.IsBase64Encoded, tt.wantBody, tt.wantBase64)
+			}
+			wantContentType := tt.wantContentType
+			if wantContentType == "" {
+				wantContentType = tt.contentType
+			}
+			if res.Headers["Content-Type"] != wantContentType {
+				t.Errorf("Content-Type = %q, want %q", res.Headers["Content-Type"], wantContentType)
+			}
+			if len(res.Cookies) != 1 || res.Cookies[0] != "session=1" {
+				t.Errorf("cookies = %v, want [session=1]", res.Cookies)
+			}
+		})
+	}
+}
+`
+}
+
+// generateLambdaEventContent returns an API Gateway HTTP API GET event for
+// path, in the format sam local invoke -e also accepts.
+func generateLambdaEventContent(path string) string {
+	return `{
+  "version": "2.0",
+  "routeKey": "$default",
+  "rawPath": "` + path + `",
+  "rawQueryString": "",
+  "headers": {
+    "accept": "*/*",
+    "host": "localhost",
+    "user-agent": "curl/8.5.0"
+  },
+  "requestContext": {
+    "accountId": "123456789012",
+    "apiId": "local",
+    "domainName": "localhost",
+    "domainPrefix": "localhost",
+    "http": {
+      "method": "GET",
+      "path": "` + path + `",
+      "protocol": "HTTP/1.1",
+      "sourceIp": "127.0.0.1",
+      "userAgent": "curl/8.5.0"
+    },
+    "requestId": "local",
+    "routeKey": "$default",
+    "stage": "$default",
+    "time": "01/Jan/2025:00:00:00 +0000",
+    "timeEpoch": 1735689600000
+  },
+  "isBase64Encoded": false
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLambdaMakefile(lambdaConfig string) string {
+	var b strings.Builder
+	b.WriteString(`# The bootstrap binary runs on the provided.al2023 runtime. LAMBDA_ARCH must
+# match the architecture in the deployment config.
+LAMBDA_ARCH ?= arm64
+
+.PHONY: run test invoke bootstrap zip deploy`)
+	if lambdaConfig != LambdaConfigServerless {
+		b.WriteString(" build-Function sam-invoke")
+	}
+	b.WriteString(`
+
+run:
+	go run .
+
+test:
+	go test ./...
+
+invoke:
+	go run ./cmd/invoke events/*.json
+
+bootstrap:
+	GOOS=linux GOARCH=$(LAMBDA_ARCH) CGO_ENABLED=0 go build -tags lambda.norpc -trimpath -ldflags="-s -w" -o bin/bootstrap .
+
+zip: bootstrap
+	cd bin && zip -q function.zip bootstrap
+`)
+
+	if lambdaConfig == LambdaConfigServerless {
+		b.WriteString(`
+deploy: zip
+	serverless deploy
+`)
+	} else {
+		b.WriteString(`
+# Called by sam build, template.yaml uses BuildMethod: makefile.
+build-Function: bootstrap
+	cp bin/bootstrap $(ARTIFACTS_DIR)/
+
+# Runs the function in the Lambda runtime image, requires the SAM CLI and Docker.
+sam-invoke:
+	sam build
+	sam local invoke Function -e events/get-readyz.json
+
+deploy:
+	sam build
+	sam deploy --guided
+`)
+	}
+
+	return b.String()
+}
+
+func (pg *ProjectGenerator) generateSAMTemplate(projectName string) string {
+	return `AWSTemplateFormatVersion: "2010-09-09"
+Transform: AWS::Serverless-2016-10-31
+Description: ` + projectName + `
+
+Globals:
+  Function:
+    Timeout: 10
+    MemorySize: 128
+
+Resources:
+  Function:
+    Type: AWS::Serverless::Function
+    Metadata:
+      BuildMethod: makefile
+    Properties:
+      CodeUri: .
+      Handler: bootstrap
+      Runtime: provided.al2023
+      Architectures:
+        - arm64
+      Events:
+        # Without a path and method the event is the API's $default route,
+        # routing happens in cmd/web.
+        Api:
+          Type: HttpApi
+
+Outputs:
+  ApiUrl:
+    Description: URL of the HTTP API
+    Value: !Sub "https://${ServerlessHttpApi}.execute-api.${AWS::Region}.${AWS::URLSuffix}/"
+`
+}
+
+func (pg *ProjectGenerator) generateServerlessConfig(projectName string) string {
+	return `service: ` + composeName(projectName) + `
+
+provider:
+  name: aws
+  runtime: provided.al2023
+  architecture: arm64
+  region: ${opt:region, 'us-east-1'}
+  timeout: 10
+  memorySize: 128
+
+package:
+  individually: true
+
+functions:
+  api:
+    handler: bootstrap
+    # Built by make zip.
+    package:
+      artifact: bin/function.zip
+    events:
+      # Routing happens in cmd/web.
+      - httpApi: "*"
+`
+}
@@ -0,0 +1,264 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// CreateLibProject writes a Go package meant to be imported rather than run:
+// a package with doc.go, example, benchmark and fuzz tests, a CHANGELOG and a
+// Makefile. There is no main.go and no Air configuration.
+func (pg *ProjectGenerator) CreateLibProject(projectName, moduleName string) error {
+	if moduleName == "" {
+		moduleName = projectName
+	}
+	pkg := libPackageName(moduleName)
+
+	files := map[string]string{
+		"doc.go":          pg.generateLibDocContent(pkg, moduleName),
+		pkg + ".go":       pg.generateLibContent(pkg),
+		pkg + "_test.go":  pg.generateLibTestContent(pkg),
+		"example_test.go": pg.generateLibExampleContent(pkg, moduleName),
+		"fuzz_test.go":    pg.generateLibFuzzContent(pkg),
+		"CHANGELOG.md":    pg.generateLibChangelogContent(),
+		"Makefile":        pg.generateLibMakefile(),
+	}
+	if err := writeProjectFiles(".", files); err != nil {
+		return err
+	}
+
+	if err := pg.CreateGitignoreFile(constants.LibTemplate, "."); err != nil {
+		fmt.Printf("Warning: failed to create .gitignore file: %v\n", err)
+	}
+
+	cmd := exec.Command("go", "mod", "tidy")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	}
+
+	if err := pg.InitGitRepository(projectName, constants.LibTemplate); err != nil {
+		fmt.Printf("Warning: failed to initialize git repository: %v\n", err)
+	}
+
+	return nil
+}
+
+// libPackageName derives the package name from the last element of the module
+// path that is not a major version suffix, keeping lowercase letters and
+// digits: github.com/acme/go-slug/v2 becomes goslug.
+func libPackageName(moduleName string) string {
+	base := path.Base(moduleName)
+	if majorVersionSuffix.MatchString(base) {
+		base = path.Base(path.Dir(moduleName))
+	}
+
+	name := strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, base)
+
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "lib" + name
+	}
+	return name
+}
+
+func (pg *ProjectGenerator) generateLibDocContent(pkg, moduleName string) string {
+	return `// Package ` + pkg + ` turns arbitrary text into URL-friendly slugs.
+//
+// Replace Slugify with the package's own API, keeping the doc comment, the
+// examples in example_test.go and the fuzz target in fuzz_test.go in step with
+// it: examples are rendered on pkg.go.dev and run by go test.
+//
+// Install it with:
+//
+//	go get ` + moduleName + `
+package ` + pkg + `
+`
+}
+
+func (pg *ProjectGenerator) generateLibContent(pkg string) string {
+	return `package ` + pkg + `
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify returns s as a slug: lowercase ASCII letters and digits, with every
+// other run of characters replaced by a single dash. Slugs never start or end
+// with a dash, and Slugify(Slugify(s)) == Slugify(s).
+func Slugify(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	dash := false
+	for _, r := range s {
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	return b.String()
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLibTestContent(pkg string) string {
+	return `package ` + pkg + `
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "empty", in: "", want: ""},
+		{name: "words", in: "Hello World", want: "hello-world"},
+		{name: "punctuation", in: "Go, the language!", want: "go-the-language"},
+		{name: "surrounding separators", in: "  --Go--  ", want: "go"},
+		{name: "digits", in: "Release 1.2.3", want: "release-1-2-3"},
+		{name: "non ASCII", in: "Crème brûlée", want: "cr-me-br-l-e"},
+		{name: "only separators", in: "--- ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.in); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkSlugify(b *testing.B) {
+	const title = "The Go Programming Language: Effective Go, Tips & Tricks (2nd edition)"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Slugify(title)
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLibExampleContent(pkg, moduleName string) string {
+	return `package ` + pkg + `_test
+
+import (
+	"fmt"
+
+	"` + moduleName + `"
+)
+
+func ExampleSlugify() {
+	fmt.Println(` + pkg + `.Slugify("Hello, World!"))
+	fmt.Println(` + pkg + `.Slugify("  Release 1.2.3  "))
+	// Output:
+	// hello-world
+	// release-1-2-3
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLibFuzzContent(pkg string) string {
+	return `package ` + pkg + `
+
+import (
+	"strings"
+	"testing"
+)
+
+// FuzzSlugify checks the properties documented on Slugify for arbitrary input.
+// go test runs the seed corpus, make fuzz generates new inputs.
+func FuzzSlugify(f *testing.F) {
+	for _, seed := range []string{"", "Hello World", "  --Go--  ", "Crème brûlée", "a\x00b"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		slug := Slugify(s)
+
+		for _, r := range slug {
+			if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' {
+				t.Fatalf("Slugify(%q) = %q contains %q", s, slug, r)
+			}
+		}
+		if strings.HasPrefix(slug, "-") || strings.HasSuffix(slug, "-") || strings.Contains(slug, "--") {
+			t.Fatalf("Slugify(%q) = %q has stray dashes", s, slug)
+		}
+		if again := Slugify(slug); again != slug {
+			t.Fatalf("Slugify is not idempotent: Slugify(%q) = %q", slug, again)
+		}
+	})
+}
+`
+}
+
+func (pg *ProjectGenerator) generateLibChangelogContent() string {
+	return `# Changelog
+
+All notable changes to this project are documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+Run ` + "`make apicompat`" + ` before tagging a release to check the version against the
+changes to the exported API.
+
+## [Unreleased]
+
+### Added
+
+- ` + "`Slugify`" + `
+`
+}
+
+func (pg *ProjectGenerator) generateLibMakefile() string {
+	return `# FUZZTIME bounds each make fuzz run, go test only runs the seed corpus.
+FUZZTIME ?= 30s
+
+.PHONY: test bench fuzz cover lint apicompat
+
+test:
+	go test -race ./...
+
+bench:
+	go test -run='^$$' -bench=. -benchmem ./...
+
+fuzz:
+	go test -run='^$$' -fuzz=FuzzSlugify -fuzztime=$(FUZZTIME) .
+
+cover:
+	go test -coverprofile=coverage.out ./...
+	go tool cover -func=coverage.out
+
+lint:
+	go vet ./...
+	golangci-lint run
+
+# Compares the exported API with the latest released version and suggests
+# the next version number.
+apicompat:
+	go run golang.org/x/exp/cmd/gorelease@latest
+`
+}
@@ -44,7 +44,7 @@ This is a Go project using:
 - Go modules for dependency management
 - Standard Go project structure
 - Web development with ` + router + ` router 
-- CLI applications using urfave/cli/v2`
+- CLI applications using urfave/cli/v2, cobra or the standard library flag package`
 
 	if frontendFramework != "" {
 		baseContent += fmt.Sprintf(`
@@ -71,6 +71,22 @@ This is a Go project using:
 			baseContent += `
 - Angular with TypeScript
 - Component-based architecture with dependency injection`
+		case preact:
+			baseContent += `
+- Preact with hooks and a React-compatible API
+- JSX/TSX for component templates`
+		case lit:
+			baseContent += `
+- Lit web components with reactive properties
+- Tagged template literals for component templates`
+		case qwik:
+			baseContent += `
+- Qwik with resumability and Qwik City routing
+- TSX components using component$ and signals`
+		case astro:
+			baseContent += `
+- Astro with the islands architecture
+- .astro components rendered on the server by default`
 		}
 	}
 
@@ -185,6 +201,38 @@ This is a Go project using:
 - Follow Angular style guide conventions
 - Use proper dependency injection patterns
 - Implement proper component lifecycle hooks`
+		case preact:
+			content += `
+
+### Preact-Specific Rules
+- Use functional components with hooks from preact/hooks
+- Prefer signals from @preact/signals for shared state
+- Avoid pulling in preact/compat unless a React library requires it
+- Keep bundle size small, it is the main reason to pick Preact`
+		case lit:
+			content += `
+
+### Lit-Specific Rules
+- Extend LitElement and declare reactive properties with decorators or static properties
+- Keep styles in static styles using the css tag
+- Dispatch custom events for child to parent communication
+- Remember that global styles do not cross the shadow DOM boundary`
+		case qwik:
+			content += `
+
+### Qwik-Specific Rules
+- Use component$ and the $ suffix for lazy-loadable boundaries
+- Use useSignal and useStore for state
+- Load data with routeLoader$ and mutate with routeAction$
+- Avoid capturing non-serializable values in closures`
+		case astro:
+			content += `
+
+### Astro-Specific Rules
+- Render on the server by default and ship zero JavaScript where possible
+- Use client:* directives only for interactive islands
+- Keep pages under src/pages and shared layouts under src/layouts
+- Use content collections for structured content`
 		}
 
 		if runtime == bun {
@@ -231,6 +279,13 @@ func (lt *LLMTemplate) generateVSCodeContent(frontendFramework, runtime, router
 		case angular:
 			baseSettings += `,
         "html": true`
+		case preact, qwik:
+			baseSettings += `,
+        "javascriptreact": true,
+        "typescriptreact": true`
+		case astro:
+			baseSettings += `,
+        "astro": true`
 		}
 	}
 
@@ -290,6 +345,15 @@ func (lt *LLMTemplate) generateVSCodeContent(frontendFramework, runtime, router
 			baseSettings += `,
     "typescript.preferences.includePackageJsonAutoImports": "on",
     "angular.enableCodeCompletion": true`
+		case preact, lit, qwik:
+			baseSettings += `,
+    "typescript.preferences.includePackageJsonAutoImports": "on",
+    "typescript.suggest.autoImports": true,
+    "javascript.suggest.autoImports": true`
+		case astro:
+			baseSettings += `,
+    "typescript.preferences.includePackageJsonAutoImports": "on",
+    "prettier.documentSelectors": ["**/*.astro"]`
 		}
 
 		if runtime == bun {
@@ -330,6 +394,14 @@ This is a Go project with the following characteristics:
 			baseContent += ` with fine-grained reactivity`
 		case angular:
 			baseContent += ` with TypeScript and dependency injection`
+		case preact:
+			baseContent += ` with hooks and signals`
+		case lit:
+			baseContent += ` with web components and reactive properties`
+		case qwik:
+			baseContent += ` with resumability and Qwik City`
+		case astro:
+			baseContent += ` with server rendering and interactive islands`
 		}
 
 		if runtime == bun {
@@ -434,6 +506,30 @@ This is a Go project with the following characteristics:
 - Implement proper component lifecycle hooks
 - Use Angular CLI for consistent code generation
 - Follow RxJS best practices for reactive programming`
+		case preact:
+			content += `
+- Use functional components with preact/hooks
+- Use @preact/signals for shared state
+- Only use preact/compat when a React dependency requires it
+- Keep an eye on bundle size`
+		case lit:
+			content += `
+- Extend LitElement and declare reactive properties
+- Scope styles with static styles and the css tag
+- Communicate upwards with custom events
+- Keep components framework agnostic`
+		case qwik:
+			content += `
+- Use component$ and $ boundaries for lazy loading
+- Use useSignal and useStore for state
+- Use routeLoader$ and routeAction$ for data
+- Avoid capturing non-serializable values in closures`
+		case astro:
+			content += `
+- Prefer server-rendered .astro components
+- Hydrate interactive islands with client:* directives
+- Follow the src/pages and src/layouts conventions
+- Use content collections for structured content`
 		}
 
 		if runtime == bun {
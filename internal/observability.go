@@ -0,0 +1,1009 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// createObservabilityFiles writes internal/observability into the API module
+// in baseDir. Everything but route.go is router independent: route.go reads
+// the pattern of the matched route back from the router in use, so metrics
+// and spans are labelled by route rather than by raw path.
+func (pg *ProjectGenerator) createObservabilityFiles(baseDir, router string) error {
+	dir := filepath.Join("internal", "observability")
+	files := map[string]string{
+		filepath.Join(dir, "observability.go"):      pg.generateObservabilityContent(),
+		filepath.Join(dir, "metrics.go"):            pg.generateObservabilityMetricsContent(),
+		filepath.Join(dir, "log.go"):                pg.generateObservabilityLogContent(),
+		filepath.Join(dir, "route.go"):              pg.generateObservabilityRouteContent(router),
+		filepath.Join(dir, "route_test.go"):         pg.generateObservabilityRouteTestContent(router),
+		filepath.Join(dir, "observability_test.go"): pg.generateObservabilityTestContent(),
+	}
+
+	if err := writeProjectFiles(baseDir, files); err != nil {
+		return fmt.Errorf("failed to create observability package: %w", err)
+	}
+
+	return nil
+}
+
+// generateObservabilityMainContent is main.go for API projects created with
+// --observability. It serves /metrics next to the routes, sets up tracing
+// and shuts down gracefully so buffered spans are flushed on exit.
+func (pg *ProjectGenerator) generateObservabilityMainContent(moduleName, projectName, routerType string, useRateLimit, useCache bool) string {
+	routerSetup := "r := web.SetupRoutes()"
+	handler := "r"
+	if routerType == "stdlib" {
+		routerSetup = "web.SetupRoutes()"
+		handler = "http.DefaultServeMux"
+	}
+
+	modulePath := pg.setModuleName(moduleName, projectName)
+
+	imports := []string{modulePath + "/cmd/web", modulePath + "/internal/observability"}
+	if useCache {
+		imports = append(imports, modulePath+"/internal/cache")
+		routerSetup = `c, err := cache.FromEnv()
+	if err != nil {
+		slog.Error("failed to configure the cache", "error", err)
+		os.Exit(1)
+	}
+	cache.Default = c
+
+	` + routerSetup
+	}
+
+	// The limiter goes inside Instrument so rejected requests are measured
+	// too. Instrument unwraps it to find the router.
+	if useRateLimit {
+		imports = append(imports, modulePath+"/internal/ratelimit")
+		routerSetup += `
+
+	limiter, err := ratelimit.FromEnv()
+	if err != nil {
+		slog.Error("failed to configure rate limiting", "error", err)
+		os.Exit(1)
+	}`
+		handler = "limiter.Middleware(" + handler + ")"
+	}
+
+	return fmt.Sprintf(`package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	%[4]s
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+func main() {
+	slog.SetDefault(observability.NewLogger(os.Stdout))
+
+	if err := godotenv.Load(); err != nil {
+		slog.Info("No .env file found, reading configuration from the environment")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := observability.Setup(ctx, "%[1]s", version)
+	if err != nil {
+		slog.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	%[2]s
+
+	metrics := observability.NewMetrics()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/", observability.Instrument(%[3]s, metrics))
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	port = ":" + port
+
+	srv := &http.Server{
+		Addr:              port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("Starting web server", "version", version, "addr", "http://localhost"+port)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("server failed", "error", err)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shut down server", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+}
+`, composeName(projectName), routerSetup, handler, importGroup(imports))
+}
+
+func (pg *ProjectGenerator) generateObservabilityContent() string {
+	return `// Package observability sets up request metrics, tracing and structured
+// logging for the server.
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Setup installs the global tracer provider and propagators and returns a
+// function that flushes and stops the provider.
+//
+// Spans are exported over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set. The exporter, the sampler and
+// the resource read the rest of their configuration from the standard OTEL_*
+// variables, so OTEL_SERVICE_NAME overrides serviceName. Without an endpoint
+// spans are still recorded, so trace IDs keep showing up in the logs.
+func Setup(ctx context.Context, serviceName, version string) (func(context.Context) error, error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", version),
+		),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil && !errors.Is(err, resource.ErrPartialResource) {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
+`
+}
+
+func (pg *ProjectGenerator) generateObservabilityMetricsContent() string {
+	return `package observability
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// unmatchedRoute labels requests no route matched, so unknown paths do not
+// each create a new time series.
+const unmatchedRoute = "unmatched"
+
+// isProbe reports whether r is a liveness or readiness probe. Probes are
+// neither traced nor logged, orchestrators send one every few seconds.
+func isProbe(r *http.Request) bool {
+	return r.URL.Path == "/livez" || r.URL.Path == "/readyz"
+}
+
+// unwrapHandler returns the handler behind middleware that exposes the one it
+// wraps with Unwrap, such as the rate limiter.
+func unwrapHandler(h http.Handler) http.Handler {
+	for {
+		u, ok := h.(interface{ Unwrap() http.Handler })
+		if !ok {
+			return h
+		}
+		h = u.Unwrap()
+	}
+}
+
+// Metrics are the HTTP request metrics of the server together with the Go
+// runtime and process metrics, in a registry of their own.
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "code"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests currently being served.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll)),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.inFlight,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Instrument wraps the router in next with tracing, request metrics and a
+// request log. Incoming trace context is picked up from the request headers
+// and the span is named after the matched route.
+func Instrument(next http.Handler, m *Metrics) http.Handler {
+	instrumented := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		route := serveWithRoute(next, rec, r)
+		if route == "" {
+			route = unmatchedRoute
+		}
+		elapsed := time.Since(start)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		code := strconv.Itoa(status)
+
+		m.requests.WithLabelValues(r.Method, route, code).Inc()
+		m.duration.WithLabelValues(r.Method, route, code).Observe(elapsed.Seconds())
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(attribute.String("http.route", route))
+
+		if !isProbe(r) {
+			slog.InfoContext(r.Context(), "request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"route", route,
+				"status", status,
+				"duration", elapsed.String(),
+			)
+		}
+	})
+
+	return otelhttp.NewHandler(instrumented, "http.server",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !isProbe(r)
+		}),
+	)
+}
+
+// statusRecorder remembers the status code written by the handler. It keeps
+// flushing and hijacking working for streaming responses and WebSockets.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+`
+}
+
+func (pg *ProjectGenerator) generateObservabilityLogContent() string {
+	return `package observability
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewLogger returns a JSON logger that adds the trace and span IDs of the
+// context passed to it, as in slog.InfoContext, to every record.
+func NewLogger(w io.Writer) *slog.Logger {
+	return slog.New(&traceHandler{Handler: slog.NewJSONHandler(w, nil)})
+}
+
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name)}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateObservabilityRouteContent(router string) string {
+	switch router {
+	case "chi":
+		return `package observability
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// serveWithRoute serves r with next and returns the pattern of the route that
+// handled it. chi routes with the route context it finds on the request, so
+// handing it one lets the pattern be read back once routing is done.
+func serveWithRoute(next http.Handler, w http.ResponseWriter, r *http.Request) string {
+	rctx := chi.NewRouteContext()
+	if routes, ok := unwrapHandler(next).(chi.Routes); ok {
+		rctx.Routes = routes
+	}
+
+	next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx)))
+	if pattern := rctx.RoutePattern(); pattern != "" || rctx.Routes == nil {
+		return pattern
+	}
+
+	// Middleware in front of the router, such as the rate limiter, may have
+	// answered without routing.
+	return rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+}
+`
+	case "gorilla":
+		return `package observability
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// serveWithRoute serves r with next and returns the path template of the
+// route that handled it. gorilla/mux only records the route on the copy of
+// the request it passes on, so the request is matched here as well.
+func serveWithRoute(next http.Handler, w http.ResponseWriter, r *http.Request) string {
+	var route string
+	if router, ok := unwrapHandler(next).(*mux.Router); ok {
+		var match mux.RouteMatch
+		if router.Match(r, &match) && match.MatchErr == nil && match.Route != nil {
+			route, _ = match.Route.GetPathTemplate()
+		}
+	}
+
+	next.ServeHTTP(w, r)
+	return route
+}
+`
+	case "httprouter":
+		return `package observability
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// serveWithRoute serves r with next and returns the path of the route that
+// handled it. httprouter does not expose the route it matched, so the path is
+// rebuilt from the parameters of the matching route.
+func serveWithRoute(next http.Handler, w http.ResponseWriter, r *http.Request) string {
+	var route string
+	if router, ok := unwrapHandler(next).(*httprouter.Router); ok {
+		if handle, params, _ := router.Lookup(r.Method, r.URL.Path); handle != nil {
+			route = routeTemplate(r.URL.Path, params)
+		}
+	}
+
+	next.ServeHTTP(w, r)
+	return route
+}
+
+// routeTemplate puts the parameter names back into path, turning
+// /users/42/files/a/b into /users/:id/files/*filepath.
+func routeTemplate(path string, params httprouter.Params) string {
+	segments := strings.Split(path, "/")
+	next := 0
+	for _, p := range params {
+		// Catch-all parameters come last and keep their leading slash.
+		if strings.HasPrefix(p.Value, "/") {
+			n := strings.Count(p.Value, "/")
+			segments = append(segments[:len(segments)-n], "*"+p.Key)
+			break
+		}
+		for i := next; i < len(segments); i++ {
+			if segments[i] == p.Value {
+				segments[i] = ":" + p.Key
+				next = i + 1
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+`
+	default:
+		return `package observability
+
+import (
+	"net/http"
+	"strings"
+)
+
+// serveWithRoute serves r with next and returns the pattern of the route that
+// handled it. http.ServeMux records the pattern on the request it routes.
+func serveWithRoute(next http.Handler, w http.ResponseWriter, r *http.Request) string {
+	next.ServeHTTP(w, r)
+
+	pattern := r.Pattern
+	if mux, ok := unwrapHandler(next).(*http.ServeMux); ok && pattern == "" {
+		// Middleware in front of the mux, such as the rate limiter, may have
+		// answered without routing.
+		_, pattern = mux.Handler(r)
+	}
+
+	// Patterns may start with a method, the method is a label of its own.
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+	return pattern
+}
+`
+	}
+}
+
+// generateObservabilityRouteTestContent returns newTestRouter for the tests
+// in observability_test.go, a router serving /items/{id} written the way the
+// router in use spells it.
+func (pg *ProjectGenerator) generateObservabilityRouteTestContent(router string) string {
+	switch router {
+	case "chi":
+		return `package observability
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const testRoute = "/items/{id}"
+
+func newTestRouter(h http.HandlerFunc) http.Handler {
+	r := chi.NewRouter()
+	r.Get(testRoute, h)
+	return r
+}
+`
+	case "gorilla":
+		return `package observability
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+const testRoute = "/items/{id}"
+
+func newTestRouter(h http.HandlerFunc) http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc(testRoute, h).Methods(http.MethodGet)
+	return r
+}
+`
+	case "httprouter":
+		return `package observability
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const testRoute = "/items/:id"
+
+func newTestRouter(h http.HandlerFunc) http.Handler {
+	r := httprouter.New()
+	r.HandlerFunc(http.MethodGet, testRoute, h)
+	return r
+}
+
+func TestRouteTemplate(t *testing.T) {
+	tests := []struct {
+		path   string
+		params httprouter.Params
+		want   string
+	}{
+		{"/", nil, "/"},
+		{"/items/42", httprouter.Params{{Key: "id", Value: "42"}}, "/items/:id"},
+		{"/items/42/items", httprouter.Params{{Key: "id", Value: "42"}}, "/items/:id/items"},
+		{"/files/a/b", httprouter.Params{{Key: "filepath", Value: "/a/b"}}, "/files/*filepath"},
+		{
+			"/users/7/files/",
+			httprouter.Params{{Key: "id", Value: "7"}, {Key: "filepath", Value: "/"}},
+			"/users/:id/files/*filepath",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := routeTemplate(tt.path, tt.params); got != tt.want {
+			t.Errorf("routeTemplate(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+`
+	default:
+		return `package observability
+
+import "net/http"
+
+const testRoute = "/items/{id}"
+
+func newTestRouter(h http.HandlerFunc) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+testRoute, h)
+	return mux
+}
+`
+	}
+}
+
+func (pg *ProjectGenerator) generateObservabilityTestContent() string {
+	return `package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrument(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	var logs bytes.Buffer
+	slog.SetDefault(NewLogger(&logs))
+
+	m := NewMetrics()
+	handler := Instrument(newTestRouter(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}), m)
+
+	for _, path := range []string{"/items/1", "/items/2", "/nowhere"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"http_requests_total{code=\"201\",method=\"GET\",route=\"" + testRoute + "\"} 2",
+		"http_requests_total{code=\"404\",method=\"GET\",route=\"unmatched\"} 1",
+		"http_request_duration_seconds_count{code=\"201\",method=\"GET\",route=\"" + testRoute + "\"} 2",
+		"http_requests_in_flight 0",
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("got %d spans, want 3", len(ended))
+	}
+	if got, want := ended[0].Name(), "GET "+testRoute; got != want {
+		t.Errorf("span name = %q, want %q", got, want)
+	}
+
+	var record map[string]any
+	line, _, _ := strings.Cut(logs.String(), "\n")
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		t.Fatalf("failed to decode log record %q: %v", line, err)
+	}
+	if got, want := record["trace_id"], ended[0].SpanContext().TraceID().String(); got != want {
+		t.Errorf("trace_id = %v, want %v", got, want)
+	}
+	if got, want := record["span_id"], ended[0].SpanContext().SpanID().String(); got != want {
+		t.Errorf("span_id = %v, want %v", got, want)
+	}
+}
+
+// limiter is middleware in front of the router that answers some requests
+// itself, like the rate limiter.
+type limiter struct{ next http.Handler }
+
+func (l limiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Limited") != "" {
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	l.next.ServeHTTP(w, r)
+}
+
+func (l limiter) Unwrap() http.Handler {
+	return l.next
+}
+
+func TestInstrumentUnwrapsMiddleware(t *testing.T) {
+	m := NewMetrics()
+	handler := Instrument(limiter{newTestRouter(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})}, m)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	limited := httptest.NewRequest(http.MethodGet, "/items/2", nil)
+	limited.Header.Set("X-Limited", "1")
+	handler.ServeHTTP(httptest.NewRecorder(), limited)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, code := range []string{"201", "429"} {
+		want := "http_requests_total{code=\"" + code + "\",method=\"GET\",route=\"" + testRoute + "\"} 1"
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+}
+
+func TestInstrumentSkipsProbes(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	var logs bytes.Buffer
+	slog.SetDefault(NewLogger(&logs))
+
+	handler := Instrument(http.NotFoundHandler(), NewMetrics())
+	for _, path := range []string{"/livez", "/readyz"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if n := len(spans.Ended()); n != 0 {
+		t.Errorf("got %d spans for probes, want none", n)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("got log output for probes: %s", logs.String())
+	}
+}
+`
+}
+
+// generatePrometheusConfig scrapes /metrics on the api service, which is
+// served when the API is generated with --observability.
+func (cc *ComposeConfig) generatePrometheusConfig() string {
+	var b strings.Builder
+	b.WriteString(`global:
+  scrape_interval: 15s
+
+scrape_configs:
+`)
+	if !cc.WorkerOnly && cc.APIPort != 0 {
+		fmt.Fprintf(&b, `  - job_name: api
+    metrics_path: /metrics
+    static_configs:
+      - targets: ["api:%d"]
+`, cc.APIPort)
+	}
+	fmt.Fprintf(&b, `  - job_name: prometheus
+    static_configs:
+      - targets: ["localhost:%d"]
+`, prometheusPort)
+
+	return b.String()
+}
+
+// generateGrafanaDatasources provisions Prometheus and Jaeger in Grafana
+// when they are part of the compose file.
+func (cc *ComposeConfig) generateGrafanaDatasources() string {
+	var b strings.Builder
+	b.WriteString("apiVersion: 1\n\ndatasources:")
+
+	var hasDatasource bool
+	for _, service := range cc.Services {
+		switch service {
+		case ServicePrometheus:
+			fmt.Fprintf(&b, `
+  - name: Prometheus
+    type: prometheus
+    uid: prometheus
+    access: proxy
+    url: http://prometheus:%d
+    isDefault: true
+`, prometheusPort)
+			hasDatasource = true
+		case ServiceJaeger:
+			b.WriteString(`
+  - name: Jaeger
+    type: jaeger
+    uid: jaeger
+    access: proxy
+    url: http://jaeger:16686
+`)
+			hasDatasource = true
+		}
+	}
+	if !hasDatasource {
+		b.WriteString(" []\n")
+	}
+
+	return b.String()
+}
+
+const grafanaDashboardProvider = `apiVersion: 1
+
+providers:
+  - name: default
+    type: file
+    disableDeletion: false
+    updateIntervalSeconds: 30
+    options:
+      path: /var/lib/grafana/dashboards
+`
+
+// grafanaHTTPDashboard is the starter dashboard for the metrics served by
+// internal/observability.
+const grafanaHTTPDashboard = `{
+  "title": "HTTP API",
+  "uid": "http-api",
+  "tags": ["gogen"],
+  "timezone": "browser",
+  "schemaVersion": 39,
+  "refresh": "10s",
+  "time": {
+    "from": "now-1h",
+    "to": "now"
+  },
+  "panels": [
+    {
+      "id": 1,
+      "title": "Requests per second by route",
+      "type": "timeseries",
+      "datasource": { "type": "prometheus", "uid": "prometheus" },
+      "gridPos": { "h": 8, "w": 12, "x": 0, "y": 0 },
+      "fieldConfig": { "defaults": { "unit": "reqps" }, "overrides": [] },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "sum by (route) (rate(http_requests_total[$__rate_interval]))",
+          "legendFormat": "{{route}}"
+        }
+      ]
+    },
+    {
+      "id": 2,
+      "title": "Error rate (5xx)",
+      "type": "timeseries",
+      "datasource": { "type": "prometheus", "uid": "prometheus" },
+      "gridPos": { "h": 8, "w": 12, "x": 12, "y": 0 },
+      "fieldConfig": { "defaults": { "unit": "percentunit", "min": 0 }, "overrides": [] },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "sum(rate(http_requests_total{code=~\"5..\"}[$__rate_interval])) / sum(rate(http_requests_total[$__rate_interval]))",
+          "legendFormat": "errors"
+        }
+      ]
+    },
+    {
+      "id": 3,
+      "title": "Latency",
+      "type": "timeseries",
+      "datasource": { "type": "prometheus", "uid": "prometheus" },
+      "gridPos": { "h": 8, "w": 12, "x": 0, "y": 8 },
+      "fieldConfig": { "defaults": { "unit": "s" }, "overrides": [] },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "histogram_quantile(0.5, sum by (le) (rate(http_request_duration_seconds_bucket[$__rate_interval])))",
+          "legendFormat": "p50"
+        },
+        {
+          "refId": "B",
+          "expr": "histogram_quantile(0.95, sum by (le) (rate(http_request_duration_seconds_bucket[$__rate_interval])))",
+          "legendFormat": "p95"
+        },
+        {
+          "refId": "C",
+          "expr": "histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket[$__rate_interval])))",
+          "legendFormat": "p99"
+        }
+      ]
+    },
+    {
+      "id": 4,
+      "title": "Requests by status code",
+      "type": "timeseries",
+      "datasource": { "type": "prometheus", "uid": "prometheus" },
+      "gridPos": { "h": 8, "w": 12, "x": 12, "y": 8 },
+      "fieldConfig": { "defaults": { "unit": "reqps" }, "overrides": [] },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "sum by (code) (rate(http_requests_total[$__rate_interval]))",
+          "legendFormat": "{{code}}"
+        }
+      ]
+    },
+    {
+      "id": 5,
+      "title": "In-flight requests",
+      "type": "stat",
+      "datasource": { "type": "prometheus", "uid": "prometheus" },
+      "gridPos": { "h": 6, "w": 6, "x": 0, "y": 16 },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "sum(http_requests_in_flight)"
+        }
+      ]
+    },
+    {
+      "id": 6,
+      "title": "Goroutines",
+      "type": "timeseries",
+      "datasource": { "type": "prometheus", "uid": "prometheus" },
+      "gridPos": { "h": 6, "w": 6, "x": 6, "y": 16 },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "go_goroutines{job=\"api\"}",
+          "legendFormat": "goroutines"
+        }
+      ]
+    },
+    {
+      "id": 7,
+      "title": "Heap in use",
+      "type": "timeseries",
+      "datasource": { "type": "prometheus", "uid": "prometheus" },
+      "gridPos": { "h": 6, "w": 6, "x": 12, "y": 16 },
+      "fieldConfig": { "defaults": { "unit": "bytes" }, "overrides": [] },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "go_memstats_heap_inuse_bytes{job=\"api\"}",
+          "legendFormat": "heap"
+        }
+      ]
+    },
+    {
+      "id": 8,
+      "title": "GC pause p99",
+      "type": "timeseries",
+      "datasource": { "type": "prometheus", "uid": "prometheus" },
+      "gridPos": { "h": 6, "w": 6, "x": 18, "y": 16 },
+      "fieldConfig": { "defaults": { "unit": "s" }, "overrides": [] },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "histogram_quantile(0.99, sum by (le) (rate(go_gc_pauses_seconds_bucket{job=\"api\"}[$__rate_interval])))",
+          "legendFormat": "p99"
+        }
+      ]
+    }
+  ]
+}
+`
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	constants "github.com/luigimorel/gogen/consants"
 )
@@ -21,6 +25,33 @@ type WebProjectConfig struct {
 	UseTypeScript     bool
 	UseTailwind       bool
 	UseDocker         bool
+	APIPort           int
+	FrontendPort      int
+	Services          []string
+	DockerBase        string
+	FrontendServer    string
+	// GRPCPort and UseGateway are only set for the grpc template.
+	GRPCPort   int
+	UseGateway bool
+	// WorkspaceDir is the service directory relative to the workspace root
+	// for services created with gogen add service.
+	WorkspaceDir string
+	// UseWASI adds a WASI build to the wasm template.
+	UseWASI bool
+	// LambdaConfig is the deployment config of the lambda template, sam or
+	// serverless.
+	LambdaConfig string
+	// UseObservability adds metrics, tracing and trace-aware logging to the
+	// API of the api and web templates.
+	UseObservability bool
+	// UseRateLimit puts the API of the api and web templates behind a
+	// token-bucket rate limiter.
+	UseRateLimit bool
+	// APIStyle is the API of the api and web templates, rest or graphql.
+	APIStyle string
+	// UseCache adds the cache package to the API of the api and web
+	// templates, configured in main from the environment.
+	UseCache bool
 }
 
 func NewProjectGenerator() *ProjectGenerator {
@@ -45,7 +76,18 @@ func (pg *ProjectGenerator) setDefaultPackages() string {
 	"github.com/joho/godotenv"`
 }
 
-func (pg *ProjectGenerator) generateMainContent(moduleName, projectName, routerType string) string {
+// importGroup lists the import paths sorted, one per line, the way gofmt
+// keeps a group.
+func importGroup(paths []string) string {
+	sort.Strings(paths)
+	quoted := make([]string, len(paths))
+	for i, path := range paths {
+		quoted[i] = strconv.Quote(path)
+	}
+	return strings.Join(quoted, "\n\t")
+}
+
+func (pg *ProjectGenerator) generateMainContent(moduleName, projectName, routerType string, useRateLimit, useCache bool) string {
 	var routerSetup, serverStart string
 
 	if routerType == "stdlib" {
@@ -56,16 +98,47 @@ func (pg *ProjectGenerator) generateMainContent(moduleName, projectName, routerT
 		serverStart = "log.Fatal(http.ListenAndServe(port, r))"
 	}
 
+	modulePath := pg.setModuleName(moduleName, projectName)
+	imports := []string{modulePath + "/cmd/web"}
+	if useCache {
+		imports = append(imports, modulePath+"/internal/cache")
+		routerSetup = `c, err := cache.FromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure the cache: %v", err)
+	}
+	cache.Default = c
+
+	` + routerSetup
+	}
+	if useRateLimit {
+		handler := "r"
+		if routerType == "stdlib" {
+			handler = "http.DefaultServeMux"
+		}
+		imports = append(imports, modulePath+"/internal/ratelimit")
+		routerSetup += `
+
+	limiter, err := ratelimit.FromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure rate limiting: %v", err)
+	}`
+		serverStart = "log.Fatal(http.ListenAndServe(port, limiter.Middleware(" + handler + ")))"
+	}
+
 	return `package main
 
 import (
 	` + pg.setDefaultPackages() + `
-	"` + pg.setModuleName(moduleName, projectName) + `/cmd/web"
+
+	` + importGroup(imports) + `
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
 	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file")
+		log.Println("No .env file found, reading configuration from the environment")
 	}
 
 	` + routerSetup + `
@@ -76,58 +149,30 @@ func main() {
 	}
 	port = ":" + port
 
-	fmt.Printf("Starting web server on http://localhost%s\n", port)
+	fmt.Printf("Starting web server %s on http://localhost%s\n", version, port)
 	` + serverStart + `
-}`
+}
+`
 }
 
-func (pg *ProjectGenerator) CreateCLIProject(projectName, moduleName string) error {
-	mainContent := fmt.Sprintf(`package main
-
-import (
-    "fmt"
-    "log"
-    "os"
+func (pg *ProjectGenerator) CreateCLIProject(projectName, moduleName, framework string) error {
+	if moduleName == "" {
+		moduleName = projectName
+	}
 
-    "github.com/urfave/cli/v2"
-)
+	files := pg.generateCLIFiles(projectName, moduleName, framework)
 
-func main() {
-    app := &cli.App{
-        Name:  "%s",
-        Usage: "A CLI application built with gogen",
-        Action: func(c *cli.Context) error {
-            return cli.ShowAppHelp(c)
-        },
-        Commands: []*cli.Command{
-            {
-                Name:    "greet",
-                Aliases: []string{"g"},
-                Usage:   "Greet someone",
-                Flags: []cli.Flag{
-                    &cli.StringFlag{
-                        Name:  "name",
-                        Value: "World",
-                        Usage: "Name to greet",
-                    },
-                },
-                Action: func(c *cli.Context) error {
-                    name := c.String("name")
-                    fmt.Printf("Hello %%s\n", name)
-                    return nil
-                },
-            },
-        },
-    }
-
-    if err := app.Run(os.Args); err != nil {
-        log.Fatal(err)
-    }
-}
-`, projectName)
+	for _, path := range sortedKeys(files) {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, []byte(files[path]), 0600); err != nil {
+			return err
+		}
+	}
 
-	if err := os.WriteFile("main.go", []byte(mainContent), 0600); err != nil {
-		return err
+	if err := pg.CreateCLIReleaseFiles(".", projectName); err != nil {
+		return fmt.Errorf("failed to create release files: %w", err)
 	}
 
 	if err := pg.InitGitRepository(projectName, constants.CLITemplate); err != nil {
@@ -146,6 +191,101 @@ func main() {
 	return cmd.Run()
 }
 
+// generateCLIFiles returns the Go sources of a CLI project by path. GoReleaser
+// builds the whole main package, so they may spread over several files.
+func (pg *ProjectGenerator) generateCLIFiles(projectName, moduleName, framework string) map[string]string {
+	switch framework {
+	case CLIFrameworkCobra:
+		return pg.generateCobraFiles(projectName, moduleName)
+	case CLIFrameworkStdlib:
+		return pg.generateStdlibCLIFiles(projectName)
+	default:
+		return map[string]string{
+			"main.go":       pg.generateUrfaveMainContent(projectName),
+			"completion.go": pg.generateCompletionContent(),
+		}
+	}
+}
+
+func (pg *ProjectGenerator) generateUrfaveMainContent(projectName string) string {
+	return fmt.Sprintf(`package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Set at build time with -ldflags, see the Makefile and .goreleaser.yaml.
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+func main() {
+	if err := newApp().Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newApp() *cli.App {
+	return &cli.App{
+		Name:                 "%[1]s",
+		Usage:                "A CLI application built with gogen",
+		Version:              version,
+		EnableBashCompletion: true,
+		Action: func(c *cli.Context) error {
+			return cli.ShowAppHelp(c)
+		},
+		Commands: []*cli.Command{
+			{
+				Name:    "greet",
+				Aliases: []string{"g"},
+				Usage:   "Greet someone",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Value: "World",
+						Usage: "Name to greet",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					name := c.String("name")
+					fmt.Fprintf(c.App.Writer, "Hello %%s\n", name)
+					return nil
+				},
+			},
+			{
+				Name:  "version",
+				Usage: "Print version information",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintf(c.App.Writer, "%%s %%s (commit %%s, built %%s)\n", c.App.Name, version, commit, date)
+					return nil
+				},
+			},
+			completionCommand(),
+			{
+				Name:   "man",
+				Usage:  "Generate the man page",
+				Hidden: true,
+				Action: func(c *cli.Context) error {
+					man, err := c.App.ToManWithSection(1)
+					if err != nil {
+						return err
+					}
+					fmt.Fprint(c.App.Writer, man)
+					return nil
+				},
+			},
+		},
+	}
+}
+`, projectName)
+}
+
 func (pg *ProjectGenerator) CreateWebProject(projectName, moduleName, router, frontendFramework, runtime string, useTypeScript, useTailwind, useDocker bool) error {
 	config := &WebProjectConfig{
 		ProjectName:       projectName,
@@ -179,7 +319,7 @@ func (pg *ProjectGenerator) CreateWebProjectWithConfig(config *WebProjectConfig)
 }
 
 func (pg *ProjectGenerator) setupAPIProject(config *WebProjectConfig, dm *DirectoryManager) error {
-	if err := pg.createAPIProjectInDir(constants.APIDir, config.ProjectName, config.ModuleName, config.Router); err != nil {
+	if err := pg.createAPIProjectInDir(constants.APIDir, config); err != nil {
 		return fmt.Errorf("failed to create API project: %w", err)
 	}
 
@@ -193,11 +333,11 @@ func (pg *ProjectGenerator) setupAPIProject(config *WebProjectConfig, dm *Direct
 	}()
 
 	if config.UseDocker {
-		if err := pg.CreateDockerfile(".", constants.APIDir, config.Runtime); err != nil {
+		if err := pg.CreateDockerfile(".", constants.APIDir, config); err != nil {
 			return fmt.Errorf("failed to create Docker files for API: %w", err)
 		}
 
-		if err := pg.CreateDockerComposeFile(".."); err != nil {
+		if err := pg.CreateDockerComposeFile("..", NewComposeConfig(config)); err != nil {
 			return fmt.Errorf("failed to create docker-compose files: %w", err)
 		}
 	}
@@ -218,6 +358,14 @@ func (pg *ProjectGenerator) setupFrontendProject(config *WebProjectConfig, dm *D
 		return fmt.Errorf("failed to create frontend project: %w", err)
 	}
 
+	// The frontend image serves the build as static files, so frameworks
+	// that render on a server are only switched to a static build for it.
+	if config.UseDocker {
+		if err := pg.configureStaticBuild(config.FrontendFramework, constants.FrontendDir, config.Runtime, config.UseTypeScript); err != nil {
+			return err
+		}
+	}
+
 	if err := dm.ChangeToDir(constants.FrontendDir); err != nil {
 		return fmt.Errorf("failed to change to frontend directory: %w", err)
 	}
@@ -234,7 +382,7 @@ func (pg *ProjectGenerator) setupFrontendProject(config *WebProjectConfig, dm *D
 }
 
 func (pg *ProjectGenerator) createFrontendConfigFiles(config *WebProjectConfig) {
-	if err := pg.CreateEnvFile(constants.FrontendDir, "."); err != nil {
+	if err := pg.createEnvFile(constants.FrontendDir, ".", config.APIStyle, config.FrontendFramework); err != nil {
 		fmt.Printf("Warning: failed to create env file: %v\n", err)
 	}
 
@@ -243,7 +391,7 @@ func (pg *ProjectGenerator) createFrontendConfigFiles(config *WebProjectConfig)
 	}
 
 	if config.UseDocker {
-		if err := pg.CreateDockerfile(".", constants.FrontendDir, config.Runtime); err != nil {
+		if err := pg.CreateDockerfile(".", constants.FrontendDir, config); err != nil {
 			fmt.Printf("Warning: failed to create Docker files for frontend: %v\n", err)
 		}
 	}
@@ -266,10 +414,39 @@ func (pg *ProjectGenerator) createConfigFiles() error {
 }
 
 func (pg *ProjectGenerator) CreateAPIProject(projectName, moduleName, router string) error {
-	return pg.createAPIProjectInDir(".", projectName, moduleName, router)
+	config := &WebProjectConfig{
+		ProjectName: projectName,
+		ModuleName:  moduleName,
+		Router:      router,
+	}
+
+	return pg.CreateAPIProjectWithConfig(config)
 }
 
-func (pg *ProjectGenerator) createAPIProjectInDir(baseDir, projectName, moduleName, router string) error {
+func (pg *ProjectGenerator) CreateAPIProjectWithConfig(config *WebProjectConfig) error {
+	if err := pg.createAPIProjectInDir(".", config); err != nil {
+		return err
+	}
+
+	if config.UseDocker {
+		if err := pg.CreateDockerfile(".", constants.APIDir, config); err != nil {
+			return fmt.Errorf("failed to create Docker files: %w", err)
+		}
+
+		// API projects live at the repository root, not in api/.
+		cc := NewComposeConfig(config)
+		cc.Context = "."
+		if err := pg.CreateDockerComposeFile(".", cc); err != nil {
+			return fmt.Errorf("failed to create docker-compose files: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (pg *ProjectGenerator) createAPIProjectInDir(baseDir string, config *WebProjectConfig) error {
+	projectName, moduleName := config.ProjectName, config.ModuleName
+
 	cmdWebDir := fmt.Sprintf("%s/cmd/web", baseDir)
 	if baseDir == "." {
 		cmdWebDir = "cmd/web"
@@ -282,22 +459,62 @@ func (pg *ProjectGenerator) createAPIProjectInDir(baseDir, projectName, moduleNa
 	var mainContent string
 	var routesContent string
 
-	switch router {
+	modulePath := pg.setModuleName(moduleName, projectName)
+	routerType := config.Router
+	switch routerType {
 	case "chi":
-		mainContent = pg.generateMainContent(moduleName, projectName, "chi")
-		routesContent = pg.RouterGenerator.generateChiContent()
+		routesContent = pg.RouterGenerator.generateChiContent(modulePath)
 
 	case "gorilla":
-		mainContent = pg.generateMainContent(moduleName, projectName, "gorilla")
-		routesContent = pg.RouterGenerator.generateGorillaContent()
+		routesContent = pg.RouterGenerator.generateGorillaContent(modulePath)
 
 	case "httprouter":
-		mainContent = pg.generateMainContent(moduleName, projectName, "httprouter")
-		routesContent = pg.RouterGenerator.generateHttpRouterContent()
+		routesContent = pg.RouterGenerator.generateHttpRouterContent(modulePath)
 
 	default:
-		mainContent = pg.generateMainContent(moduleName, projectName, "stdlib")
-		routesContent = pg.RouterGenerator.generateStdlibContent()
+		routerType = "stdlib"
+		routesContent = pg.RouterGenerator.generateStdlibContent(modulePath)
+	}
+
+	if config.APIStyle == APIStyleGraphQL {
+		routesContent = pg.RouterGenerator.generateGraphQLRoutesContent(modulePath, routerType)
+		if err := pg.createGraphQLFiles(baseDir, modulePath); err != nil {
+			return err
+		}
+	}
+
+	if config.UseObservability {
+		mainContent = pg.generateObservabilityMainContent(moduleName, projectName, routerType, config.UseRateLimit, config.UseCache)
+		if err := pg.createObservabilityFiles(baseDir, routerType); err != nil {
+			return err
+		}
+	} else {
+		mainContent = pg.generateMainContent(moduleName, projectName, routerType, config.UseRateLimit, config.UseCache)
+	}
+
+	if config.UseRateLimit {
+		if err := pg.createRateLimitFiles(baseDir, modulePath); err != nil {
+			return err
+		}
+	}
+
+	if config.UseCache {
+		if err := pg.createCacheFiles(baseDir); err != nil {
+			return err
+		}
+		cached, err := cacheHomeRoute(routesContent, modulePath)
+		if err != nil {
+			return err
+		}
+		routesContent = cached
+	}
+
+	if err := pg.createHealthFiles(baseDir); err != nil {
+		return err
+	}
+
+	if err := pg.createHTTPXFiles(baseDir); err != nil {
+		return err
 	}
 
 	mainGoPath := fmt.Sprintf("%s/main.go", baseDir)
@@ -318,8 +535,7 @@ func (pg *ProjectGenerator) createAPIProjectInDir(baseDir, projectName, moduleNa
 		return err
 	}
 
-	baseModuleName := pg.setModuleName(moduleName, projectName)
-	apiModContent := fmt.Sprintf("module %s\n\ngo 1.21\n", baseModuleName)
+	apiModContent := fmt.Sprintf("module %s\n\ngo 1.21\n", modulePath)
 	if err := os.WriteFile(goModPath, []byte(apiModContent), 0600); err != nil {
 		return err
 	}
@@ -328,10 +544,28 @@ func (pg *ProjectGenerator) createAPIProjectInDir(baseDir, projectName, moduleNa
 		fmt.Printf("Warning: failed to create env file: %v\n", err)
 	}
 
+	if config.UseRateLimit {
+		if err := appendEnvBlock(filepath.Join(baseDir, ".env.example"), rateLimitEnvExample); err != nil {
+			fmt.Printf("Warning: failed to update .env.example: %v\n", err)
+		}
+	}
+
+	if config.UseCache {
+		if err := appendEnvBlock(filepath.Join(baseDir, ".env.example"), cacheEnvExample); err != nil {
+			fmt.Printf("Warning: failed to update .env.example: %v\n", err)
+		}
+	}
+
 	if err := pg.InitGitRepository(projectName, baseDir); err != nil {
 		fmt.Printf("Warning: failed to initialize git repository: %v\n", err)
 	}
 
+	if config.APIStyle == APIStyleGraphQL {
+		if err := addGraphQLDependencies(baseDir); err != nil {
+			return err
+		}
+	}
+
 	originalDir, _ := os.Getwd()
 	if baseDir != "." {
 		if err := os.Chdir(baseDir); err != nil {
@@ -355,7 +589,7 @@ func (pg *ProjectGenerator) createAPIProjectInDir(baseDir, projectName, moduleNa
 		}
 	}
 
-	if err := pg.CreateAirFile(".", constants.WebTemplate); err != nil {
+	if err := pg.CreateAirFile(baseDir, constants.APITemplate); err != nil {
 		fmt.Printf("Warning: failed to create .air.toml file: %v\n", err)
 	}
 
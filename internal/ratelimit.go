@@ -0,0 +1,1006 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// createRateLimitFiles writes internal/ratelimit into the API module in
+// baseDir. main.go wraps the router SetupRoutes returns with its middleware.
+func (pg *ProjectGenerator) createRateLimitFiles(baseDir, modulePath string) error {
+	dir := filepath.Join("internal", "ratelimit")
+	files := map[string]string{
+		filepath.Join(dir, "ratelimit.go"):      pg.generateRateLimitContent(modulePath),
+		filepath.Join(dir, "config.go"):         pg.generateRateLimitConfigContent(),
+		filepath.Join(dir, "key.go"):            pg.generateRateLimitKeyContent(),
+		filepath.Join(dir, "memory.go"):         pg.generateRateLimitMemoryContent(),
+		filepath.Join(dir, "redis.go"):          pg.generateRateLimitRedisContent(),
+		filepath.Join(dir, "ratelimit_test.go"): pg.generateRateLimitTestContent(),
+		filepath.Join(dir, "store_test.go"):     pg.generateRateLimitStoreTestContent(),
+	}
+
+	if err := writeProjectFiles(baseDir, files); err != nil {
+		return fmt.Errorf("failed to create ratelimit package: %w", err)
+	}
+
+	return nil
+}
+
+// rateLimitEnvExample lists the settings FromEnv reads, commented out with
+// their defaults, for .env.example.
+const rateLimitEnvExample = `# Rate limiting
+# RATE_LIMIT=100/1m
+# RATE_LIMIT_ROUTES=/greetings=10/1m,/admin=off
+# RATE_LIMIT_KEY=ip
+# RATE_LIMIT_API_KEY_HEADER=X-API-Key
+# RATE_LIMIT_IP_HEADER=X-Forwarded-For
+# RATE_LIMIT_STORE=memory
+`
+
+func (pg *ProjectGenerator) generateRateLimitContent(modulePath string) string {
+	return `// Package ratelimit limits requests per client with token buckets.
+//
+// Every client gets a bucket per route group holding up to Limit.Requests
+// tokens, refilled evenly over Limit.Period. Each request takes a token and
+// requests that find the bucket empty are answered with 429 Too Many
+// Requests and a Retry-After header. Limited responses carry the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers.
+//
+// Buckets live in a Store: MemoryStore for a single instance, RedisStore to
+// share them between instances. FromEnv builds a Limiter from the
+// environment.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"` + modulePath + `/internal/httpx"
+)
+
+// Limit allows Requests requests per Period, in bursts of up to Requests.
+// The zero Limit does not limit anything.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit written as <requests>/<period>, such as 100/1m
+// or 10/s. "off" is the zero Limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q: want <requests>/<period>, such as 100/1m", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: requests must be a positive number", s)
+	}
+
+	// Allow "10/s" as well as "10/1s".
+	if period != "" && strings.IndexAny(period[:1], "0123456789") < 0 {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: period must be a positive duration", s)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Enabled reports whether l limits requests at all.
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+
+	period := l.Period.String()
+	if strings.HasSuffix(period, "m0s") {
+		period = strings.TrimSuffix(period, "0s")
+	}
+	if strings.HasSuffix(period, "h0m") {
+		period = strings.TrimSuffix(period, "0m")
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, period)
+}
+
+// rate is the number of tokens added to a bucket per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// after returns how long a bucket takes to gain n tokens.
+func (l Limit) after(n float64) time.Duration {
+	return time.Duration(math.Ceil(n / l.rate() * float64(time.Second)))
+}
+
+// result describes a bucket left with tokens after a request was allowed
+// or not.
+func (l Limit) result(allowed bool, tokens float64) Result {
+	res := Result{
+		Allowed:   allowed,
+		Remaining: int(tokens),
+		Reset:     l.after(float64(l.Requests) - tokens),
+	}
+	if !allowed {
+		res.RetryAfter = l.after(1 - tokens)
+	}
+	return res
+}
+
+// take refills a bucket that held tokens elapsed ago and takes a token from
+// it if there is one. It returns the tokens left.
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, Result) {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	tokens = math.Min(float64(limit.Requests), tokens+elapsed.Seconds()*limit.rate())
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	return tokens, limit.result(allowed, tokens)
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of requests the bucket still allows.
+	Remaining int
+	// RetryAfter is how long until the next token when Allowed is false.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps the buckets of all clients.
+type Store interface {
+	// Take takes a token from the bucket stored at key, creating a full
+	// bucket for limit if there is none.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Rule gives the route group below Prefix a limit of its own.
+type Rule struct {
+	Prefix string
+	Limit  Limit
+}
+
+// Config configures a Limiter.
+type Config struct {
+	// Default applies to requests no rule matches.
+	Default Limit
+	// Rules limit route groups by path prefix, the longest match wins. A
+	// rule with the zero Limit turns limiting off for its group.
+	Rules []Rule
+	// Exempt paths, such as health probes, are never limited.
+	Exempt []string
+	// Key returns the client a request counts against, ByIP("") if nil.
+	Key KeyFunc
+	// Store keeps the buckets, a new MemoryStore if nil.
+	Store Store
+}
+
+// Limiter limits requests as configured by its Config.
+type Limiter struct {
+	cfg Config
+}
+
+// New returns a Limiter for cfg.
+func New(cfg Config) *Limiter {
+	if cfg.Key == nil {
+		cfg.Key = ByIP("")
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	cfg.Rules = append([]Rule(nil), cfg.Rules...)
+	sort.SliceStable(cfg.Rules, func(i, j int) bool {
+		return len(cfg.Rules[i].Prefix) > len(cfg.Rules[j].Prefix)
+	})
+
+	return &Limiter{cfg: cfg}
+}
+
+// match returns the route group of path and its limit.
+func (l *Limiter) match(path string) (string, Limit) {
+	for _, exempt := range l.cfg.Exempt {
+		if path == exempt {
+			return "", Limit{}
+		}
+	}
+
+	for _, rule := range l.cfg.Rules {
+		prefix := strings.TrimSuffix(rule.Prefix, "/")
+		if path == rule.Prefix || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return rule.Prefix, rule.Limit
+		}
+	}
+
+	return "*", l.cfg.Default
+}
+
+// Middleware limits the requests served by next.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return &handler{limiter: l, next: next}
+}
+
+type handler struct {
+	limiter *Limiter
+	next    http.Handler
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	group, limit := h.limiter.match(r.URL.Path)
+	if !limit.Enabled() {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	key := group + "|" + h.limiter.cfg.Key(r)
+	res, err := h.limiter.cfg.Store.Take(r.Context(), key, limit)
+	if err != nil {
+		// Fail open: an unavailable store must not take the API down too.
+		if !errors.Is(err, context.Canceled) {
+			slog.WarnContext(r.Context(), "rate limit store failed, request not limited", "error", err)
+		}
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	header := w.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, seconds(limit.Period)))
+
+	if !res.Allowed {
+		retryAfter := seconds(res.RetryAfter)
+		header.Set("Retry-After", strconv.Itoa(retryAfter))
+		httpx.WriteError(w, r, httpx.Errorf(http.StatusTooManyRequests,
+			"rate limit of %s exceeded, retry in %ds", limit, retryAfter))
+		return
+	}
+
+	h.next.ServeHTTP(w, r)
+}
+
+// Unwrap returns the handler being limited, so middleware in front of the
+// limiter can still tell which router serves the requests.
+func (h *handler) Unwrap() http.Handler {
+	return h.next
+}
+
+// seconds rounds d up to whole seconds, as the headers want them.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRateLimitConfigContent() string {
+	return `package ratelimit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultLimit applies when RATE_LIMIT is not set.
+const DefaultLimit = "100/1m"
+
+// FromEnv builds a Limiter from these variables:
+//
+//	RATE_LIMIT                 limit of every route, such as 100/1m (the default) or off
+//	RATE_LIMIT_ROUTES          limits of route groups, such as /greetings=10/1m,/admin=off
+//	RATE_LIMIT_KEY             what requests are counted by: ip (the default), api-key or user
+//	RATE_LIMIT_API_KEY_HEADER  header carrying the API key, X-API-Key by default
+//	RATE_LIMIT_IP_HEADER       header a trusted proxy puts the client IP in, such as X-Forwarded-For
+//	RATE_LIMIT_STORE           memory (the default) or redis, which connects to REDIS_URL
+//
+// The liveness and readiness probes are never limited.
+func FromEnv() (*Limiter, error) {
+	cfg := Config{
+		Exempt: []string{"` + LivenessPath + `", "` + ReadinessPath + `"},
+	}
+
+	var err error
+	if cfg.Default, err = ParseLimit(getenv("RATE_LIMIT", DefaultLimit)); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT: %w", err)
+	}
+	if cfg.Rules, err = parseRules(os.Getenv("RATE_LIMIT_ROUTES")); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+	}
+
+	byIP := ByIP(os.Getenv("RATE_LIMIT_IP_HEADER"))
+	switch key := getenv("RATE_LIMIT_KEY", "ip"); key {
+	case "ip":
+		cfg.Key = byIP
+	case "api-key":
+		cfg.Key = ByAPIKey(getenv("RATE_LIMIT_API_KEY_HEADER", "X-API-Key"), byIP)
+	case "user":
+		cfg.Key = ByUser(byIP)
+	default:
+		return nil, fmt.Errorf("RATE_LIMIT_KEY: unknown key %q, want ip, api-key or user", key)
+	}
+
+	switch store := getenv("RATE_LIMIT_STORE", "memory"); store {
+	case "memory":
+		cfg.Store = NewMemoryStore()
+	case "redis":
+		url := os.Getenv("REDIS_URL")
+		if url == "" {
+			return nil, fmt.Errorf("RATE_LIMIT_STORE=redis needs REDIS_URL")
+		}
+		opts, err := redis.ParseURL(url)
+		if err != nil {
+			return nil, fmt.Errorf("REDIS_URL: %w", err)
+		}
+		cfg.Store = NewRedisStore(redis.NewClient(opts), "ratelimit:")
+	default:
+		return nil, fmt.Errorf("RATE_LIMIT_STORE: unknown store %q, want memory or redis", store)
+	}
+
+	return New(cfg), nil
+}
+
+// parseRules parses comma separated <prefix>=<limit> pairs.
+func parseRules(s string) ([]Rule, error) {
+	var rules []Rule
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		prefix, value, ok := strings.Cut(pair, "=")
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("invalid rule %q: want <path prefix>=<limit>, such as /greetings=10/1m", pair)
+		}
+
+		limit, err := ParseLimit(value)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, Rule{Prefix: prefix, Limit: limit})
+	}
+	return rules, nil
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRateLimitKeyContent() string {
+	return `package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// KeyFunc returns the client a request counts against.
+type KeyFunc func(r *http.Request) string
+
+// ByIP counts requests per client IP. That is the remote address of the
+// connection, or when header is set, the address a trusted reverse proxy
+// puts in it. For X-Forwarded-For the last address is used, the one the
+// proxy appended; the ones before it are up to the client.
+//
+// Only set header when every request goes through such a proxy, clients
+// could pick their own key otherwise.
+func ByIP(header string) KeyFunc {
+	return func(r *http.Request) string {
+		if header != "" {
+			values := strings.Split(r.Header.Get(header), ",")
+			if ip := strings.TrimSpace(values[len(values)-1]); ip != "" {
+				return "ip:" + ip
+			}
+		}
+
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		return "ip:" + host
+	}
+}
+
+// ByAPIKey counts requests per API key sent in header, and requests without
+// one with fallback. Keys are hashed so the store never holds them.
+func ByAPIKey(header string, fallback KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		key := r.Header.Get(header)
+		if key == "" {
+			return fallback(r)
+		}
+
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+}
+
+type userKey struct{}
+
+// WithUser records the authenticated user of a request, for ByUser.
+func WithUser(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userKey{}, id)
+}
+
+// ByUser counts requests per user recorded with WithUser, and anonymous
+// requests with fallback. The authentication middleware calling WithUser
+// has to run before the limiter for the user to be known.
+func ByUser(fallback KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		if id, _ := r.Context().Value(userKey{}).(string); id != "" {
+			return "user:" + id
+		}
+		return fallback(r)
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRateLimitMemoryContent() string {
+	return `package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often a MemoryStore drops the buckets that refilled.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in memory. Each instance of the API limits its
+// own requests, use a RedisStore to share the limits between instances.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket has refilled, after which it is the same as
+	// no bucket at all.
+	full time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updated: now}
+		s.buckets[key] = b
+	}
+
+	tokens, res := take(b.tokens, now.Sub(b.updated), limit)
+	b.tokens, b.updated, b.full = tokens, now, now.Add(res.Reset)
+
+	return res, nil
+}
+
+// sweep drops the buckets that refilled, so clients that went away do not
+// use memory forever.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRateLimitRedisContent() string {
+	return `package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript is take run atomically in Redis. The bucket is a hash of its
+// tokens and the time it was updated at, in microseconds of the Redis clock
+// so instances with skewed clocks agree. It expires once refilled.
+var takeScript = redis.NewScript(` + "`" + `
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(bucket[1]) or burst
+local updated = tonumber(bucket[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate / 1000) + 1000)
+
+-- Numbers would be truncated to integers in the reply.
+return {allowed, tostring(tokens)}
+` + "`" + `)
+
+// RedisStore keeps buckets in Redis, so every instance of the API shares
+// them.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore returns a RedisStore keeping buckets in client under keys
+// starting with prefix.
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	// The script counts time in microseconds.
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Requests, limit.rate()/1e6).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take token from %s: %w", key, err)
+	}
+
+	allowed, _ := reply[0].(int64)
+	value, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse tokens of %s: %w", key, err)
+	}
+
+	return limit.result(allowed == 1, tokens), nil
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRateLimitTestContent() string {
+	return `package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "100/1m", want: Limit{Requests: 100, Period: time.Minute}},
+		{in: "10/s", want: Limit{Requests: 10, Period: time.Second}},
+		{in: " 5/1h30m ", want: Limit{Requests: 5, Period: 90 * time.Minute}},
+		{in: "off", want: Limit{}},
+		{in: "100", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "ten/1m", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/fortnight", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error = %v, want error %t", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLimitString(t *testing.T) {
+	for in, want := range map[string]string{"100/1m": "100/1m", "10/s": "10/1s", "5/1h": "5/1h", "3/90s": "3/1m30s", "off": "off"} {
+		limit, err := ParseLimit(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := limit.String(); got != want {
+			t.Errorf("ParseLimit(%q).String() = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// newTestLimiter returns a limiter with a memory store whose clock only
+// moves when the returned func is called.
+func newTestLimiter(cfg Config) (*Limiter, func(time.Duration)) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	cfg.Store = store
+
+	return New(cfg), func(d time.Duration) { now = now.Add(d) }
+}
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+})
+
+func get(h http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter, advance := newTestLimiter(Config{Default: Limit{Requests: 2, Period: time.Minute}})
+	h := limiter.Middleware(ok)
+
+	for i, want := range []struct {
+		status    int
+		remaining string
+	}{
+		{http.StatusNoContent, "1"},
+		{http.StatusNoContent, "0"},
+		{http.StatusTooManyRequests, "0"},
+	} {
+		w := get(h, "/")
+		if w.Code != want.status {
+			t.Fatalf("request %d: status = %d, want %d", i, w.Code, want.status)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != want.remaining {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %q", i, got, want.remaining)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: RateLimit-Limit = %q, want 2", i, got)
+		}
+		if got := w.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+			t.Errorf("request %d: RateLimit-Policy = %q, want 2;w=60", i, got)
+		}
+	}
+
+	w := get(h, "/")
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+	if got := w.Header().Get("RateLimit-Reset"); got != "60" {
+		t.Errorf("RateLimit-Reset = %q, want 60", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", got)
+	}
+
+	advance(30 * time.Second)
+	if w := get(h, "/"); w.Code != http.StatusNoContent {
+		t.Errorf("status after refill = %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestMiddlewareRouteGroups(t *testing.T) {
+	limiter, _ := newTestLimiter(Config{
+		Default: Limit{Requests: 2, Period: time.Minute},
+		Rules: []Rule{
+			{Prefix: "/greetings", Limit: Limit{Requests: 1, Period: time.Minute}},
+			{Prefix: "/greetings/bulk", Limit: Limit{Requests: 3, Period: time.Minute}},
+			{Prefix: "/public/", Limit: Limit{}},
+		},
+		Exempt: []string{"/livez"},
+	})
+	h := limiter.Middleware(ok)
+
+	tests := []struct {
+		path   string
+		status int
+		limit  string
+	}{
+		{"/greetings", http.StatusNoContent, "1"},
+		{"/greetings/42", http.StatusTooManyRequests, "1"},
+		{"/greetings/bulk", http.StatusNoContent, "3"},
+		{"/greetingsx", http.StatusNoContent, "2"},
+		{"/", http.StatusNoContent, "2"},
+		{"/", http.StatusTooManyRequests, "2"},
+		{"/public", http.StatusNoContent, ""},
+		{"/public/a/b", http.StatusNoContent, ""},
+		{"/livez", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		w := get(h, tt.path)
+		if w.Code != tt.status {
+			t.Errorf("GET %s: status = %d, want %d", tt.path, w.Code, tt.status)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != tt.limit {
+			t.Errorf("GET %s: RateLimit-Limit = %q, want %q", tt.path, got, tt.limit)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	byIP := ByIP("")
+	tests := []struct {
+		name   string
+		key    KeyFunc
+		header map[string]string
+		user   string
+		want   string
+	}{
+		{name: "remote address", key: byIP, want: "ip:192.0.2.1"},
+		{name: "proxy header ignored", key: byIP, header: map[string]string{"X-Forwarded-For": "203.0.113.9"}, want: "ip:192.0.2.1"},
+		{name: "proxy header", key: ByIP("X-Forwarded-For"), header: map[string]string{"X-Forwarded-For": "10.0.0.1, 203.0.113.9"}, want: "ip:203.0.113.9"},
+		{name: "missing proxy header", key: ByIP("X-Forwarded-For"), want: "ip:192.0.2.1"},
+		{name: "api key", key: ByAPIKey("X-API-Key", byIP), header: map[string]string{"X-API-Key": "secret"}, want: "key:2bb80d537b1da3e38bd30361aa855686"},
+		{name: "no api key", key: ByAPIKey("X-API-Key", byIP), want: "ip:192.0.2.1"},
+		{name: "user", key: ByUser(byIP), user: "u_42", want: "user:u_42"},
+		{name: "anonymous", key: ByUser(byIP), want: "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			if tt.user != "" {
+				r = r.WithContext(WithUser(r.Context(), tt.user))
+			}
+
+			if got := tt.key(r); got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareSeparatesClients(t *testing.T) {
+	limiter, _ := newTestLimiter(Config{
+		Default: Limit{Requests: 1, Period: time.Minute},
+		Key:     ByAPIKey("X-API-Key", ByIP("")),
+	})
+	h := limiter.Middleware(ok)
+
+	for _, key := range []string{"alice", "bob"} {
+		if w := get(h, "/", "X-API-Key", key); w.Code != http.StatusNoContent {
+			t.Errorf("first request of %s: status = %d, want %d", key, w.Code, http.StatusNoContent)
+		}
+	}
+	if w := get(h, "/", "X-API-Key", "alice"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second request of alice: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit) (Result, error) {
+	return Result{}, errors.New("connection refused")
+}
+
+func TestMiddlewareFailsOpen(t *testing.T) {
+	limiter := New(Config{Default: Limit{Requests: 1, Period: time.Minute}, Store: failingStore{}})
+	h := limiter.Middleware(ok)
+
+	for i := 0; i < 3; i++ {
+		if w := get(h, "/"); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: status = %d, want %d", i, w.Code, http.StatusNoContent)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT", "1/1m")
+	t.Setenv("RATE_LIMIT_ROUTES", "/greetings=off")
+	t.Setenv("RATE_LIMIT_KEY", "api-key")
+
+	limiter, err := FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := limiter.Middleware(ok)
+
+	for i := 0; i < 2; i++ {
+		if w := get(h, "/greetings"); w.Code != http.StatusNoContent {
+			t.Errorf("GET /greetings: status = %d, want %d", w.Code, http.StatusNoContent)
+		}
+		if w := get(h, "/readyz"); w.Code != http.StatusNoContent {
+			t.Errorf("GET /readyz: status = %d, want %d", w.Code, http.StatusNoContent)
+		}
+	}
+	get(h, "/", "X-API-Key", "alice")
+	if w := get(h, "/", "X-API-Key", "alice"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("GET /: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	for key, value := range map[string]string{
+		"RATE_LIMIT":        "fast",
+		"RATE_LIMIT_ROUTES": "greetings=1/1m",
+		"RATE_LIMIT_KEY":    "cookie",
+		"RATE_LIMIT_STORE":  "redis",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			t.Setenv("REDIS_URL", "")
+			if _, err := FromEnv(); err == nil {
+				t.Errorf("FromEnv with %s=%s succeeded, want an error", key, value)
+			}
+		})
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRateLimitStoreTestContent() string {
+	return `package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// stores returns every Store, each with a clock moved by its advance func.
+func stores(t *testing.T) map[string]struct {
+	store   Store
+	advance func(time.Duration)
+} {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	memoryNow := start
+	memory := NewMemoryStore()
+	memory.now = func() time.Time { return memoryNow }
+
+	redisNow := start
+	server := miniredis.RunT(t)
+	server.SetTime(redisNow)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]struct {
+		store   Store
+		advance func(time.Duration)
+	}{
+		"memory": {memory, func(d time.Duration) { memoryNow = memoryNow.Add(d) }},
+		"redis": {NewRedisStore(client, "test:"), func(d time.Duration) {
+			redisNow = redisNow.Add(d)
+			server.SetTime(redisNow)
+		}},
+	}
+}
+
+func TestStores(t *testing.T) {
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			takeN := func(n int) Result {
+				t.Helper()
+				var res Result
+				for i := 0; i < n; i++ {
+					var err error
+					if res, err = s.store.Take(ctx, "client", limit); err != nil {
+						t.Fatal(err)
+					}
+				}
+				return res
+			}
+
+			if res := takeN(1); !res.Allowed || res.Remaining != 2 || res.Reset != time.Second {
+				t.Errorf("first take = %+v, want allowed with 2 remaining and a 1s reset", res)
+			}
+			if res := takeN(2); !res.Allowed || res.Remaining != 0 || res.Reset != 3*time.Second {
+				t.Errorf("third take = %+v, want allowed with 0 remaining and a 3s reset", res)
+			}
+			if res := takeN(1); res.Allowed || res.RetryAfter != time.Second {
+				t.Errorf("fourth take = %+v, want denied with a 1s retry", res)
+			}
+
+			s.advance(1500 * time.Millisecond)
+			if res := takeN(1); !res.Allowed || res.Remaining != 0 {
+				t.Errorf("take after 1.5s = %+v, want allowed with 0 remaining", res)
+			}
+			if res := takeN(1); res.Allowed || res.RetryAfter != 500*time.Millisecond {
+				t.Errorf("second take after 1.5s = %+v, want denied with a 500ms retry", res)
+			}
+
+			if res, err := s.store.Take(ctx, "other", limit); err != nil || !res.Allowed || res.Remaining != 2 {
+				t.Errorf("take of another client = %+v, %v, want a bucket of its own", res, err)
+			}
+
+			s.advance(time.Hour)
+			if res := takeN(1); !res.Allowed || res.Remaining != 2 {
+				t.Errorf("take after an hour = %+v, want a full bucket", res)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSweeps(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 10, Period: time.Second}
+
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := store.Take(context.Background(), key, limit); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = now.Add(2 * sweepInterval)
+	if _, err := store.Take(context.Background(), "d", limit); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.buckets) != 1 {
+		t.Errorf("%d buckets left after the sweep, want 1", len(store.buckets))
+	}
+}
+`
+}
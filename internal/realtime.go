@@ -0,0 +1,1565 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	RealtimeWebSocket = "ws"
+	RealtimeSSE       = "sse"
+)
+
+const realtimePackageDir = "internal/realtime"
+
+// routesFile holds the SetupRoutes function gogen add registers routes in.
+const routesFile = "cmd/web/routes.go"
+
+// IsRealtimeTransport reports whether transport is one gogen add realtime
+// can generate.
+func IsRealtimeTransport(transport string) bool {
+	return transport == RealtimeWebSocket || transport == RealtimeSSE
+}
+
+// RealtimeEndpoint returns the path the routes serve transport on.
+func RealtimeEndpoint(transport string) string {
+	if transport == RealtimeSSE {
+		return "/events"
+	}
+	return "/ws"
+}
+
+// RealtimeResult describes what AddRealtime changed, for the instructions
+// printed afterwards.
+type RealtimeResult struct {
+	ModuleDir string
+	// RouteAdded is false when the endpoint could not be registered in
+	// cmd/web/routes.go and has to be added by hand.
+	RouteAdded bool
+	// ShutdownAdded is false when main.go has no http.Server to close the
+	// hub from.
+	ShutdownAdded bool
+	// ClientFiles lists the frontend files written for web projects.
+	ClientFiles []string
+}
+
+// AddRealtime adds internal/realtime to the Go module in rootDir, or in
+// api/ for web projects, and registers its endpoint in cmd/web/routes.go.
+// Web projects also get a client for their frontend framework next to
+// src/config.
+func (pg *ProjectGenerator) AddRealtime(rootDir, transport string) (*RealtimeResult, error) {
+	if !IsRealtimeTransport(transport) {
+		return nil, fmt.Errorf("unsupported transport: %s. Supported transports: ws, sse", transport)
+	}
+
+	moduleDir := rootDir
+	if _, err := os.Stat(filepath.Join(rootDir, "go.mod")); err != nil {
+		moduleDir = filepath.Join(rootDir, constants.APIDir)
+	}
+
+	moduleName, err := readModulePath(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	if dirExists(filepath.Join(moduleDir, filepath.FromSlash(realtimePackageDir))) {
+		return nil, fmt.Errorf("%s already exists in %s", realtimePackageDir, moduleDir)
+	}
+
+	if err := writeProjectFiles(moduleDir, pg.generateRealtimeFiles(moduleName, transport)); err != nil {
+		return nil, fmt.Errorf("failed to create realtime package: %w", err)
+	}
+
+	result := &RealtimeResult{ModuleDir: moduleDir}
+
+	routes := routeEdit{
+		routes:  []routeSpec{{method: "GET", path: RealtimeEndpoint(transport), handler: "realtime.Handler()"}},
+		imports: []string{realtimePackageDir},
+		hint:    "register realtime.Handler() on your router",
+	}
+	if err := registerRoutes(filepath.Join(moduleDir, filepath.FromSlash(routesFile)), moduleName, routes); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	} else {
+		result.RouteAdded = true
+	}
+
+	added, err := registerRealtimeShutdown(filepath.Join(moduleDir, "main.go"), moduleName)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	result.ShutdownAdded = added
+
+	origins := ""
+	frontendDir := filepath.Join(rootDir, constants.FrontendDir)
+	if moduleDir != rootDir && dirExists(filepath.Join(frontendDir, "src")) {
+		framework, useTypeScript := detectFrontend(frontendDir)
+		files := pg.generateRealtimeClientFiles(framework, transport, useTypeScript)
+		if err := writeProjectFiles(frontendDir, files); err != nil {
+			return nil, fmt.Errorf("failed to create realtime client: %w", err)
+		}
+		for _, name := range sortedKeys(files) {
+			result.ClientFiles = append(result.ClientFiles, filepath.Join(constants.FrontendDir, name))
+		}
+		origins = fmt.Sprintf("http://localhost:%d", frontendDevPort(framework))
+	}
+
+	for _, name := range []string{".env", ".env.example"} {
+		if err := appendEnvVar(filepath.Join(moduleDir, name), "REALTIME_ALLOWED_ORIGINS", origins); err != nil {
+			fmt.Printf("Warning: failed to update %s: %v\n", name, err)
+		}
+	}
+
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = moduleDir
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Warning: failed to run go mod tidy: %v\n", err)
+	}
+
+	return result, nil
+}
+
+// routeSpec is a route gogen add registers in SetupRoutes. A path ending in
+// /* matches everything below it, the handler reads the rest of the path.
+type routeSpec struct {
+	method  string
+	path    string
+	handler string
+}
+
+// routeEdit is what registerRoutes adds to SetupRoutes.
+type routeEdit struct {
+	routes []routeSpec
+	// stmts are added after the routes.
+	stmts []string
+	// imports are the directories in the module of the packages the routes
+	// and stmts use.
+	imports []string
+	// hint says what to register by hand when the file cannot be edited.
+	hint string
+}
+
+// registerRoutes adds the routes to SetupRoutes after the last route it
+// registers, in the style of the router the file uses.
+func registerRoutes(path, modulePath string, edit routeEdit) error {
+	source, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to read %s, %s: %w", path, edit.hint, err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, source, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var setup *ast.FuncDecl
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "SetupRoutes" {
+			setup = fn
+			break
+		}
+	}
+	if setup == nil || setup.Body == nil || setup.Type.Params.NumFields() != 0 {
+		return fmt.Errorf("no SetupRoutes() found in %s, %s", path, edit.hint)
+	}
+
+	stmts := setup.Body.List
+	router := ""
+	if n := len(stmts); n > 0 {
+		if ret, ok := stmts[n-1].(*ast.ReturnStmt); ok {
+			if len(ret.Results) != 1 {
+				return fmt.Errorf("unexpected return in SetupRoutes in %s, %s", path, edit.hint)
+			}
+			ident, ok := ret.Results[0].(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("unexpected return in SetupRoutes in %s, %s", path, edit.hint)
+			}
+			router = ident.Name
+			stmts = stmts[:n-1]
+		}
+	}
+
+	var route func(routeSpec) string
+	switch {
+	case router == "" && setup.Type.Results.NumFields() == 0:
+		route = func(rs routeSpec) string {
+			return fmt.Sprintf("http.Handle(%q, %s)", strings.TrimSuffix(rs.path, "*"), rs.handler)
+		}
+	case router == "":
+		return fmt.Errorf("SetupRoutes in %s does not return its router, %s", path, edit.hint)
+	case importsPrefix(file, "github.com/go-chi/chi"):
+		route = func(rs routeSpec) string {
+			return fmt.Sprintf("%s.Method(%s, %q, %s)", router, httpMethodConst(rs.method), rs.path, rs.handler)
+		}
+	case importsPrefix(file, "github.com/gorilla/mux"):
+		route = func(rs routeSpec) string {
+			p := rs.path
+			if prefix, ok := strings.CutSuffix(p, "*"); ok {
+				p = prefix + "{path:.+}"
+			}
+			return fmt.Sprintf("%s.Handle(%q, %s).Methods(%q)", router, p, rs.handler, rs.method)
+		}
+	case importsPrefix(file, "github.com/julienschmidt/httprouter"):
+		route = func(rs routeSpec) string {
+			p := rs.path
+			if strings.HasSuffix(p, "*") {
+				p += "path"
+			}
+			return fmt.Sprintf("%s.Handler(%s, %q, %s)", router, httpMethodConst(rs.method), p, rs.handler)
+		}
+	default:
+		return fmt.Errorf("unknown router in %s, %s", path, edit.hint)
+	}
+
+	// Routes that differ only in their method are a single route on the
+	// standard library mux, where the handler checks the method.
+	var text strings.Builder
+	added := make(map[string]bool)
+	for _, rs := range edit.routes {
+		line := route(rs)
+		if !added[line] {
+			added[line] = true
+			text.WriteString("\n\t" + line)
+		}
+	}
+	for _, stmt := range edit.stmts {
+		text.WriteString("\n\t" + stmt)
+	}
+
+	offset := fset.Position(setup.Body.Lbrace).Offset + 1
+	if len(stmts) > 0 {
+		offset = fset.Position(stmts[len(stmts)-1].End()).Offset
+	}
+	edits := []sourceEdit{{offset: offset, text: text.String()}}
+
+	if edit, needed := importEdit(fset, file, "net/http", true); needed {
+		edits = append(edits, edit)
+	}
+	for _, dir := range edit.imports {
+		if edit, needed := projectImportEdit(fset, file, modulePath, dir); needed {
+			edits = append(edits, edit)
+		}
+	}
+
+	formatted, err := format.Source(applyEdits(source, edits))
+	if err != nil {
+		return fmt.Errorf("failed to format %s after adding the routes: %w", path, err)
+	}
+
+	return os.WriteFile(path, formatted, 0600)
+}
+
+// httpMethodConst returns the net/http constant for method, such as
+// http.MethodGet for GET.
+func httpMethodConst(method string) string {
+	return "http.Method" + method[:1] + strings.ToLower(method[1:])
+}
+
+// registerRealtimeShutdown closes the hub from srv.RegisterOnShutdown when
+// main.go builds an http.Server named srv. It reports whether it did.
+func registerRealtimeShutdown(path, modulePath string) (bool, error) {
+	source, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return false, nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, source, parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var server ast.Stmt
+	ast.Inspect(file, func(n ast.Node) bool {
+		if server != nil {
+			return false
+		}
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return true
+		}
+		if ident, ok := assign.Lhs[0].(*ast.Ident); !ok || ident.Name != "srv" {
+			return true
+		}
+		unary, ok := assign.Rhs[0].(*ast.UnaryExpr)
+		if !ok {
+			return true
+		}
+		lit, ok := unary.X.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		if sel, ok := lit.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "Server" {
+			server = assign
+		}
+		return true
+	})
+	if server == nil {
+		return false, nil
+	}
+
+	edits := []sourceEdit{{offset: fset.Position(server.End()).Offset, text: "\n\tsrv.RegisterOnShutdown(realtime.Close)\n"}}
+	if edit, needed := projectImportEdit(fset, file, modulePath, realtimePackageDir); needed {
+		edits = append(edits, edit)
+	}
+
+	formatted, err := format.Source(applyEdits(source, edits))
+	if err != nil {
+		return false, fmt.Errorf("failed to format %s after adding the shutdown hook: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, formatted, 0600); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// projectImportEdit imports the package at dir in modulePath next to the
+// last import from the same module, so it joins that group.
+func projectImportEdit(fset *token.FileSet, file *ast.File, modulePath, dir string) (sourceEdit, bool) {
+	path := modulePath + "/" + dir
+	var last *ast.ImportSpec
+	for _, spec := range file.Imports {
+		value := strings.Trim(spec.Path.Value, `"`)
+		if value == path {
+			return sourceEdit{}, false
+		}
+		if strings.HasPrefix(value, modulePath+"/") {
+			last = spec
+		}
+	}
+	if last == nil {
+		return importEdit(fset, file, path, false)
+	}
+	return sourceEdit{offset: fset.Position(last.End()).Offset, text: "\n\t" + strconv.Quote(path)}, true
+}
+
+func importsPrefix(file *ast.File, prefix string) bool {
+	for _, spec := range file.Imports {
+		if strings.HasPrefix(strings.Trim(spec.Path.Value, `"`), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectFrontend reads the framework from the dependencies in package.json
+// and whether the frontend uses TypeScript from src/config.ts.
+func detectFrontend(frontendDir string) (string, bool) {
+	_, err := os.Stat(filepath.Join(frontendDir, "src", "config.ts"))
+	useTypeScript := err == nil
+	if _, err := os.Stat(filepath.Join(frontendDir, "tsconfig.json")); err == nil {
+		useTypeScript = true
+	}
+
+	content, err := os.ReadFile(filepath.Join(frontendDir, "package.json"))
+	if err != nil {
+		return "", useTypeScript
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return "", useTypeScript
+	}
+
+	has := func(name string) bool {
+		_, dep := pkg.Dependencies[name]
+		_, dev := pkg.DevDependencies[name]
+		return dep || dev
+	}
+	for _, candidate := range []struct{ dep, framework string }{
+		{"@angular/core", angular},
+		{"astro", astro},
+		{"@builder.io/qwik", qwik},
+		{"preact", preact},
+		{"react", react},
+		{"vue", vue},
+		{"svelte", svelte},
+		{"solid-js", solidjs},
+		{"lit", lit},
+	} {
+		if has(candidate.dep) {
+			return candidate.framework, useTypeScript
+		}
+	}
+	return "", useTypeScript
+}
+
+// appendEnvVar adds key to an env file that exists and does not set it yet.
+func appendEnvVar(path, key, value string) error {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), key+"=") {
+			return nil
+		}
+	}
+
+	env := string(content)
+	if env != "" && !strings.HasSuffix(env, "\n") {
+		env += "\n"
+	}
+	env += key + "=" + value + "\n"
+	return os.WriteFile(path, []byte(env), 0600)
+}
+
+func (pg *ProjectGenerator) generateRealtimeFiles(modulePath, transport string) map[string]string {
+	dir := filepath.FromSlash(realtimePackageDir)
+	files := map[string]string{
+		filepath.Join(dir, "hub.go"):      pg.generateRealtimeHubContent(modulePath, transport),
+		filepath.Join(dir, "hub_test.go"): pg.generateRealtimeHubTestContent(),
+	}
+	if transport == RealtimeSSE {
+		files[filepath.Join(dir, "sse.go")] = pg.generateRealtimeSSEContent(modulePath)
+		files[filepath.Join(dir, "sse_test.go")] = pg.generateRealtimeSSETestContent()
+	} else {
+		files[filepath.Join(dir, "ws.go")] = pg.generateRealtimeWebSocketContent(modulePath)
+		files[filepath.Join(dir, "ws_test.go")] = pg.generateRealtimeWebSocketTestContent()
+	}
+	return files
+}
+
+func (pg *ProjectGenerator) generateRealtimeHubContent(modulePath, transport string) string {
+	transportName := "WebSocket"
+	if transport == RealtimeSSE {
+		transportName = "Server-Sent Events"
+	}
+
+	return `// Package realtime pushes messages from the server to connected clients
+// over ` + transportName + ` on ` + RealtimeEndpoint(transport) + `.
+//
+// A Hub keeps the connected clients. Broadcast queues a message for every
+// client: each one has its own buffer of SendBuffer messages, and a client
+// that lets its buffer fill up is disconnected, so one slow connection
+// never holds up the others. Idle connections are kept alive with a ping
+// every 30 seconds. Shutdown closes every connection and answers new ones
+// with 503 Service Unavailable, main runs it through
+// srv.RegisterOnShutdown(realtime.Close).
+//
+// Handlers publish to the clients with the package-level functions:
+//
+//	realtime.Broadcast([]byte("hello"))
+//	realtime.BroadcastJSON(event)
+//
+// Browsers may connect from the API's own origin and from the origins
+// listed in REALTIME_ALLOWED_ORIGINS.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"` + modulePath + `/internal/httpx"
+)
+
+// SendBuffer is how many messages are queued for a client before it counts
+// as too slow and is disconnected.
+const SendBuffer = 64
+
+// ShutdownTimeout bounds how long Close waits for the connections to end.
+const ShutdownTimeout = 5 * time.Second
+
+const (
+	writeWait  = 10 * time.Second
+	pingPeriod = 30 * time.Second
+)
+
+var errClosed = errors.New("realtime: hub is shut down")
+
+type client struct {
+	send chan []byte
+	// dropped is closed when the hub disconnects the client for falling
+	// behind.
+	dropped chan struct{}
+}
+
+// Hub fans messages out to the connected clients.
+type Hub struct {
+	buffer int
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+	done    chan struct{}
+	conns   sync.WaitGroup
+}
+
+// NewHub returns a hub that queues up to buffer messages per client.
+func NewHub(buffer int) *Hub {
+	if buffer < 1 {
+		buffer = 1
+	}
+	return &Hub{
+		buffer:  buffer,
+		clients: make(map[*client]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Default is the hub behind the package-level functions and the endpoint
+// the routes register.
+var Default = NewHub(SendBuffer)
+
+// Broadcast queues msg for every client of the Default hub.
+func Broadcast(msg []byte) {
+	Default.Broadcast(msg)
+}
+
+// BroadcastJSON queues v, encoded as JSON, for every client of the Default
+// hub.
+func BroadcastJSON(v any) error {
+	return Default.BroadcastJSON(v)
+}
+
+// Handler serves the Default hub, allowing the origins in
+// REALTIME_ALLOWED_ORIGINS.
+func Handler() http.Handler {
+	return Default.Handler(AllowedOrigins()...)
+}
+
+// Close shuts the Default hub down.
+func Close() {
+	Default.Close()
+}
+
+// Broadcast queues msg for every connected client. Clients whose buffer is
+// full are disconnected, they reconnect and receive the messages sent from
+// then on.
+func (h *Hub) Broadcast(msg []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients {
+		select {
+		case c.send <- msg:
+		default:
+			delete(h.clients, c)
+			close(c.dropped)
+		}
+	}
+}
+
+// BroadcastJSON encodes v as JSON and broadcasts it.
+func (h *Hub) BroadcastJSON(v any) error {
+	msg, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	h.Broadcast(msg)
+	return nil
+}
+
+// Len reports the number of connected clients.
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Shutdown disconnects every client, refuses new ones and waits for the
+// connections to end or ctx to be done.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+	h.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		h.conns.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close shuts the hub down, waiting up to ShutdownTimeout. It fits
+// http.Server.RegisterOnShutdown.
+func (h *Hub) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	if err := h.Shutdown(ctx); err != nil {
+		slog.Warn("realtime connections still open after shutdown", "error", err)
+	}
+}
+
+// subscribe registers a client for a new connection, every successful call
+// has to be paired with unsubscribe.
+func (h *Hub) subscribe() (*client, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, errClosed
+	}
+	c := &client{
+		send:    make(chan []byte, h.buffer),
+		dropped: make(chan struct{}),
+	}
+	h.clients[c] = struct{}{}
+	h.conns.Add(1)
+	return c, nil
+}
+
+func (h *Hub) unsubscribe(c *client) {
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+	h.conns.Done()
+}
+
+// AllowedOrigins returns the comma separated origins in
+// REALTIME_ALLOWED_ORIGINS. "*" allows every origin.
+func AllowedOrigins() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("REALTIME_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// originAllowed accepts requests without an Origin header, which do not come
+// from browsers, from the server's own origin and from allowed.
+func originAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// unavailable answers connections that arrive during shutdown.
+func unavailable(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", "5")
+	httpx.WriteError(w, r, httpx.Errorf(http.StatusServiceUnavailable, "server is shutting down"))
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRealtimeWebSocketContent(modulePath string) string {
+	return `package realtime
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"` + modulePath + `/internal/httpx"
+)
+
+const (
+	// pongWait is how long a connection may go without answering a ping.
+	pongWait = 2 * pingPeriod
+
+	maxMessageSize = 4096
+)
+
+type wsHandler struct {
+	hub      *Hub
+	upgrader websocket.Upgrader
+}
+
+// Handler upgrades requests to WebSocket connections that receive the
+// hub's broadcasts. Browsers may connect from the server's own origin and
+// from allowedOrigins.
+func (h *Hub) Handler(allowedOrigins ...string) http.Handler {
+	return &wsHandler{
+		hub: h,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				return originAllowed(r, allowedOrigins)
+			},
+			Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+				httpx.WriteError(w, r, httpx.Errorf(status, "%s", reason))
+			},
+		},
+	}
+}
+
+func (s *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, err := s.hub.subscribe()
+	if err != nil {
+		unavailable(w, r)
+		return
+	}
+	defer s.hub.unsubscribe(c)
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has answered the request.
+		return
+	}
+
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		readMessages(conn)
+	}()
+
+	s.writeMessages(conn, c, readDone)
+	_ = conn.Close()
+	<-readDone
+}
+
+// readMessages handles pongs and the close handshake until the connection
+// fails. Messages from clients are discarded, handle them here to take
+// input over the socket.
+func readMessages(conn *websocket.Conn) {
+	conn.SetReadLimit(maxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writeMessages is the only writer of conn. It sends the queued messages
+// and the pings until the connection fails, the client is dropped or the
+// hub shuts down.
+func (s *wsHandler) writeMessages(conn *websocket.Conn, c *client, readDone <-chan struct{}) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-c.dropped:
+			closeConn(conn, websocket.CloseTryAgainLater, "client too slow")
+			return
+		case <-s.hub.done:
+			closeConn(conn, websocket.CloseGoingAway, "server shutting down")
+			return
+		case <-readDone:
+			return
+		}
+	}
+}
+
+// closeConn sends a close frame, clients reconnect when they receive it.
+func closeConn(conn *websocket.Conn, code int, text string) {
+	msg := websocket.FormatCloseMessage(code, text)
+	_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRealtimeSSEContent(modulePath string) string {
+	return `package realtime
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"` + modulePath + `/internal/httpx"
+)
+
+// retryMillis tells browsers how long to wait before reconnecting.
+const retryMillis = "3000"
+
+type sseHandler struct {
+	hub            *Hub
+	allowedOrigins []string
+}
+
+// Handler streams the hub's broadcasts as Server-Sent Events. Browsers may
+// connect from the server's own origin and from allowedOrigins.
+func (h *Hub) Handler(allowedOrigins ...string) http.Handler {
+	return &sseHandler{hub: h, allowedOrigins: allowedOrigins}
+}
+
+func (s *sseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		httpx.MethodNotAllowedHandler().ServeHTTP(w, r)
+		return
+	}
+	if !originAllowed(r, s.allowedOrigins) {
+		httpx.WriteError(w, r, httpx.Forbidden("origin not allowed"))
+		return
+	}
+
+	c, err := s.hub.subscribe()
+	if err != nil {
+		unavailable(w, r)
+		return
+	}
+	defer s.hub.unsubscribe(c)
+
+	// The stream outlives the server's WriteTimeout, every write sets its
+	// own deadline instead. The deadline is cleared again for the next
+	// request on the connection.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	defer func() { _ = rc.SetWriteDeadline(time.Time{}) }()
+
+	header := w.Header()
+	if origin := r.Header.Get("Origin"); origin != "" {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+	}
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	// Keeps proxies such as nginx from buffering the stream.
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := write(w, rc, "retry: "+retryMillis+"\n\n"); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			if err := write(w, rc, formatEvent(msg)); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// Comments keep idle connections open through proxies and
+			// surface clients that went away.
+			if err := write(w, rc, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-c.dropped:
+			return
+		case <-s.hub.done:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func write(w http.ResponseWriter, rc *http.ResponseController, event string) error {
+	_ = rc.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := io.WriteString(w, event); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// formatEvent turns msg into a message event, with a data line per line of
+// msg.
+func formatEvent(msg []byte) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(string(msg), "\r\n", "\n"), "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRealtimeHubTestContent() string {
+	return `package realtime
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// waitForClients waits until the connections made by a test have
+// subscribed to h.
+func waitForClients(t *testing.T, h *Hub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for h.Len() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("hub has %d clients, want %d", h.Len(), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	h := NewHub(4)
+	a, _ := h.subscribe()
+	b, _ := h.subscribe()
+
+	h.Broadcast([]byte("hello"))
+
+	for _, c := range []*client{a, b} {
+		if got := string(<-c.send); got != "hello" {
+			t.Errorf("got %q, want hello", got)
+		}
+	}
+}
+
+func TestBroadcastDropsSlowClients(t *testing.T) {
+	h := NewHub(1)
+	slow, _ := h.subscribe()
+	fast, _ := h.subscribe()
+
+	h.Broadcast([]byte("one"))
+	<-fast.send
+	h.Broadcast([]byte("two"))
+
+	select {
+	case <-slow.dropped:
+	default:
+		t.Fatal("slow client was not dropped")
+	}
+	select {
+	case <-fast.dropped:
+		t.Fatal("fast client was dropped")
+	default:
+	}
+	if got := string(<-fast.send); got != "two" {
+		t.Errorf("fast client got %q, want two", got)
+	}
+	if h.Len() != 1 {
+		t.Errorf("hub has %d clients, want 1", h.Len())
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	h := NewHub(1)
+	c, _ := h.subscribe()
+	go func() {
+		<-h.done
+		h.unsubscribe(c)
+	}()
+
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if _, err := h.subscribe(); !errors.Is(err, errClosed) {
+		t.Errorf("subscribe after shutdown: got %v, want %v", err, errClosed)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	h := NewHub(1)
+	c, _ := h.subscribe()
+	defer h.unsubscribe(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := h.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{"http://localhost:5173"}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"http://example.com", true},
+		{"http://localhost:5173", true},
+		{"http://evil.example", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://example.com/", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := originAllowed(r, allowed); got != tt.want {
+			t.Errorf("origin %q: got %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+	r.Header.Set("Origin", "http://evil.example")
+	if !originAllowed(r, []string{"*"}) {
+		t.Error("* does not allow every origin")
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRealtimeWebSocketTestContent() string {
+	return `package realtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func dial(t *testing.T, srv *httptest.Server, header http.Header) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), header)
+	if conn != nil {
+		t.Cleanup(func() { _ = conn.Close() })
+	}
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	return conn, resp, err
+}
+
+func TestWebSocketBroadcast(t *testing.T) {
+	h := NewHub(SendBuffer)
+	srv := httptest.NewServer(h.Handler())
+	t.Cleanup(srv.Close)
+
+	conn, _, err := dial(t, srv, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	waitForClients(t, h, 1)
+
+	h.Broadcast([]byte("hello"))
+
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(msg) != "hello" {
+		t.Errorf("got %q, want hello", msg)
+	}
+}
+
+func TestWebSocketShutdown(t *testing.T) {
+	h := NewHub(SendBuffer)
+	srv := httptest.NewServer(h.Handler())
+	t.Cleanup(srv.Close)
+
+	conn, _, err := dial(t, srv, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	waitForClients(t, h, 1)
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- h.Shutdown(context.Background()) }()
+
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Fatalf("got %v, want close %d", err, websocket.CloseGoingAway)
+	}
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	_, resp, err := dial(t, srv, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("dial after shutdown: got %v, want status %d", err, http.StatusServiceUnavailable)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	h := NewHub(SendBuffer)
+	srv := httptest.NewServer(h.Handler("http://localhost:5173"))
+	t.Cleanup(srv.Close)
+
+	_, resp, err := dial(t, srv, http.Header{"Origin": {"http://evil.example"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("disallowed origin: got %v, want status %d", err, http.StatusForbidden)
+	}
+
+	if _, _, err := dial(t, srv, http.Header{"Origin": {"http://localhost:5173"}}); err != nil {
+		t.Errorf("allowed origin: %v", err)
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRealtimeSSETestContent() string {
+	return `package realtime
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func connect(t *testing.T, srv *httptest.Server, origin string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// readEvent returns the lines of the next event, without the blank line
+// that ends it.
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if line == "\n" {
+			return lines
+		}
+		lines = append(lines, line[:len(line)-1])
+	}
+}
+
+func TestSSEBroadcast(t *testing.T) {
+	h := NewHub(SendBuffer)
+	srv := httptest.NewServer(h.Handler())
+	t.Cleanup(srv.Close)
+
+	resp, body := connect(t, srv, "")
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type: got %q, want text/event-stream", ct)
+	}
+	if got := readEvent(t, body); len(got) != 1 || got[0] != "retry: "+retryMillis {
+		t.Errorf("first event: got %q", got)
+	}
+	waitForClients(t, h, 1)
+
+	h.Broadcast([]byte("hello\nworld"))
+
+	got := readEvent(t, body)
+	if len(got) != 2 || got[0] != "data: hello" || got[1] != "data: world" {
+		t.Errorf("got %q, want data lines hello and world", got)
+	}
+}
+
+func TestSSEShutdown(t *testing.T) {
+	h := NewHub(SendBuffer)
+	srv := httptest.NewServer(h.Handler())
+	t.Cleanup(srv.Close)
+
+	_, body := connect(t, srv, "")
+	readEvent(t, body)
+	waitForClients(t, h, 1)
+
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if _, err := io.ReadAll(body); err != nil {
+		t.Fatalf("stream did not end cleanly: %v", err)
+	}
+
+	resp, _ := connect(t, srv, "")
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("after shutdown: got %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestSSEOrigin(t *testing.T) {
+	h := NewHub(SendBuffer)
+	srv := httptest.NewServer(h.Handler("http://localhost:5173"))
+	t.Cleanup(srv.Close)
+
+	resp, _ := connect(t, srv, "http://evil.example")
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("disallowed origin: got %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+
+	resp, _ = connect(t, srv, "http://localhost:5173")
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
+		t.Errorf("Access-Control-Allow-Origin: got %q", got)
+	}
+}
+`
+}
+
+// generateRealtimeClientFiles returns the framework-agnostic client in
+// src/realtime and a wrapper for the framework, relative to the frontend
+// directory. Frameworks without a wrapper use the client directly.
+func (pg *ProjectGenerator) generateRealtimeClientFiles(framework, transport string, useTypeScript bool) map[string]string {
+	ext := "js"
+	if useTypeScript {
+		ext = "ts"
+	}
+
+	files := map[string]string{
+		filepath.Join("src", "realtime."+ext): pg.generateRealtimeClientContent(transport, useTypeScript),
+	}
+
+	switch framework {
+	case react, preact:
+		files[filepath.Join("src", "useRealtime."+ext)] = pg.generateRealtimeReactContent(framework, useTypeScript)
+	case vue:
+		files[filepath.Join("src", "useRealtime."+ext)] = pg.generateRealtimeVueContent(useTypeScript)
+	case svelte:
+		files[filepath.Join("src", "realtimeStore."+ext)] = pg.generateRealtimeSvelteContent(useTypeScript)
+	case solidjs:
+		files[filepath.Join("src", "createRealtime."+ext)] = pg.generateRealtimeSolidContent(useTypeScript)
+	}
+
+	return files
+}
+
+func (pg *ProjectGenerator) generateRealtimeClientContent(transport string, useTypeScript bool) string {
+	var b strings.Builder
+
+	b.WriteString("import config from './config';\n\n")
+	if useTypeScript {
+		b.WriteString(`export type RealtimeStatus = 'connecting' | 'open' | 'closed';
+
+export interface RealtimeOptions {
+  onMessage: (data: string) => void;
+  onStatus?: (status: RealtimeStatus) => void;
+}
+
+`)
+	}
+
+	fmt.Fprintf(&b, `// REALTIME_PATH is the endpoint gogen add realtime registered on the API.
+export const REALTIME_PATH = '%s';
+
+const MIN_RETRY_DELAY = 1000;
+const MAX_RETRY_DELAY = 30000;
+
+`, RealtimeEndpoint(transport))
+
+	signature := "export function realtimeUrl() {\n"
+	if useTypeScript {
+		signature = "export function realtimeUrl(): string {\n"
+	}
+	b.WriteString(signature)
+	b.WriteString("  const url = new URL(REALTIME_PATH, config.apiUrl || window.location.origin);\n")
+	if transport == RealtimeWebSocket {
+		b.WriteString("  url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';\n")
+	}
+	b.WriteString("  return url.toString();\n}\n\n")
+
+	b.WriteString(`// connect opens a connection to the realtime endpoint and reopens it with
+// exponential backoff whenever it drops, until the returned function is
+// called.
+`)
+	if useTypeScript {
+		b.WriteString("export function connect({ onMessage, onStatus }: RealtimeOptions): () => void {\n")
+		b.WriteString("  let retries = 0;\n  let stopped = false;\n  let timer: ReturnType<typeof setTimeout> | undefined;\n")
+		if transport == RealtimeSSE {
+			b.WriteString("  let current: EventSource | undefined;\n\n")
+		} else {
+			b.WriteString("  let current: WebSocket | undefined;\n\n")
+		}
+	} else {
+		b.WriteString("export function connect({ onMessage, onStatus }) {\n")
+		b.WriteString("  let retries = 0;\n  let stopped = false;\n  let timer;\n  let current;\n\n")
+	}
+
+	b.WriteString(`  const retry = () => {
+    onStatus?.('closed');
+    if (stopped) return;
+    const delay = Math.min(MIN_RETRY_DELAY * 2 ** retries, MAX_RETRY_DELAY);
+    retries += 1;
+    timer = setTimeout(open, delay);
+  };
+
+`)
+
+	if transport == RealtimeSSE {
+		b.WriteString(`  const open = () => {
+    onStatus?.('connecting');
+    const source = new EventSource(realtimeUrl());
+    current = source;
+    source.onopen = () => {
+      retries = 0;
+      onStatus?.('open');
+    };
+    source.onmessage = (event) => onMessage(event.data);
+    source.onerror = () => {
+      // The browser reconnects by itself unless the server refused the
+      // stream, for example with 503 while shutting down.
+      if (source.readyState === EventSource.CLOSED) {
+        retry();
+      } else {
+        onStatus?.('connecting');
+      }
+    };
+  };
+
+  open();
+
+  return () => {
+    stopped = true;
+    clearTimeout(timer);
+    current?.close();
+  };
+}
+`)
+	} else {
+		b.WriteString(`  const open = () => {
+    onStatus?.('connecting');
+    const socket = new WebSocket(realtimeUrl());
+    current = socket;
+    socket.onopen = () => {
+      retries = 0;
+      onStatus?.('open');
+    };
+    socket.onmessage = (event) => onMessage(event.data);
+    socket.onclose = retry;
+  };
+
+  open();
+
+  return () => {
+    stopped = true;
+    clearTimeout(timer);
+    if (current) {
+      current.onclose = null;
+      current.close();
+    }
+  };
+}
+`)
+	}
+
+	return b.String()
+}
+
+func (pg *ProjectGenerator) generateRealtimeReactContent(framework string, useTypeScript bool) string {
+	hooks := "react"
+	if framework == preact {
+		hooks = "preact/hooks"
+	}
+
+	if useTypeScript {
+		return `import { useEffect, useState } from '` + hooks + `';
+import { connect, type RealtimeStatus } from './realtime';
+
+// useRealtime keeps a connection to the API's realtime endpoint open while
+// the component is mounted and returns the last message it received.
+export function useRealtime(): { lastMessage: string | null; status: RealtimeStatus } {
+  const [lastMessage, setLastMessage] = useState<string | null>(null);
+  const [status, setStatus] = useState<RealtimeStatus>('connecting');
+
+  useEffect(() => connect({ onMessage: setLastMessage, onStatus: setStatus }), []);
+
+  return { lastMessage, status };
+}
+`
+	}
+
+	return `import { useEffect, useState } from '` + hooks + `';
+import { connect } from './realtime';
+
+// useRealtime keeps a connection to the API's realtime endpoint open while
+// the component is mounted and returns the last message it received.
+export function useRealtime() {
+  const [lastMessage, setLastMessage] = useState(null);
+  const [status, setStatus] = useState('connecting');
+
+  useEffect(() => connect({ onMessage: setLastMessage, onStatus: setStatus }), []);
+
+  return { lastMessage, status };
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRealtimeVueContent(useTypeScript bool) string {
+	if useTypeScript {
+		return `import { onMounted, onUnmounted, ref } from 'vue';
+import { connect, type RealtimeStatus } from './realtime';
+
+// useRealtime keeps a connection to the API's realtime endpoint open while
+// the component is mounted and exposes the last message it received.
+export function useRealtime() {
+  const lastMessage = ref<string | null>(null);
+  const status = ref<RealtimeStatus>('connecting');
+  let disconnect: (() => void) | undefined;
+
+  onMounted(() => {
+    disconnect = connect({
+      onMessage: (data) => {
+        lastMessage.value = data;
+      },
+      onStatus: (next) => {
+        status.value = next;
+      },
+    });
+  });
+  onUnmounted(() => disconnect?.());
+
+  return { lastMessage, status };
+}
+`
+	}
+
+	return `import { onMounted, onUnmounted, ref } from 'vue';
+import { connect } from './realtime';
+
+// useRealtime keeps a connection to the API's realtime endpoint open while
+// the component is mounted and exposes the last message it received.
+export function useRealtime() {
+  const lastMessage = ref(null);
+  const status = ref('connecting');
+  let disconnect;
+
+  onMounted(() => {
+    disconnect = connect({
+      onMessage: (data) => {
+        lastMessage.value = data;
+      },
+      onStatus: (next) => {
+        status.value = next;
+      },
+    });
+  });
+  onUnmounted(() => disconnect?.());
+
+  return { lastMessage, status };
+}
+`
+}
+
+func (pg *ProjectGenerator) generateRealtimeSvelteContent(useTypeScript bool) string {
+	if useTypeScript {
+		return `import { readable } from 'svelte/store';
+import { connect, type RealtimeStatus } from './realtime';
+
+export interface RealtimeState {
+  lastMessage: string | null;
+  status: RealtimeStatus;
+}
+
+// realtime holds the last message from the API's realtime endpoint. It
+// connects when the first component subscribes and disconnects after the
+// last one unsubscribes.
+export const realtime = readable<RealtimeState>({ lastMessage: null, status: 'connecting' }, (set) => {
+  let state: RealtimeState = { lastMessage: null, status: 'connecting' };
+
+  return connect({
+    onMessage: (lastMessage) => set((state = { ...state, lastMessage })),
+    onStatus: (status) => set((state = { ...state, status })),
+  });
+});
+`
+	}
+
+	return `import { readable } from 'svelte/store';
+import { connect } from './realtime';
+
+// realtime holds the last message from the API's realtime endpoint. It
+// connects when the first component subscribes and disconnects after the
+// last one unsubscribes.
+export const realtime = readable({ lastMessage: null, status: 'connecting' }, (set) => {
+  let state = { lastMessage: null, status: 'connecting' };
+
+  return connect({
+    onMessage: (lastMessage) => set((state = { ...state, lastMessage })),
+    onStatus: (status) => set((state = { ...state, status })),
+  });
+});
+`
+}
+
+func (pg *ProjectGenerator) generateRealtimeSolidContent(useTypeScript bool) string {
+	if useTypeScript {
+		return `import { createSignal, onCleanup } from 'solid-js';
+import { connect, type RealtimeStatus } from './realtime';
+
+// createRealtime connects to the API's realtime endpoint for the lifetime of
+// the owning component and returns signals for the last message and the
+// connection status.
+export function createRealtime() {
+  const [lastMessage, setLastMessage] = createSignal<string | null>(null);
+  const [status, setStatus] = createSignal<RealtimeStatus>('connecting');
+
+  onCleanup(connect({ onMessage: (data) => setLastMessage(data), onStatus: setStatus }));
+
+  return { lastMessage, status };
+}
+`
+	}
+
+	return `import { createSignal, onCleanup } from 'solid-js';
+import { connect } from './realtime';
+
+// createRealtime connects to the API's realtime endpoint for the lifetime of
+// the owning component and returns signals for the last message and the
+// connection status.
+export function createRealtime() {
+  const [lastMessage, setLastMessage] = createSignal(null);
+  const [status, setStatus] = createSignal('connecting');
+
+  onCleanup(connect({ onMessage: (data) => setLastMessage(data), onStatus: setStatus }));
+
+  return { lastMessage, status };
+}
+`
+}
@@ -0,0 +1,2240 @@
+package internal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+const (
+	storagePackageDir = "internal/storage"
+	httpxPackageDir   = "internal/httpx"
+	healthPackageDir  = "internal/health"
+)
+
+// Versions of the S3 client the storage package uses and of the fake S3
+// server its tests run against when no MinIO is available.
+const (
+	minioVersion    = "v7.3.0"
+	gofakes3Version = "v1.2.0"
+)
+
+// localMinioEndpoint is where the minio compose service is published on the
+// host.
+const localMinioEndpoint = "http://localhost:9000"
+
+// IsStorageBackend reports whether backend is one gogen add storage can
+// configure.
+func IsStorageBackend(backend string) bool {
+	return backend == StorageLocal || backend == StorageS3
+}
+
+// StorageResult describes what AddStorage changed, for the instructions
+// printed afterwards.
+type StorageResult struct {
+	ModuleDir string
+	// RouteAdded is false when the upload routes could not be registered in
+	// cmd/web/routes.go and have to be added by hand.
+	RouteAdded bool
+	// ComposeUpdated is true when docker-compose.yml got the minio service
+	// or was pointed at it.
+	ComposeUpdated bool
+	// ClientFiles lists the frontend files written for web projects.
+	ClientFiles []string
+}
+
+// AddStorage adds internal/storage to the Go module in rootDir, or in api/
+// for web projects, registers its upload and download routes in
+// cmd/web/routes.go and configures backend in .env. With the s3 backend,
+// docker-compose.yml gets a minio service. Web projects also get an upload
+// component for their frontend framework.
+func (pg *ProjectGenerator) AddStorage(rootDir, backend string) (*StorageResult, error) {
+	if !IsStorageBackend(backend) {
+		return nil, fmt.Errorf("unsupported storage backend: %s. Supported backends: local, s3", backend)
+	}
+
+	moduleDir := rootDir
+	if _, err := os.Stat(filepath.Join(rootDir, "go.mod")); err != nil {
+		moduleDir = filepath.Join(rootDir, constants.APIDir)
+	}
+
+	moduleName, err := readModulePath(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	if dirExists(filepath.Join(moduleDir, filepath.FromSlash(storagePackageDir))) {
+		return nil, fmt.Errorf("%s already exists in %s", storagePackageDir, moduleDir)
+	}
+	// The handlers answer errors through httpx, which API projects have
+	// since gogen new generates them.
+	if !dirExists(filepath.Join(moduleDir, filepath.FromSlash(httpxPackageDir))) {
+		return nil, fmt.Errorf("%s not found in %s, gogen add storage needs an API or web project", httpxPackageDir, moduleDir)
+	}
+
+	if err := writeProjectFiles(moduleDir, pg.generateStorageFiles(moduleName)); err != nil {
+		return nil, fmt.Errorf("failed to create storage package: %w", err)
+	}
+
+	result := &StorageResult{ModuleDir: moduleDir}
+
+	routes := routeEdit{
+		routes: []routeSpec{
+			{method: "POST", path: "/uploads", handler: "storage.UploadHandler()"},
+			{method: "OPTIONS", path: "/uploads", handler: "storage.UploadHandler()"},
+			{method: "GET", path: "/uploads/*", handler: "storage.URLHandler()"},
+			{method: "GET", path: "/files/*", handler: "storage.DownloadHandler()"},
+		},
+		stmts:   []string{`health.Register("storage", 0, storage.Check)`},
+		imports: []string{healthPackageDir, storagePackageDir},
+		hint:    "register storage.UploadHandler(), storage.URLHandler() and storage.DownloadHandler() on your router",
+	}
+	if err := registerRoutes(filepath.Join(moduleDir, filepath.FromSlash(routesFile)), moduleName, routes); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	} else {
+		result.RouteAdded = true
+	}
+
+	origins := ""
+	frontendDir := filepath.Join(rootDir, constants.FrontendDir)
+	if moduleDir != rootDir && dirExists(filepath.Join(frontendDir, "src")) {
+		framework, useTypeScript := detectFrontend(frontendDir)
+		files := pg.generateStorageClientFiles(framework, useTypeScript)
+		if err := writeProjectFiles(frontendDir, files); err != nil {
+			return nil, fmt.Errorf("failed to create upload component: %w", err)
+		}
+		for _, name := range sortedKeys(files) {
+			result.ClientFiles = append(result.ClientFiles, filepath.Join(constants.FrontendDir, name))
+		}
+		origins = fmt.Sprintf("http://localhost:%d", frontendDevPort(framework))
+	}
+
+	signingKey := make([]byte, 32)
+	if _, err := rand.Read(signingKey); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	for _, name := range []string{".env", ".env.example"} {
+		env := storageEnv(backend, origins)
+		if name == ".env" {
+			env = append(env, [2]string{"STORAGE_SIGNING_KEY", hex.EncodeToString(signingKey)})
+		} else {
+			env = append(env, [2]string{"STORAGE_SIGNING_KEY", ""})
+		}
+		for _, kv := range env {
+			if err := appendEnvVar(filepath.Join(moduleDir, name), kv[0], kv[1]); err != nil {
+				fmt.Printf("Warning: failed to update %s: %v\n", name, err)
+				break
+			}
+		}
+	}
+
+	if err := appendGitignore(moduleDir, "uploads/"); err != nil {
+		fmt.Printf("Warning: failed to update .gitignore: %v\n", err)
+	}
+
+	if backend == StorageS3 {
+		updated, err := addComposeStorage(rootDir)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		result.ComposeUpdated = updated
+	}
+
+	for _, dependency := range []string{
+		"github.com/minio/minio-go/v7@" + minioVersion,
+		"github.com/johannesboyne/gofakes3@" + gofakes3Version,
+	} {
+		cmd := exec.Command("go", "get", dependency)
+		cmd.Dir = moduleDir
+		if err := cmd.Run(); err != nil {
+			fmt.Printf("Warning: failed to add %s: %v\n", dependency, err)
+		}
+	}
+
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = moduleDir
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Warning: failed to run go mod tidy: %v\n", err)
+	}
+
+	return result, nil
+}
+
+// appendGitignore adds pattern to the .gitignore in dir when there is one
+// that does not list it yet.
+func appendGitignore(dir, pattern string) error {
+	path := filepath.Join(dir, ".gitignore")
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	ignore := string(content)
+	if ignore != "" && !strings.HasSuffix(ignore, "\n") {
+		ignore += "\n"
+	}
+	return os.WriteFile(path, []byte(ignore+pattern+"\n"), 0600)
+}
+
+// storageEnv is the configuration AddStorage adds to .env for backend. The
+// S3 variables point at the minio compose service as seen from the host.
+func storageEnv(backend, origins string) [][2]string {
+	env := [][2]string{
+		{"STORAGE_BACKEND", backend},
+		{"STORAGE_ALLOWED_ORIGINS", origins},
+	}
+	if backend == StorageS3 {
+		env = append(env,
+			[2]string{"S3_ENDPOINT", localMinioEndpoint},
+			[2]string{"S3_BUCKET", "uploads"},
+			[2]string{"S3_ACCESS_KEY", "minioadmin"},
+			[2]string{"S3_SECRET_KEY", "minioadmin"},
+		)
+	}
+	return env
+}
+
+// addComposeStorage adds the minio service to docker-compose.yml in rootDir
+// when it has none, and points the api and worker services at it. It
+// reports whether it changed the file.
+func addComposeStorage(rootDir string) (bool, error) {
+	composePath := filepath.Join(rootDir, "docker-compose.yml")
+	content, err := os.ReadFile(filepath.Clean(composePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read docker-compose.yml: %w", err)
+	}
+	compose := string(content)
+	if !strings.Contains(compose, "\n  api:\n") {
+		return false, nil
+	}
+
+	cc := &ComposeConfig{}
+	for _, line := range strings.Split(compose, "\n") {
+		if name, ok := strings.CutPrefix(line, "name: "); ok {
+			cc.ProjectName = strings.TrimSpace(name)
+			break
+		}
+	}
+	minio, err := cc.extraService(ServiceMinio)
+	if err != nil {
+		return false, err
+	}
+
+	// Browsers download from presigned URLs, which have to name the host
+	// they reach minio at rather than the compose network name.
+	env := []string{"STORAGE_BACKEND=s3", "S3_PUBLIC_ENDPOINT=" + localMinioEndpoint}
+	hasMinio := strings.Contains(compose, "\n  "+ServiceMinio+":\n")
+	if !hasMinio {
+		index := strings.Index(compose, "\nnetworks:\n")
+		if index == -1 {
+			return false, fmt.Errorf("failed to find the top-level networks key in docker-compose.yml")
+		}
+		compose = compose[:index] + "\n" + minio.definition + compose[index:]
+
+		volume := fmt.Sprintf("  %s:\n    driver: local\n", minio.volume)
+		if !strings.HasSuffix(compose, "\n") {
+			compose += "\n"
+		}
+		if strings.Contains(compose, "\nvolumes:\n") {
+			compose += volume
+		} else {
+			compose += "\nvolumes:\n" + volume
+		}
+		env = append(minio.apiEnv, env...)
+	}
+
+	lines := strings.Split(compose, "\n")
+	for _, service := range []string{"api", "worker"} {
+		lines = addComposeServiceEnv(lines, service, env)
+		if !hasMinio {
+			lines = addComposeDependency(lines, service, ServiceMinio)
+		}
+	}
+
+	if err := os.WriteFile(composePath, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		return false, fmt.Errorf("failed to update docker-compose.yml: %w", err)
+	}
+	return true, nil
+}
+
+// composeServiceRange returns the range [start, end) of the lines of service
+// in the services of a docker-compose.yml, or -1 when it has none.
+func composeServiceRange(lines []string, service string) (int, int) {
+	start := -1
+	for i, line := range lines {
+		if line == "  "+service+":" {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return -1, -1
+	}
+	end := start + 1
+	for end < len(lines) && (lines[end] == "" || strings.HasPrefix(lines[end], "    ")) {
+		end++
+	}
+	// Trailing blank lines separate the service from the next one.
+	for end > start+1 && lines[end-1] == "" {
+		end--
+	}
+	return start, end
+}
+
+// composeKeyRange returns the range [start, end) of the entries under key in
+// the lines of a service, or -1 when the service has no such key.
+func composeKeyRange(lines []string, start, end int, key string) (int, int) {
+	for i := start + 1; i < end; i++ {
+		if lines[i] == "    "+key+":" {
+			j := i + 1
+			for j < end && strings.HasPrefix(lines[j], "      ") {
+				j++
+			}
+			return i + 1, j
+		}
+	}
+	return -1, -1
+}
+
+// addComposeServiceEnv adds the variables in env that service does not set
+// yet to its environment list.
+func addComposeServiceEnv(lines []string, service string, env []string) []string {
+	start, end := composeServiceRange(lines, service)
+	if start == -1 {
+		return lines
+	}
+	first, last := composeKeyRange(lines, start, end, "environment")
+	if first == -1 {
+		return lines
+	}
+
+	var added []string
+	for _, e := range env {
+		key, _, _ := strings.Cut(e, "=")
+		set := false
+		for _, line := range lines[first:last] {
+			if strings.HasPrefix(line, "      - "+key+"=") {
+				set = true
+				break
+			}
+		}
+		if !set {
+			added = append(added, "      - "+e)
+		}
+	}
+	return insertLines(lines, last, added...)
+}
+
+// addComposeDependency makes service wait for dependency to be healthy.
+func addComposeDependency(lines []string, service, dependency string) []string {
+	start, end := composeServiceRange(lines, service)
+	if start == -1 {
+		return lines
+	}
+	entry := []string{"      " + dependency + ":", "        condition: service_healthy"}
+	if _, last := composeKeyRange(lines, start, end, "depends_on"); last != -1 {
+		return insertLines(lines, last, entry...)
+	}
+	for i := start + 1; i < end; i++ {
+		if lines[i] == "    networks:" {
+			return insertLines(lines, i, append([]string{"    depends_on:"}, entry...)...)
+		}
+	}
+	return lines
+}
+
+func insertLines(lines []string, at int, inserted ...string) []string {
+	result := make([]string, 0, len(lines)+len(inserted))
+	result = append(result, lines[:at]...)
+	result = append(result, inserted...)
+	return append(result, lines[at:]...)
+}
+
+func (pg *ProjectGenerator) generateStorageFiles(modulePath string) map[string]string {
+	dir := filepath.FromSlash(storagePackageDir)
+	return map[string]string{
+		filepath.Join(dir, "storage.go"):       pg.generateStorageContent(),
+		filepath.Join(dir, "local.go"):         pg.generateStorageLocalContent(),
+		filepath.Join(dir, "s3.go"):            pg.generateStorageS3Content(),
+		filepath.Join(dir, "config.go"):        pg.generateStorageConfigContent(),
+		filepath.Join(dir, "handlers.go"):      pg.generateStorageHandlersContent(modulePath),
+		filepath.Join(dir, "storage_test.go"):  pg.generateStorageTestContent(),
+		filepath.Join(dir, "handlers_test.go"): pg.generateStorageHandlersTestContent(modulePath),
+	}
+}
+
+func (pg *ProjectGenerator) generateStorageContent() string {
+	return `// Package storage keeps uploaded files on the local disk or in an
+// S3-compatible object store such as AWS S3 or MinIO, and serves them
+// through signed URLs that expire.
+//
+// Handlers use the Storage FromEnv configures through Default, or take a
+// Storage of their own:
+//
+//	files, err := storage.Default()
+//	if err != nil {
+//		return err
+//	}
+//	key, err := storage.NewKey(".pdf")
+//	if err != nil {
+//		return err
+//	}
+//	if err := files.Store.Put(ctx, key, r, size, "application/pdf"); err != nil {
+//		return err
+//	}
+//	url, err := files.Store.SignedURL(ctx, key, 15*time.Minute)
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned for keys that hold no object.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrInvalidKey is returned for keys that are empty or leave the store, such
+// as ../secret.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Object describes a stored file.
+type Object struct {
+	Key         string    ` + "`" + `json:"key"` + "`" + `
+	Size        int64     ` + "`" + `json:"size"` + "`" + `
+	ContentType string    ` + "`" + `json:"content_type"` + "`" + `
+	ModTime     time.Time ` + "`" + `json:"mod_time"` + "`" + `
+}
+
+// Storage stores files under keys. Keys are slash-separated paths such as
+// avatars/42.png.
+type Storage interface {
+	// Put stores the content of r under key, replacing any previous object.
+	// size is -1 when it is not known in advance.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object under key. The caller closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	// Stat describes the object under key without reading it.
+	Stat(ctx context.Context, key string) (Object, error)
+	// Delete removes the object under key. Deleting a missing key is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL anyone can download the object from until ttl
+	// has passed.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// Check reports whether the store is usable, for the readiness probe.
+	Check(ctx context.Context) error
+}
+
+// NewKey returns a random key ending in ext, so uploads never overwrite each
+// other and their keys cannot be guessed.
+func NewKey(ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return hex.EncodeToString(b) + ext, nil
+}
+
+// validKey rejects keys that are empty, absolute or climb out of the store.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageLocalContent() string {
+	return `package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DownloadPath is where the routes serve the files of a Local store.
+const DownloadPath = "/files/"
+
+// ErrInvalidSignature is returned for download URLs that were not signed by
+// the store or have expired.
+var ErrInvalidSignature = errors.New("storage: invalid or expired signature")
+
+// Local keeps files in a directory on disk. Its signed URLs point at
+// DownloadPath on the API, which checks the signature before serving the
+// file. Content types are derived from the extension of the key.
+type Local struct {
+	dir     string
+	baseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewLocal returns a store that keeps files in dir, creating it if needed.
+// baseURL is the public URL of the API, such as https://api.example.com,
+// and secret the key download URLs are signed with.
+func NewLocal(dir, baseURL string, secret []byte) (*Local, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("storage: local store needs a signing secret")
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return &Local{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+		now:     time.Now,
+	}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes r to a temporary file next to the object and renames it into
+// place, so readers never see a partial file.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file for %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, contextReader{ctx: ctx, r: r}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	f, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return nil, Object{}, notFound(key, err)
+	}
+	obj, err := l.describe(key, f)
+	if err != nil {
+		f.Close()
+		return nil, Object{}, err
+	}
+	return f, obj, nil
+}
+
+func (l *Local) Stat(ctx context.Context, key string) (Object, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return Object{}, notFound(key, err)
+	}
+	if info.IsDir() {
+		return Object{}, ErrNotFound
+	}
+	return l.object(key, info), nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// SignedURL returns a DownloadPath URL on the API with the expiry and an
+// HMAC of the key and expiry in the query.
+func (l *Local) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(l.now().Add(ttl).Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {l.sign(key, expires)},
+	}
+	return l.baseURL + DownloadPath + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// Verify checks the expiry and signature SignedURL put in query.
+func (l *Local) Verify(key string, query url.Values) error {
+	expires := query.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || l.now().Unix() > unix {
+		return ErrInvalidSignature
+	}
+	signature := query.Get("signature")
+	if !hmac.Equal([]byte(signature), []byte(l.sign(key, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Check reports whether the directory is still there.
+func (l *Local) Check(ctx context.Context) error {
+	info, err := os.Stat(l.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", l.dir)
+	}
+	return nil
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (l *Local) describe(key string, f *os.File) (Object, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return Object{}, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	if info.IsDir() {
+		return Object{}, ErrNotFound
+	}
+	return l.object(key, info), nil
+}
+
+func (l *Local) object(key string, info fs.FileInfo) Object {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: contentType,
+		ModTime:     info.ModTime(),
+	}
+}
+
+func notFound(key string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return fmt.Errorf("failed to open %s: %w", key, err)
+}
+
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// contextReader stops a copy once ctx is done, for example when the client
+// of an upload goes away.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageS3Content() string {
+	return `package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// partSize is the part size of multipart uploads of unknown size, the
+// smallest S3 accepts. The client buffers one part in memory per upload.
+const partSize = 5 << 20
+
+// S3Config configures an S3 store.
+type S3Config struct {
+	// Endpoint is the URL of an S3-compatible server such as MinIO, for
+	// example http://localhost:9000. Empty means AWS S3.
+	Endpoint string
+	// PublicEndpoint is the URL browsers reach the server at, when it
+	// differs from Endpoint, for example because the API reaches MinIO
+	// through the docker compose network. Signed URLs use it.
+	PublicEndpoint string
+	Region         string
+	Bucket         string
+	AccessKey      string
+	SecretKey      string
+	// Transport is the HTTP transport of the client, http.DefaultTransport
+	// when nil.
+	Transport http.RoundTripper
+}
+
+// S3 keeps files in a bucket of an S3-compatible object store. The bucket
+// is created on the first upload when it does not exist. Signed URLs are
+// presigned GET requests the object store serves itself.
+type S3 struct {
+	client  *minio.Client
+	signer  *minio.Client
+	bucket  string
+	region  string
+	mu      sync.Mutex
+	created bool
+}
+
+// NewS3 returns a store for cfg. It does not contact the server.
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("storage: S3 store needs a bucket")
+	}
+	client, err := newMinioClient(cfg.Endpoint, cfg)
+	if err != nil {
+		return nil, err
+	}
+	signer := client
+	if cfg.PublicEndpoint != "" {
+		if signer, err = newMinioClient(cfg.PublicEndpoint, cfg); err != nil {
+			return nil, err
+		}
+	}
+	return &S3{client: client, signer: signer, bucket: cfg.Bucket, region: cfg.Region}, nil
+}
+
+func newMinioClient(endpoint string, cfg S3Config) (*minio.Client, error) {
+	opts := &minio.Options{
+		Creds:     credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:    true,
+		Transport: cfg.Transport,
+		// Presigning needs the region, setting it saves a lookup.
+		Region: cfg.Region,
+	}
+	host := "s3.amazonaws.com"
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("storage: invalid S3 endpoint %q", endpoint)
+		}
+		host = u.Host
+		opts.Secure = u.Scheme == "https"
+		// Servers like MinIO serve buckets under the path rather than as
+		// subdomains.
+		opts.BucketLookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(host, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client for %s: %w", host, err)
+	}
+	return client, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	if err := s.ensureBucket(ctx); err != nil {
+		return err
+	}
+	opts := minio.PutObjectOptions{ContentType: contentType}
+	if size < 0 {
+		opts.PartSize = partSize
+	}
+	if _, err := s.client.PutObject(ctx, s.bucket, key, r, size, opts); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	if err := validKey(key); err != nil {
+		return nil, Object{}, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, Object{}, s.error(key, err)
+	}
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, Object{}, s.error(key, err)
+	}
+	return obj, object(info), nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (Object, error) {
+	if err := validKey(key); err != nil {
+		return Object{}, err
+	}
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return Object{}, s.error(key, err)
+	}
+	return object(info), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != "NoSuchBucket" {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	u, err := s.signer.PresignedGetObject(ctx, s.bucket, key, ttl, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign URL for %s: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// Check reports whether the server answers. A missing bucket is fine, the
+// first upload creates it.
+func (s *S3) Check(ctx context.Context) error {
+	_, err := s.client.BucketExists(ctx, s.bucket)
+	return err
+}
+
+func (s *S3) ensureBucket(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.created {
+		return nil
+	}
+
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("failed to look up bucket %s: %w", s.bucket, err)
+	}
+	if !exists {
+		err := s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{Region: s.region})
+		if err != nil && minio.ToErrorResponse(err).Code != "BucketAlreadyOwnedByYou" {
+			return fmt.Errorf("failed to create bucket %s: %w", s.bucket, err)
+		}
+	}
+	s.created = true
+	return nil
+}
+
+func (s *S3) error(key string, err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return ErrNotFound
+	}
+	return fmt.Errorf("failed to read %s: %w", key, err)
+}
+
+func object(info minio.ObjectInfo) Object {
+	return Object{
+		Key:         info.Key,
+		Size:        info.Size,
+		ContentType: info.ContentType,
+		ModTime:     info.LastModified,
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageConfigContent() string {
+	return `package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+const (
+	// DefaultMaxUploadSize is the largest upload accepted when
+	// STORAGE_MAX_UPLOAD_SIZE is not set, 10 MiB.
+	DefaultMaxUploadSize = 10 << 20
+	// DefaultURLTTL is how long signed URLs work when STORAGE_URL_TTL is
+	// not set.
+	DefaultURLTTL = 15 * time.Minute
+)
+
+// DefaultAllowedTypes are the content types uploads may have when
+// STORAGE_ALLOWED_TYPES is not set.
+var DefaultAllowedTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf"}
+
+// Files is a Storage together with the limits the upload handlers enforce.
+type Files struct {
+	Store Storage
+	// MaxUploadSize is the largest file Upload accepts, in bytes.
+	MaxUploadSize int64
+	// AllowedTypes are the content types Upload accepts, as sniffed from
+	// the content rather than taken from the client.
+	AllowedTypes []string
+	// URLTTL is how long the signed URLs the handlers return work.
+	URLTTL time.Duration
+	// AllowedOrigins are the origins of frontends served from elsewhere
+	// than the API, such as the Vite dev server, that may upload files.
+	AllowedOrigins []string
+}
+
+// FromEnv builds Files from these variables:
+//
+//	STORAGE_BACKEND          local (default) or s3
+//	STORAGE_LOCAL_DIR        directory of the local backend, uploads by default
+//	STORAGE_PUBLIC_URL       URL of the API in local download URLs, http://localhost:$PORT by default
+//	STORAGE_SIGNING_KEY      secret local download URLs are signed with
+//	STORAGE_MAX_UPLOAD_SIZE  largest upload in bytes, 10 MiB by default
+//	STORAGE_ALLOWED_TYPES    comma-separated content types uploads may have
+//	STORAGE_URL_TTL          lifetime of signed URLs, 15m by default
+//	STORAGE_ALLOWED_ORIGINS  comma-separated origins of frontends that may upload
+//	S3_ENDPOINT              URL of an S3-compatible server, AWS S3 when empty
+//	S3_PUBLIC_ENDPOINT       URL browsers reach that server at, S3_ENDPOINT by default
+//	S3_REGION                us-east-1 by default
+//	S3_BUCKET                uploads by default
+//	S3_ACCESS_KEY, S3_SECRET_KEY
+func FromEnv() (*Files, error) {
+	files := &Files{
+		MaxUploadSize:  DefaultMaxUploadSize,
+		AllowedTypes:   DefaultAllowedTypes,
+		URLTTL:         DefaultURLTTL,
+		AllowedOrigins: splitList(os.Getenv("STORAGE_ALLOWED_ORIGINS")),
+	}
+
+	if v := os.Getenv("STORAGE_MAX_UPLOAD_SIZE"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid STORAGE_MAX_UPLOAD_SIZE %q: want a positive number of bytes", v)
+		}
+		files.MaxUploadSize = n
+	}
+	if v := os.Getenv("STORAGE_ALLOWED_TYPES"); v != "" {
+		files.AllowedTypes = splitList(v)
+	}
+	if v := os.Getenv("STORAGE_URL_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid STORAGE_URL_TTL %q: want a duration such as 15m", v)
+		}
+		files.URLTTL = ttl
+	}
+
+	store, err := storeFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	files.Store = store
+	return files, nil
+}
+
+func storeFromEnv() (Storage, error) {
+	switch backend := envOr("STORAGE_BACKEND", BackendLocal); backend {
+	case BackendLocal:
+		secret := []byte(os.Getenv("STORAGE_SIGNING_KEY"))
+		if len(secret) == 0 {
+			slog.Warn("STORAGE_SIGNING_KEY is not set, download URLs stop working when the API restarts")
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, fmt.Errorf("failed to generate signing key: %w", err)
+			}
+		}
+		baseURL := envOr("STORAGE_PUBLIC_URL", "http://localhost:"+envOr("PORT", "8080"))
+		return NewLocal(envOr("STORAGE_LOCAL_DIR", "uploads"), baseURL, secret)
+	case BackendS3:
+		return NewS3(S3Config{
+			Endpoint:       os.Getenv("S3_ENDPOINT"),
+			PublicEndpoint: os.Getenv("S3_PUBLIC_ENDPOINT"),
+			Region:         envOr("S3_REGION", "us-east-1"),
+			Bucket:         envOr("S3_BUCKET", "uploads"),
+			AccessKey:      os.Getenv("S3_ACCESS_KEY"),
+			SecretKey:      os.Getenv("S3_SECRET_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported STORAGE_BACKEND %q: want local or s3", backend)
+	}
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultFiles *Files
+	defaultErr   error
+)
+
+// Default returns the Files FromEnv configures. They are built on first use,
+// after main has loaded .env.
+func Default() (*Files, error) {
+	defaultOnce.Do(func() {
+		defaultFiles, defaultErr = FromEnv()
+	})
+	return defaultFiles, defaultErr
+}
+
+// Check reports whether the Default store is usable, for the readiness
+// probe.
+func Check(ctx context.Context) error {
+	files, err := Default()
+	if err != nil {
+		return err
+	}
+	return files.Store.Check(ctx)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageHandlersContent(modulePath string) string {
+	return `package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"` + modulePath + `/internal/httpx"
+)
+
+// UploadPath is where the routes accept uploads and serve their URLs under.
+const UploadPath = "/uploads"
+
+// formOverhead is how much larger than MaxUploadSize a multipart body may be,
+// for the part headers and boundaries.
+const formOverhead = 64 << 10
+
+// sniffLen is how many bytes http.DetectContentType looks at.
+const sniffLen = 512
+
+var errTooLarge = errors.New("storage: upload too large")
+
+// extensions are the key extensions of common upload types, picked over
+// the first of mime.ExtensionsByType, which is not always the usual one.
+var extensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+// UploadResponse is the JSON body Upload answers with.
+type UploadResponse struct {
+	Object
+	URL       string    ` + "`" + `json:"url"` + "`" + `
+	ExpiresAt time.Time ` + "`" + `json:"expires_at"` + "`" + `
+}
+
+// UploadHandler serves Upload for the Default files.
+func UploadHandler() http.Handler {
+	return defaultHandler((*Files).Upload)
+}
+
+// URLHandler serves URL for the Default files.
+func URLHandler() http.Handler {
+	return defaultHandler((*Files).URL)
+}
+
+// DownloadHandler serves Download for the Default files.
+func DownloadHandler() http.Handler {
+	return defaultHandler((*Files).Download)
+}
+
+func defaultHandler(h func(*Files, http.ResponseWriter, *http.Request) error) http.Handler {
+	return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		files, err := Default()
+		if err != nil {
+			return err
+		}
+		return h(files, w, r)
+	})
+}
+
+// Upload stores the "file" field of a multipart/form-data POST under a new
+// random key and answers 201 with the object and a signed URL. The content
+// type is sniffed from the first bytes of the file, files of other types
+// than AllowedTypes get 415 and files larger than MaxUploadSize get 413.
+// It answers the CORS preflight of browsers at AllowedOrigins.
+func (f *Files) Upload(w http.ResponseWriter, r *http.Request) error {
+	allowed := f.allowOrigin(w, r)
+	if r.Method == http.MethodOptions && allowed {
+		w.Header().Set("Access-Control-Allow-Methods", http.MethodPost)
+		w.Header().Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		return httpx.Errorf(http.StatusMethodNotAllowed, "method %s is not allowed on %s", r.Method, r.URL.Path)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, f.MaxUploadSize+formOverhead)
+	form, err := r.MultipartReader()
+	if err != nil {
+		return httpx.Errorf(http.StatusUnsupportedMediaType, "Content-Type must be multipart/form-data")
+	}
+
+	for {
+		part, err := form.NextPart()
+		if errors.Is(err, io.EOF) {
+			return httpx.BadRequest("the form has no file field")
+		}
+		if err != nil {
+			return f.readError(err)
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		resp, err := f.store(r, part)
+		part.Close()
+		if err != nil {
+			return err
+		}
+		return httpx.Encode(w, http.StatusCreated, resp)
+	}
+}
+
+func (f *Files) store(r *http.Request, part io.Reader) (*UploadResponse, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, f.readError(err)
+	}
+	if n == 0 {
+		return nil, httpx.BadRequest("the file is empty")
+	}
+	head = head[:n]
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !slices.Contains(f.AllowedTypes, contentType) {
+		return nil, httpx.Errorf(http.StatusUnsupportedMediaType, "files of type %s are not allowed, allowed types: %s", contentType, strings.Join(f.AllowedTypes, ", "))
+	}
+
+	key, err := NewKey(extension(contentType))
+	if err != nil {
+		return nil, err
+	}
+	body := &limitReader{r: io.MultiReader(bytes.NewReader(head), part), limit: f.MaxUploadSize}
+	if err := f.Store.Put(r.Context(), key, body, -1, contentType); err != nil {
+		// Stores do not all wrap the errors of the reader.
+		if body.read > body.limit {
+			return nil, f.readError(errTooLarge)
+		}
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, f.readError(err)
+		}
+		return nil, err
+	}
+
+	url, expiresAt, err := f.sign(r, key)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadResponse{
+		Object: Object{
+			Key:         key,
+			Size:        body.read,
+			ContentType: contentType,
+			ModTime:     time.Now().UTC(),
+		},
+		URL:       url,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// URL answers GET /uploads/{key} with the object and a fresh signed URL.
+func (f *Files) URL(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		return httpx.Errorf(http.StatusMethodNotAllowed, "method %s is not allowed on %s", r.Method, r.URL.Path)
+	}
+	f.allowOrigin(w, r)
+
+	key := strings.TrimPrefix(r.URL.Path, UploadPath+"/")
+	obj, err := f.Store.Stat(r.Context(), key)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidKey) {
+		return httpx.NotFound("no file %s", key)
+	}
+	if err != nil {
+		return err
+	}
+
+	url, expiresAt, err := f.sign(r, key)
+	if err != nil {
+		return err
+	}
+	return httpx.Encode(w, http.StatusOK, UploadResponse{Object: obj, URL: url, ExpiresAt: expiresAt})
+}
+
+// Download serves the files of a Local store at their signed URLs. Other
+// stores serve their signed URLs themselves, so it answers 404 for them.
+func (f *Files) Download(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		return httpx.Errorf(http.StatusMethodNotAllowed, "method %s is not allowed on %s", r.Method, r.URL.Path)
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, DownloadPath)
+	local, ok := f.Store.(*Local)
+	if !ok {
+		return httpx.NotFound("no file %s", key)
+	}
+	if err := local.Verify(key, r.URL.Query()); err != nil {
+		return httpx.Forbidden("the download URL is invalid or has expired")
+	}
+
+	file, obj, err := local.Get(r.Context(), key)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidKey) {
+		return httpx.NotFound("no file %s", key)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", obj.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=0")
+	http.ServeContent(w, r, "", obj.ModTime, file.(io.ReadSeeker))
+	return nil
+}
+
+// allowOrigin lets the browser read the response when the request comes
+// from one of AllowedOrigins, and reports whether it does.
+func (f *Files) allowOrigin(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	if origin == "" || !slices.Contains(f.AllowedOrigins, origin) {
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	return true
+}
+
+func (f *Files) sign(r *http.Request, key string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(f.URLTTL).UTC().Truncate(time.Second)
+	url, err := f.Store.SignedURL(r.Context(), key, f.URLTTL)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return url, expiresAt, nil
+}
+
+func (f *Files) readError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, errTooLarge) || errors.As(err, &maxBytesErr) {
+		return httpx.Errorf(http.StatusRequestEntityTooLarge, "the file is larger than %d bytes", f.MaxUploadSize)
+	}
+	return httpx.BadRequest("failed to read the upload: %v", err)
+}
+
+func extension(contentType string) string {
+	if ext, ok := extensions[contentType]; ok {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// limitReader fails with errTooLarge once more than limit bytes were read,
+// rather than stopping silently like io.LimitReader.
+type limitReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n, errTooLarge
+	}
+	return n, err
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageTestContent() string {
+	return `package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+// stores returns a Local store in a temporary directory and an S3 store.
+func stores(t *testing.T) map[string]Storage {
+	t.Helper()
+
+	local, err := NewLocal(t.TempDir(), "http://api.test", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3, _ := newTestS3(t)
+	return map[string]Storage{"local": local, "s3": s3}
+}
+
+// newTestS3 returns an S3 store and a client for its signed URLs. The store
+// talks to the server at S3_TEST_ENDPOINT, such as the MinIO service of
+// docker compose, or to an in-memory fake when it is not set.
+func newTestS3(t *testing.T) (*S3, *http.Client) {
+	t.Helper()
+
+	client := http.DefaultClient
+	cfg := S3Config{
+		Endpoint:  os.Getenv("S3_TEST_ENDPOINT"),
+		Region:    "us-east-1",
+		Bucket:    "test-" + strings.ToLower(strings.NewReplacer("/", "-", "_", "-").Replace(t.Name())),
+		AccessKey: envOr("S3_ACCESS_KEY", "minioadmin"),
+		SecretKey: envOr("S3_SECRET_KEY", "minioadmin"),
+	}
+	if cfg.Endpoint == "" {
+		// The fake only understands unsigned payloads, which the client
+		// sends over TLS.
+		srv := httptest.NewTLSServer(gofakes3.New(s3mem.New()).Server())
+		t.Cleanup(srv.Close)
+		client = srv.Client()
+		cfg.Endpoint = srv.URL
+		cfg.Transport = client.Transport
+	}
+	s3, err := NewS3(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s3, client
+}
+
+func TestStorage(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "docs/report.pdf"
+
+			if _, err := store.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Stat before Put: got %v, want ErrNotFound", err)
+			}
+
+			content := "%PDF-1.4 report"
+			if err := store.Put(ctx, key, strings.NewReader(content), -1, "application/pdf"); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+
+			obj, err := store.Stat(ctx, key)
+			if err != nil {
+				t.Fatalf("Stat: %v", err)
+			}
+			if obj.Size != int64(len(content)) || obj.ContentType != "application/pdf" {
+				t.Errorf("Stat: got %+v", obj)
+			}
+
+			rc, _, err := store.Get(ctx, key)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			got, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil || string(got) != content {
+				t.Errorf("Get: got %q, %v", got, err)
+			}
+
+			if err := store.Delete(ctx, key); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get after Delete: got %v, want ErrNotFound", err)
+			}
+			if err := store.Delete(ctx, key); err != nil {
+				t.Errorf("Delete of a missing key: %v", err)
+			}
+		})
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	for name, store := range stores(t) {
+		for _, key := range []string{"", "../secret", "a/../../b", "/etc/passwd", "a//b"} {
+			err := store.Put(context.Background(), key, strings.NewReader("x"), 1, "text/plain")
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("%s: Put(%q): got %v, want ErrInvalidKey", name, key, err)
+			}
+		}
+	}
+}
+
+func TestS3SignedURL(t *testing.T) {
+	store, client := newTestS3(t)
+	ctx := context.Background()
+	if err := store.Put(ctx, "hello.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := store.SignedURL(ctx, "hello.txt", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(url, "X-Amz-Signature=") {
+		t.Errorf("SignedURL: %s is not presigned", url)
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" {
+		t.Errorf("GET %s: got %d %q", url, resp.StatusCode, body)
+	}
+}
+
+func TestLocalSignedURL(t *testing.T) {
+	local, err := NewLocal(t.TempDir(), "http://api.test/", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	local.now = func() time.Time { return now }
+
+	signed, err := local.SignedURL(context.Background(), "a b.png", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, signed, nil)
+	if req.URL.Path != "/files/a b.png" {
+		t.Errorf("path: got %q", req.URL.Path)
+	}
+
+	query := req.URL.Query()
+	if err := local.Verify("a b.png", query); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := local.Verify("other.png", query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify of another key: got %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := local.Verify("a b.png", query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify after expiry: got %v", err)
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageHandlersTestContent(modulePath string) string {
+	return `package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"` + modulePath + `/internal/httpx"
+)
+
+// png is the signature and header of a PNG file, enough to be sniffed as
+// image/png.
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+// newTestServer serves the handlers the routes register over a Local store
+// that accepts files of up to maxSize bytes.
+func newTestServer(t *testing.T, maxSize int64) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	local, err := NewLocal(t.TempDir(), srv.URL, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := &Files{
+		Store:          local,
+		MaxUploadSize:  maxSize,
+		AllowedTypes:   DefaultAllowedTypes,
+		URLTTL:         time.Minute,
+		AllowedOrigins: []string{"http://localhost:5173"},
+	}
+	mux.Handle(UploadPath, httpx.HandlerFunc(files.Upload))
+	mux.Handle(UploadPath+"/", httpx.HandlerFunc(files.URL))
+	mux.Handle(DownloadPath, httpx.HandlerFunc(files.Download))
+	return srv
+}
+
+func upload(t *testing.T, srv *httptest.Server, field string, content []byte) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("description", "a test upload"); err != nil {
+		t.Fatal(err)
+	}
+	part, err := form.CreateFormFile(field, "upload.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	form.Close()
+
+	resp, err := http.Post(srv.URL+UploadPath, form.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestUpload(t *testing.T) {
+	srv := newTestServer(t, 1<<20)
+
+	resp := upload(t, srv, "file", png)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("upload: got %d", resp.StatusCode)
+	}
+	var uploaded UploadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&uploaded); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(uploaded.Key, ".png") || uploaded.Size != int64(len(png)) || uploaded.ContentType != "image/png" {
+		t.Errorf("upload: got %+v", uploaded)
+	}
+
+	download, err := http.Get(uploaded.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer download.Body.Close()
+	content, _ := io.ReadAll(download.Body)
+	if download.StatusCode != http.StatusOK || !bytes.Equal(content, png) {
+		t.Errorf("download: got %d with %d bytes", download.StatusCode, len(content))
+	}
+	if got := download.Header.Get("Content-Type"); got != "image/png" {
+		t.Errorf("download Content-Type: got %q", got)
+	}
+
+	tampered, err := http.Get(strings.Replace(uploaded.URL, "signature=", "signature=x", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered.Body.Close()
+	if tampered.StatusCode != http.StatusForbidden {
+		t.Errorf("download with a bad signature: got %d", tampered.StatusCode)
+	}
+
+	fresh, err := http.Get(srv.URL + UploadPath + "/" + uploaded.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fresh.Body.Close()
+	var signed UploadResponse
+	if err := json.NewDecoder(fresh.Body).Decode(&signed); err != nil {
+		t.Fatal(err)
+	}
+	if fresh.StatusCode != http.StatusOK || signed.URL == "" || signed.Size != uploaded.Size {
+		t.Errorf("signed URL: got %d %+v", fresh.StatusCode, signed)
+	}
+}
+
+func TestUploadRejects(t *testing.T) {
+	srv := newTestServer(t, 1024)
+
+	tests := []struct {
+		name    string
+		field   string
+		content []byte
+		status  int
+	}{
+		{"too large", "file", append(png, make([]byte, 2048)...), http.StatusRequestEntityTooLarge},
+		{"type not allowed", "file", []byte("#!/bin/sh\necho hello\n"), http.StatusUnsupportedMediaType},
+		{"empty", "file", nil, http.StatusBadRequest},
+		{"no file field", "document", png, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := upload(t, srv, tt.field, tt.content)
+			if resp.StatusCode != tt.status {
+				body, _ := io.ReadAll(resp.Body)
+				t.Errorf("got %d %s, want %d", resp.StatusCode, body, tt.status)
+			}
+		})
+	}
+
+	resp, err := http.Post(srv.URL+UploadPath, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("JSON body: got %d", resp.StatusCode)
+	}
+
+	missing, err := http.Get(srv.URL + UploadPath + "/missing.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Errorf("missing key: got %d", missing.StatusCode)
+	}
+}
+
+func TestUploadCORS(t *testing.T) {
+	srv := newTestServer(t, 1024)
+
+	for origin, want := range map[string]string{
+		"http://localhost:5173": "http://localhost:5173",
+		"https://evil.example":  "",
+	} {
+		req, _ := http.NewRequest(http.MethodOptions, srv.URL+UploadPath, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("preflight from %s: got Access-Control-Allow-Origin %q, want %q", origin, got, want)
+		}
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageClientFiles(framework string, useTypeScript bool) map[string]string {
+	ext, jsx := "js", "jsx"
+	if useTypeScript {
+		ext, jsx = "ts", "tsx"
+	}
+
+	files := map[string]string{
+		filepath.Join("src", "upload."+ext): pg.generateStorageClientContent(useTypeScript),
+	}
+
+	switch framework {
+	case react, preact:
+		files[filepath.Join("src", "FileUpload."+jsx)] = pg.generateStorageReactContent(framework, useTypeScript)
+	case vue:
+		files[filepath.Join("src", "components", "FileUpload.vue")] = pg.generateStorageVueContent(useTypeScript)
+	case svelte:
+		files[filepath.Join("src", "lib", "FileUpload.svelte")] = pg.generateStorageSvelteContent(useTypeScript)
+	case solidjs:
+		files[filepath.Join("src", "FileUpload."+jsx)] = pg.generateStorageSolidContent(useTypeScript)
+	case lit:
+		files[filepath.Join("src", "file-upload."+ext)] = pg.generateStorageLitContent(useTypeScript)
+	}
+
+	return files
+}
+
+func (pg *ProjectGenerator) generateStorageClientContent(useTypeScript bool) string {
+	var b strings.Builder
+
+	b.WriteString(`import config from './config';
+
+// UPLOAD_PATH is the endpoint gogen add storage registered on the API.
+export const UPLOAD_PATH = '/uploads';
+
+// ACCEPTED_TYPES are the default STORAGE_ALLOWED_TYPES of the API, keep them
+// in step when changing either.
+export const ACCEPTED_TYPES = 'image/png,image/jpeg,image/gif,image/webp,application/pdf';
+
+`)
+	if useTypeScript {
+		b.WriteString(`// UploadedFile is the body the API answers uploads with.
+export interface UploadedFile {
+  key: string;
+  size: number;
+  content_type: string;
+  mod_time: string;
+  url: string;
+  expires_at: string;
+}
+
+export function uploadUrl(): string {
+`)
+	} else {
+		b.WriteString("export function uploadUrl() {\n")
+	}
+	b.WriteString(`  return new URL(UPLOAD_PATH, config.apiUrl || window.location.origin).toString();
+}
+
+// uploadFile sends file to the API as multipart/form-data and reports the
+// progress as a fraction between 0 and 1. It rejects with the detail of the
+// problem the API answers with, such as a file that is too large.
+`)
+	if useTypeScript {
+		b.WriteString("export function uploadFile(file: File, onProgress?: (fraction: number) => void): Promise<UploadedFile> {\n")
+	} else {
+		b.WriteString("export function uploadFile(file, onProgress) {\n")
+	}
+	b.WriteString(`  return new Promise((resolve, reject) => {
+    const xhr = new XMLHttpRequest();
+    xhr.open('POST', uploadUrl());
+    xhr.responseType = 'json';
+    xhr.upload.onprogress = (event) => {
+      if (event.lengthComputable) onProgress?.(event.loaded / event.total);
+    };
+    xhr.onload = () => {
+      if (xhr.status === 201) {
+        resolve(xhr.response);
+      } else {
+        reject(new Error(xhr.response?.detail ?? 'Upload failed with status ' + xhr.status));
+      }
+    };
+    xhr.onerror = () => reject(new Error('Upload failed, the API could not be reached'));
+
+    const form = new FormData();
+    form.append('file', file);
+    xhr.send(form);
+  });
+}
+`)
+
+	return b.String()
+}
+
+func (pg *ProjectGenerator) generateStorageReactContent(framework string, useTypeScript bool) string {
+	if useTypeScript {
+		imports := "import { useState, type ChangeEvent } from 'react';\n"
+		eventType := "ChangeEvent<HTMLInputElement>"
+		if framework == preact {
+			imports = "import type { JSX } from 'preact';\nimport { useState } from 'preact/hooks';\n"
+			eventType = "JSX.TargetedEvent<HTMLInputElement>"
+		}
+
+		return imports + `import { ACCEPTED_TYPES, uploadFile, type UploadedFile } from './upload';
+
+interface FileUploadProps {
+  onUploaded?: (file: UploadedFile) => void;
+}
+
+// FileUpload uploads the file the user picks to the API and links to it
+// once it is stored.
+export function FileUpload({ onUploaded }: FileUploadProps) {
+  const [progress, setProgress] = useState<number | null>(null);
+  const [uploaded, setUploaded] = useState<UploadedFile | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  const handleChange = async (event: ` + eventType + `) => {
+    const input = event.currentTarget;
+    const file = input.files?.[0];
+    if (!file) return;
+
+    setError(null);
+    setUploaded(null);
+    setProgress(0);
+    try {
+      const result = await uploadFile(file, setProgress);
+      setUploaded(result);
+      onUploaded?.(result);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : String(err));
+    } finally {
+      setProgress(null);
+      input.value = '';
+    }
+  };
+
+  return (
+    <div className="file-upload">
+      <input type="file" accept={ACCEPTED_TYPES} onChange={handleChange} disabled={progress !== null} />
+      {progress !== null && <progress max={1} value={progress} />}
+      {error && <p role="alert">{error}</p>}
+      {uploaded && (
+        <p>
+          Uploaded{' '}
+          <a href={uploaded.url} target="_blank" rel="noreferrer">
+            {uploaded.key}
+          </a>{' '}
+          ({uploaded.size} bytes)
+        </p>
+      )}
+    </div>
+  );
+}
+`
+	}
+
+	hooks := "react"
+	if framework == preact {
+		hooks = "preact/hooks"
+	}
+
+	return `import { useState } from '` + hooks + `';
+import { ACCEPTED_TYPES, uploadFile } from './upload';
+
+// FileUpload uploads the file the user picks to the API and links to it
+// once it is stored.
+export function FileUpload({ onUploaded }) {
+  const [progress, setProgress] = useState(null);
+  const [uploaded, setUploaded] = useState(null);
+  const [error, setError] = useState(null);
+
+  const handleChange = async (event) => {
+    const input = event.currentTarget;
+    const file = input.files?.[0];
+    if (!file) return;
+
+    setError(null);
+    setUploaded(null);
+    setProgress(0);
+    try {
+      const result = await uploadFile(file, setProgress);
+      setUploaded(result);
+      onUploaded?.(result);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : String(err));
+    } finally {
+      setProgress(null);
+      input.value = '';
+    }
+  };
+
+  return (
+    <div className="file-upload">
+      <input type="file" accept={ACCEPTED_TYPES} onChange={handleChange} disabled={progress !== null} />
+      {progress !== null && <progress max={1} value={progress} />}
+      {error && <p role="alert">{error}</p>}
+      {uploaded && (
+        <p>
+          Uploaded{' '}
+          <a href={uploaded.url} target="_blank" rel="noreferrer">
+            {uploaded.key}
+          </a>{' '}
+          ({uploaded.size} bytes)
+        </p>
+      )}
+    </div>
+  );
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageVueContent(useTypeScript bool) string {
+	script := `<script setup>
+import { ref } from 'vue';
+import { ACCEPTED_TYPES, uploadFile } from '../upload';
+
+// FileUpload uploads the file the user picks to the API, links to it once
+// it is stored and emits uploaded with the stored file.
+const emit = defineEmits(['uploaded']);
+
+const progress = ref(null);
+const uploaded = ref(null);
+const error = ref(null);
+
+async function handleChange(event) {
+  const input = event.target;
+`
+	if useTypeScript {
+		script = `<script setup lang="ts">
+import { ref } from 'vue';
+import { ACCEPTED_TYPES, uploadFile, type UploadedFile } from '../upload';
+
+// FileUpload uploads the file the user picks to the API, links to it once
+// it is stored and emits uploaded with the stored file.
+const emit = defineEmits<{ uploaded: [file: UploadedFile] }>();
+
+const progress = ref<number | null>(null);
+const uploaded = ref<UploadedFile | null>(null);
+const error = ref<string | null>(null);
+
+async function handleChange(event: Event) {
+  const input = event.target as HTMLInputElement;
+`
+	}
+
+	return script + `  const file = input.files?.[0];
+  if (!file) return;
+
+  error.value = null;
+  uploaded.value = null;
+  progress.value = 0;
+  try {
+    const result = await uploadFile(file, (fraction) => {
+      progress.value = fraction;
+    });
+    uploaded.value = result;
+    emit('uploaded', result);
+  } catch (err) {
+    error.value = err instanceof Error ? err.message : String(err);
+  } finally {
+    progress.value = null;
+    input.value = '';
+  }
+}
+</script>
+
+<template>
+  <div class="file-upload">
+    <input type="file" :accept="ACCEPTED_TYPES" :disabled="progress !== null" @change="handleChange" />
+    <progress v-if="progress !== null" max="1" :value="progress" />
+    <p v-if="error" role="alert">{{ error }}</p>
+    <p v-if="uploaded">
+      Uploaded
+      <a :href="uploaded.url" target="_blank" rel="noreferrer">{{ uploaded.key }}</a>
+      ({{ uploaded.size }} bytes)
+    </p>
+  </div>
+</template>
+`
+}
+
+func (pg *ProjectGenerator) generateStorageSvelteContent(useTypeScript bool) string {
+	script := `<script>
+  import { ACCEPTED_TYPES, uploadFile } from '../upload';
+
+  // FileUpload uploads the file the user picks to the API, links to it once
+  // it is stored and calls onuploaded with the stored file.
+  let { onuploaded } = $props();
+
+  let progress = $state(null);
+  let uploaded = $state(null);
+  let error = $state(null);
+
+  async function handleChange(event) {
+`
+	if useTypeScript {
+		script = `<script lang="ts">
+  import { ACCEPTED_TYPES, uploadFile, type UploadedFile } from '../upload';
+
+  // FileUpload uploads the file the user picks to the API, links to it once
+  // it is stored and calls onuploaded with the stored file.
+  let { onuploaded }: { onuploaded?: (file: UploadedFile) => void } = $props();
+
+  let progress = $state<number | null>(null);
+  let uploaded = $state<UploadedFile | null>(null);
+  let error = $state<string | null>(null);
+
+  async function handleChange(event: Event & { currentTarget: HTMLInputElement }) {
+`
+	}
+
+	return script + `    const input = event.currentTarget;
+    const file = input.files?.[0];
+    if (!file) return;
+
+    error = null;
+    uploaded = null;
+    progress = 0;
+    try {
+      const result = await uploadFile(file, (fraction) => (progress = fraction));
+      uploaded = result;
+      onuploaded?.(result);
+    } catch (err) {
+      error = err instanceof Error ? err.message : String(err);
+    } finally {
+      progress = null;
+      input.value = '';
+    }
+  }
+</script>
+
+<div class="file-upload">
+  <input type="file" accept={ACCEPTED_TYPES} disabled={progress !== null} onchange={handleChange} />
+  {#if progress !== null}
+    <progress max="1" value={progress}></progress>
+  {/if}
+  {#if error}
+    <p role="alert">{error}</p>
+  {/if}
+  {#if uploaded}
+    <p>
+      Uploaded <a href={uploaded.url} target="_blank" rel="noreferrer">{uploaded.key}</a> ({uploaded.size} bytes)
+    </p>
+  {/if}
+</div>
+`
+}
+
+func (pg *ProjectGenerator) generateStorageSolidContent(useTypeScript bool) string {
+	header := `import { createSignal, Show } from 'solid-js';
+import { ACCEPTED_TYPES, uploadFile } from './upload';
+
+// FileUpload uploads the file the user picks to the API and links to it
+// once it is stored.
+export function FileUpload(props) {
+  const [progress, setProgress] = createSignal(null);
+  const [uploaded, setUploaded] = createSignal(null);
+  const [error, setError] = createSignal(null);
+
+  const handleChange = async (event) => {
+`
+	if useTypeScript {
+		header = `import { createSignal, Show } from 'solid-js';
+import { ACCEPTED_TYPES, uploadFile, type UploadedFile } from './upload';
+
+interface FileUploadProps {
+  onUploaded?: (file: UploadedFile) => void;
+}
+
+// FileUpload uploads the file the user picks to the API and links to it
+// once it is stored.
+export function FileUpload(props: FileUploadProps) {
+  const [progress, setProgress] = createSignal<number | null>(null);
+  const [uploaded, setUploaded] = createSignal<UploadedFile | null>(null);
+  const [error, setError] = createSignal<string | null>(null);
+
+  const handleChange = async (event: Event & { currentTarget: HTMLInputElement }) => {
+`
+	}
+
+	return header + `    const input = event.currentTarget;
+    const file = input.files?.[0];
+    if (!file) return;
+
+    setError(null);
+    setUploaded(null);
+    setProgress(0);
+    try {
+      const result = await uploadFile(file, (fraction) => setProgress(fraction));
+      setUploaded(result);
+      props.onUploaded?.(result);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : String(err));
+    } finally {
+      setProgress(null);
+      input.value = '';
+    }
+  };
+
+  return (
+    <div class="file-upload">
+      <input type="file" accept={ACCEPTED_TYPES} onChange={handleChange} disabled={progress() !== null} />
+      <Show when={progress() !== null}>
+        <progress max={1} value={progress() ?? 0} />
+      </Show>
+      <Show when={error()}>
+        <p role="alert">{error()}</p>
+      </Show>
+      <Show when={uploaded()}>
+        {(file) => (
+          <p>
+            Uploaded{' '}
+            <a href={file().url} target="_blank" rel="noreferrer">
+              {file().key}
+            </a>{' '}
+            ({file().size} bytes)
+          </p>
+        )}
+      </Show>
+    </div>
+  );
+}
+`
+}
+
+func (pg *ProjectGenerator) generateStorageLitContent(useTypeScript bool) string {
+	var b strings.Builder
+
+	b.WriteString("import { LitElement, html, nothing } from 'lit';\n")
+	if useTypeScript {
+		b.WriteString("import { ACCEPTED_TYPES, uploadFile, type UploadedFile } from './upload';\n")
+	} else {
+		b.WriteString("import { ACCEPTED_TYPES, uploadFile } from './upload';\n")
+	}
+	b.WriteString(`
+// FileUploadElement is <file-upload>. It uploads the file the user picks to
+// the API, links to it once it is stored and dispatches an uploaded event
+// with the stored file as its detail.
+export class FileUploadElement extends LitElement {
+  static properties = {
+    progress: { state: true },
+    uploaded: { state: true },
+    error: { state: true },
+  };
+
+`)
+	if useTypeScript {
+		b.WriteString(`  declare progress: number | null;
+  declare uploaded: UploadedFile | null;
+  declare error: string | null;
+
+`)
+	}
+	b.WriteString(`  constructor() {
+    super();
+    this.progress = null;
+    this.uploaded = null;
+    this.error = null;
+  }
+
+`)
+	if useTypeScript {
+		b.WriteString("  private async handleChange(event: Event) {\n    const input = event.target as HTMLInputElement;\n")
+	} else {
+		b.WriteString("  async handleChange(event) {\n    const input = event.target;\n")
+	}
+	b.WriteString(`    const file = input.files?.[0];
+    if (!file) return;
+
+    this.error = null;
+    this.uploaded = null;
+    this.progress = 0;
+    try {
+      const result = await uploadFile(file, (fraction) => (this.progress = fraction));
+      this.uploaded = result;
+      this.dispatchEvent(new CustomEvent('uploaded', { detail: result }));
+    } catch (err) {
+      this.error = err instanceof Error ? err.message : String(err);
+    } finally {
+      this.progress = null;
+      input.value = '';
+    }
+  }
+
+  render() {
+    return html` + "`" + `
+      <input type="file" accept=${ACCEPTED_TYPES} ?disabled=${this.progress !== null} @change=${this.handleChange} />
+      ${this.progress !== null ? html` + "`" + `<progress max="1" .value=${this.progress}></progress>` + "`" + ` : nothing}
+      ${this.error ? html` + "`" + `<p role="alert">${this.error}</p>` + "`" + ` : nothing}
+      ${this.uploaded
+        ? html` + "`" + `<p>
+            Uploaded <a href=${this.uploaded.url} target="_blank" rel="noreferrer">${this.uploaded.key}</a>
+            (${this.uploaded.size} bytes)
+          </p>` + "`" + `
+        : nothing}
+    ` + "`" + `;
+  }
+}
+
+customElements.define('file-upload', FileUploadElement);
+`)
+
+	return b.String()
+}
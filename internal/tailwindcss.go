@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 )
 
 type TailwindConfig struct {
@@ -63,7 +65,7 @@ func (tc *TailwindConfig) tailwindLibInstall(framework, runtime string) error {
 	}
 
 	switch framework {
-	case react, vue, svelte, solidjs:
+	case react, vue, svelte, solidjs, preact, lit, qwik, astro:
 		args := append([]string{"add"}, "tailwindcss", "@tailwindcss/vite")
 		return exec.Command(runtime, args...).Run()
 
@@ -83,7 +85,7 @@ func (tc *TailwindConfig) tailwindLibInstall(framework, runtime string) error {
 
 func (tc *TailwindConfig) updateConfigFile(framework string) error {
 	configExt := ".js"
-	if tc.UseTypeScript && framework != angular {
+	if (tc.UseTypeScript && framework != angular) || framework == qwik {
 		configExt = ".ts"
 	}
 
@@ -151,7 +153,68 @@ export default defineConfig({
 });
 `
 		return os.WriteFile("vite.config"+configExt, []byte(viteConfig), 0600)
-	case "angular":
+	case preact:
+		viteConfig := `import { defineConfig } from 'vite'
+import preact from '@preact/preset-vite'
+import tailwindcss from '@tailwindcss/vite'
+
+export default defineConfig({
+  plugins: [
+  preact(),
+  tailwindcss(),
+  ],
+})
+`
+		return os.WriteFile("vite.config"+configExt, []byte(viteConfig), 0600)
+	case lit:
+		viteConfig := `import { defineConfig } from 'vite'
+import tailwindcss from '@tailwindcss/vite'
+
+export default defineConfig({
+  plugins: [
+  tailwindcss(),
+  ],
+})
+`
+		return os.WriteFile("vite.config"+configExt, []byte(viteConfig), 0600)
+	case qwik:
+		viteConfig := `import { defineConfig } from "vite";
+import { qwikVite } from "@builder.io/qwik/optimizer";
+import { qwikCity } from "@builder.io/qwik-city/vite";
+import tsconfigPaths from "vite-tsconfig-paths";
+import tailwindcss from "@tailwindcss/vite";
+
+export default defineConfig(() => {
+  return {
+    plugins: [qwikCity(), qwikVite(), tsconfigPaths(), tailwindcss()],
+    server: {
+      headers: {
+        "Cache-Control": "public, max-age=0",
+      },
+    },
+    preview: {
+      headers: {
+        "Cache-Control": "public, max-age=600",
+      },
+    },
+  };
+});
+`
+		return os.WriteFile("vite.config"+configExt, []byte(viteConfig), 0600)
+	case astro:
+		astroConfig := `// @ts-check
+import { defineConfig } from 'astro/config';
+import tailwindcss from '@tailwindcss/vite';
+
+// https://astro.build/config
+export default defineConfig({
+  vite: {
+    plugins: [tailwindcss()],
+  },
+});
+`
+		return os.WriteFile("astro.config.mjs", []byte(astroConfig), 0600)
+	case angular:
 		postcssConfig := `{  "plugins": {    "@tailwindcss/postcss": {}  }}`
 		return os.WriteFile(".postcssrc.json", []byte(postcssConfig), 0600)
 	}
@@ -172,10 +235,22 @@ func (tc *TailwindConfig) updateStylesFile(framework string) error {
 		cssFile = "src/index.css"
 	case angular:
 		cssFile = "src/styles.css"
+	case preact, lit:
+		cssFile = "src/index.css"
+	case qwik:
+		cssFile = "src/global.css"
+	case astro:
+		// The minimal Astro starter ships without a stylesheet, the one
+		// created here is imported from the index page.
+		cssFile = "src/styles/global.css"
 	default:
 		return nil
 	}
 
+	if err := os.MkdirAll(filepath.Dir(cssFile), 0750); err != nil {
+		return fmt.Errorf("failed to create styles directory: %w", err)
+	}
+
 	existingContent, err := os.ReadFile(cssFile)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to read CSS file: %w", err)
@@ -190,5 +265,35 @@ func (tc *TailwindConfig) updateStylesFile(framework string) error {
 		newContent = []byte(tailwindImport)
 	}
 
-	return os.WriteFile(cssFile, newContent, 0600)
+	if err := os.WriteFile(cssFile, newContent, 0600); err != nil {
+		return err
+	}
+
+	if framework == astro {
+		return importAstroStyles("src/pages/index.astro", "../styles/global.css")
+	}
+
+	return nil
+}
+
+// importAstroStyles imports the stylesheet at cssPath, relative to the page,
+// in the frontmatter of the Astro page at pagePath.
+func importAstroStyles(pagePath, cssPath string) error {
+	content, err := os.ReadFile(pagePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", pagePath, err)
+	}
+
+	page := string(content)
+	importLine := "import '" + cssPath + "';\n"
+	if strings.Contains(page, importLine) {
+		return nil
+	}
+	if rest, found := strings.CutPrefix(page, "---\n"); found {
+		page = "---\n" + importLine + rest
+	} else {
+		page = "---\n" + importLine + "---\n\n" + page
+	}
+
+	return os.WriteFile(pagePath, []byte(page), 0600)
 }
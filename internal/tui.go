@@ -0,0 +1,449 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const tuiPackageDir = "internal/ui"
+
+// CreateTUIProject writes a Bubble Tea application: main.go starts the
+// program and internal/ui holds the model, split into model, update and view
+// files, with a list screen that opens a detail screen.
+func (pg *ProjectGenerator) CreateTUIProject(projectName, moduleName string) error {
+	if moduleName == "" {
+		moduleName = projectName
+	}
+
+	uiDir := filepath.FromSlash(tuiPackageDir)
+	files := map[string]string{
+		"main.go":                             pg.generateTUIMainContent(projectName, moduleName),
+		filepath.Join(uiDir, "items.go"):      pg.generateTUIItemsContent(),
+		filepath.Join(uiDir, "keys.go"):       pg.generateTUIKeysContent(),
+		filepath.Join(uiDir, "styles.go"):     pg.generateTUIStylesContent(),
+		filepath.Join(uiDir, "model.go"):      pg.generateTUIModelContent(),
+		filepath.Join(uiDir, "update.go"):     pg.generateTUIUpdateContent(),
+		filepath.Join(uiDir, "view.go"):       pg.generateTUIViewContent(),
+		filepath.Join(uiDir, "model_test.go"): pg.generateTUITestContent(),
+	}
+
+	for _, path := range sortedKeys(files) {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, []byte(files[path]), 0600); err != nil {
+			return err
+		}
+	}
+
+	if err := pg.CreateAirFile(".", constants.TUITemplate); err != nil {
+		fmt.Printf("Warning: failed to create .air.toml file: %v\n", err)
+	}
+
+	if err := pg.CreateGitignoreFile(constants.TUITemplate, "."); err != nil {
+		fmt.Printf("Warning: failed to create .gitignore file: %v\n", err)
+	}
+
+	cmd := exec.Command("go", "mod", "tidy")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	}
+
+	if err := pg.InitGitRepository(projectName, constants.TUITemplate); err != nil {
+		fmt.Printf("Warning: failed to initialize git repository: %v\n", err)
+	}
+
+	return nil
+}
+
+func (pg *ProjectGenerator) generateTUIMainContent(projectName, moduleName string) string {
+	return `package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"` + moduleName + `/internal/ui"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	// The terminal belongs to the UI while it runs, set DEBUG=1 and use the
+	// log package to write to debug.log instead.
+	if os.Getenv("DEBUG") != "" {
+		f, err := tea.LogToFile("debug.log", "debug")
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+	}
+
+	p := tea.NewProgram(ui.New("` + projectName + `", ui.SampleItems()), tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+`
+}
+
+func (pg *ProjectGenerator) generateTUIItemsContent() string {
+	return `package ui
+
+import "github.com/charmbracelet/bubbles/list"
+
+// Item is a row on the list screen and the content of the detail screen.
+type Item struct {
+	Name    string
+	Summary string
+	Body    string
+}
+
+func (i Item) Title() string       { return i.Name }
+func (i Item) Description() string { return i.Summary }
+func (i Item) FilterValue() string { return i.Name }
+
+var _ list.DefaultItem = Item{}
+
+// SampleItems is the example data shown by the app, replace it with your own
+// source.
+func SampleItems() []Item {
+	return []Item{
+		{
+			Name:    "Model",
+			Summary: "State of the application",
+			Body:    "The model holds everything the UI needs to render. It is a plain value, Update returns a new copy instead of mutating it.",
+		},
+		{
+			Name:    "Update",
+			Summary: "Handles messages",
+			Body:    "Update receives key presses, window resizes and the results of commands, and returns the next model plus an optional command.",
+		},
+		{
+			Name:    "View",
+			Summary: "Renders the model",
+			Body:    "View turns the model into a string. Styles live in styles.go so the views only deal with layout.",
+		},
+		{
+			Name:    "Commands",
+			Summary: "Side effects",
+			Body:    "A tea.Cmd runs outside the update loop, for example an HTTP request, and reports back with a message.",
+		},
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateTUIKeysContent() string {
+	return `package ui
+
+import "github.com/charmbracelet/bubbles/key"
+
+// keyMap holds the bindings handled by the model. The list screen adds Select
+// to the list's own bindings, the detail screen shows them through help.
+type keyMap struct {
+	Select key.Binding
+	Back   key.Binding
+	Quit   key.Binding
+	Help   key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Select: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc", "backspace"),
+			key.WithHelp("esc", "back"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "more"),
+		),
+	}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Back, k.Quit, k.Help}
+}
+
+// FullHelp implements help.KeyMap.
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Back, k.Quit}, {k.Help}}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateTUIStylesContent() string {
+	return `package ui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	primary = lipgloss.AdaptiveColor{Light: "#5A56E0", Dark: "#7571F9"}
+	muted   = lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}
+	light   = lipgloss.Color("#FFFDF5")
+)
+
+// styles is the style sheet of the app. Change colors and spacing here, the
+// views only compose these styles.
+var styles = struct {
+	App      lipgloss.Style
+	Title    lipgloss.Style
+	Subtitle lipgloss.Style
+	Detail   lipgloss.Style
+}{
+	App: lipgloss.NewStyle().
+		Padding(1, 2),
+	Title: lipgloss.NewStyle().
+		Foreground(light).
+		Background(primary).
+		Padding(0, 1).
+		Bold(true),
+	Subtitle: lipgloss.NewStyle().
+		Foreground(muted).
+		MarginTop(1).
+		MarginBottom(1),
+	Detail: lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primary).
+		Padding(1, 2).
+		MarginBottom(1),
+}
+
+// newDelegate renders list rows with the app's colors.
+func newDelegate() list.DefaultDelegate {
+	d := list.NewDefaultDelegate()
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.
+		Foreground(primary).
+		BorderLeftForeground(primary)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.
+		Foreground(primary).
+		BorderLeftForeground(primary)
+	return d
+}
+`
+}
+
+func (pg *ProjectGenerator) generateTUIModelContent() string {
+	return `package ui
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type screen int
+
+const (
+	listScreen screen = iota
+	detailScreen
+)
+
+// Model is the root Bubble Tea model. Update lives in update.go and View in
+// view.go.
+type Model struct {
+	keys     keyMap
+	list     list.Model
+	help     help.Model
+	screen   screen
+	selected Item
+	width    int
+}
+
+func New(title string, items []Item) Model {
+	keys := defaultKeyMap()
+
+	listItems := make([]list.Item, len(items))
+	for i, item := range items {
+		listItems[i] = item
+	}
+
+	l := list.New(listItems, newDelegate(), 0, 0)
+	l.Title = title
+	l.Styles.Title = styles.Title
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.Select}
+	}
+	l.AdditionalFullHelpKeys = l.AdditionalShortHelpKeys
+
+	return Model{
+		keys: keys,
+		list: l,
+		help: help.New(),
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Selected returns the item shown on the detail screen, ok is false while the
+// list screen is active.
+func (m Model) Selected() (item Item, ok bool) {
+	return m.selected, m.screen == detailScreen
+}
+`
+}
+
+func (pg *ProjectGenerator) generateTUIUpdateContent() string {
+	return `package ui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		h, v := styles.App.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.help.Width = msg.Width - h
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.screen == detailScreen {
+			return m.updateDetail(msg)
+		}
+
+		// Enter opens the highlighted item, unless it confirms a filter.
+		if m.list.FilterState() != list.Filtering && key.Matches(msg, m.keys.Select) {
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				m.selected = item
+				m.screen = detailScreen
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m Model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.screen = listScreen
+	case key.Matches(msg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	}
+	return m, nil
+}
+`
+}
+
+func (pg *ProjectGenerator) generateTUIViewContent() string {
+	return `package ui
+
+import "github.com/charmbracelet/lipgloss"
+
+// minDetailWidth keeps the detail box readable before the first resize.
+const minDetailWidth = 40
+
+func (m Model) View() string {
+	if m.screen == detailScreen {
+		return styles.App.Render(m.detailView())
+	}
+	return styles.App.Render(m.list.View())
+}
+
+func (m Model) detailView() string {
+	width := m.width - styles.App.GetHorizontalFrameSize() - styles.Detail.GetHorizontalBorderSize()
+	if width < minDetailWidth {
+		width = minDetailWidth
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		styles.Title.Render(m.selected.Name),
+		styles.Subtitle.Render(m.selected.Summary),
+		styles.Detail.Width(width).Render(m.selected.Body),
+		m.help.View(m.keys),
+	)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateTUITestContent() string {
+	return `package ui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+)
+
+func TestOpenItemAndQuit(t *testing.T) {
+	items := SampleItems()
+	tm := teatest.NewTestModel(t, New("test", items), teatest.WithInitialTermSize(80, 24))
+
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte(items[0].Name))
+	}, teatest.WithDuration(3*time.Second))
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// The rounded border is only drawn around the detail screen.
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("╭"))
+	}, teatest.WithDuration(3*time.Second))
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+
+	final, ok := tm.FinalModel(t).(Model)
+	if !ok {
+		t.Fatalf("final model has type %T, want Model", tm.FinalModel(t))
+	}
+
+	selected, open := final.Selected()
+	if !open || selected.Name != items[0].Name {
+		t.Errorf("expected %q to be open on quit, got %q (open: %v)", items[0].Name, selected.Name, open)
+	}
+}
+
+func TestBackReturnsToList(t *testing.T) {
+	var m tea.Model = New("test", SampleItems())
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if _, open := m.(Model).Selected(); !open {
+		t.Fatal("enter did not open the detail screen")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, open := m.(Model).Selected(); open {
+		t.Error("esc did not return to the list screen")
+	}
+}
+`
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"os"
+	"sort"
 )
 
 type DirectoryManager struct {
@@ -24,3 +25,12 @@ func (dm *DirectoryManager) ChangeToDir(dir string) error {
 func (dm *DirectoryManager) RootDir() error {
 	return os.Chdir(dm.originalDir)
 }
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,536 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	wasmWebDir = "web"
+	// wasmNamespace is the global JavaScript object main.wasm sets its
+	// functions on.
+	wasmNamespace = "gowasm"
+)
+
+// wasmExecDirs are the GOROOT directories wasm_exec.js has lived in, lib/wasm
+// since Go 1.24 and misc/wasm before.
+var wasmExecDirs = []string{"lib/wasm", "misc/wasm"}
+
+// WasmFrontends lists the frameworks the wasm template can be embedded in:
+// Vite projects that serve public/ from the site root.
+var WasmFrontends = []string{react, vue, solidjs, preact, lit}
+
+func IsWasmFrontend(framework string) bool {
+	for _, f := range WasmFrontends {
+		if f == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWasmProject writes a Go program compiled to GOOS=js GOARCH=wasm that
+// exposes functions to JavaScript with syscall/js. Without a frontend the
+// page lives in web/ and cmd/serve serves it, with a frontend main.wasm is
+// built into frontend/public and loaded from frontend/src/wasm.
+func (pg *ProjectGenerator) CreateWasmProject(config *WebProjectConfig) error {
+	moduleName := config.ModuleName
+	if moduleName == "" {
+		moduleName = config.ProjectName
+	}
+
+	wasmExecDir, err := findWasmExecDir()
+	if err != nil {
+		return err
+	}
+	wasmExec, err := os.ReadFile(filepath.Join(wasmExecDir.goroot, filepath.FromSlash(wasmExecDir.dir), "wasm_exec.js"))
+	if err != nil {
+		return fmt.Errorf("failed to read wasm_exec.js: %w", err)
+	}
+
+	assetsDir := wasmWebDir
+	if config.FrontendFramework != "" {
+		assetsDir = filepath.Join(constants.FrontendDir, "public")
+	}
+
+	textDir := filepath.Join("internal", "text")
+	files := map[string]string{
+		"main.go":                              pg.generateWasmMainContent(config.ProjectName, moduleName),
+		"Makefile":                             pg.generateWasmMakefile(config, wasmExecDir.dir, filepath.ToSlash(assetsDir)),
+		filepath.Join(textDir, "text.go"):      pg.generateWasmTextContent(),
+		filepath.Join(textDir, "text_test.go"): pg.generateWasmTextTestContent(),
+	}
+	if config.FrontendFramework == "" {
+		files[filepath.Join("cmd", "serve", "main.go")] = pg.generateWasmServeContent()
+		files[filepath.Join(wasmWebDir, "index.html")] = pg.generateWasmIndexContent(config.ProjectName)
+		files[filepath.Join(wasmWebDir, "main.js")] = pg.generateWasmLoaderContent()
+	}
+	if config.UseWASI {
+		files[filepath.Join("cmd", "wasi", "main.go")] = pg.generateWASIMainContent(moduleName)
+	}
+
+	if config.FrontendFramework != "" {
+		if err := pg.CreateFrontendProject(config.FrontendFramework, constants.FrontendDir, config.UseTypeScript, config.Runtime, config.UseTailwind); err != nil {
+			return fmt.Errorf("failed to create frontend project: %w", err)
+		}
+		if err := os.RemoveAll(filepath.Join(constants.FrontendDir, ".git")); err != nil {
+			fmt.Printf("Warning: failed to remove git repository from frontend: %v\n", err)
+		}
+
+		loader, ext := pg.generateWasmFrontendLoaderContent(), "js"
+		if config.UseTypeScript {
+			loader, ext = pg.generateWasmFrontendLoaderTSContent(), "ts"
+		}
+		files[filepath.Join(constants.FrontendDir, "src", "wasm."+ext)] = loader
+	}
+
+	files[filepath.Join(assetsDir, "wasm_exec.js")] = string(wasmExec)
+	if err := writeProjectFiles(".", files); err != nil {
+		return err
+	}
+
+	if err := pg.CreateGitignoreFile(constants.WasmTemplate, "."); err != nil {
+		fmt.Printf("Warning: failed to create .gitignore file: %v\n", err)
+	}
+
+	build := exec.Command("go", "build", "-o", filepath.Join(assetsDir, "main.wasm"), ".")
+	build.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	if output, err := build.CombinedOutput(); err != nil {
+		fmt.Printf("Warning: failed to build main.wasm: %v\n%s", err, output)
+	}
+
+	if err := pg.InitGitRepository(config.ProjectName, constants.WasmTemplate); err != nil {
+		fmt.Printf("Warning: failed to initialize git repository: %v\n", err)
+	}
+
+	return nil
+}
+
+type wasmExecLocation struct {
+	goroot string
+	dir    string
+}
+
+// findWasmExecDir locates wasm_exec.js in the local Go installation, it has
+// to match the toolchain that builds main.wasm.
+func findWasmExecDir() (wasmExecLocation, error) {
+	output, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return wasmExecLocation{}, fmt.Errorf("failed to get GOROOT: %w", err)
+	}
+	goroot := strings.TrimSpace(string(output))
+
+	for _, dir := range wasmExecDirs {
+		if _, err := os.Stat(filepath.Join(goroot, filepath.FromSlash(dir), "wasm_exec.js")); err == nil {
+			return wasmExecLocation{goroot: goroot, dir: dir}, nil
+		}
+	}
+	return wasmExecLocation{}, fmt.Errorf("wasm_exec.js not found in %s", goroot)
+}
+
+func (pg *ProjectGenerator) generateWasmMakefile(config *WebProjectConfig, wasmExecDir, assetsDir string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `# wasm_exec.js must come from the same Go version that builds main.wasm,
+# run make wasm-exec after upgrading Go.
+WASM_EXEC_DIR := $(shell go env GOROOT)/%s
+ASSETS_DIR := %s
+
+.PHONY: build test test-js wasm-exec`, wasmExecDir, assetsDir)
+	if config.FrontendFramework != "" {
+		b.WriteString(" dev")
+	} else {
+		b.WriteString(" serve")
+	}
+	if config.UseWASI {
+		b.WriteString(" build-wasi run-wasi")
+	}
+
+	b.WriteString(`
+
+build:
+	GOOS=js GOARCH=wasm go build -o $(ASSETS_DIR)/main.wasm .
+`)
+	if config.FrontendFramework != "" {
+		packageManager := "npm"
+		if config.Runtime == bun {
+			packageManager = bun
+		}
+		fmt.Fprintf(&b, `
+dev: build
+	cd %s && %s run dev
+`, constants.FrontendDir, packageManager)
+	} else {
+		b.WriteString(`
+serve: build
+	go run ./cmd/serve -dir $(ASSETS_DIR)
+`)
+	}
+
+	b.WriteString(`
+test:
+	go test ./...
+
+# Runs the tests compiled to WebAssembly, requires Node.js.
+test-js:
+	GOOS=js GOARCH=wasm go test -exec="$(WASM_EXEC_DIR)/go_js_wasm_exec" ./...
+
+wasm-exec:
+	cp "$(WASM_EXEC_DIR)/wasm_exec.js" $(ASSETS_DIR)/
+`)
+	if config.UseWASI {
+		b.WriteString(`
+build-wasi:
+	GOOS=wasip1 GOARCH=wasm go build -o bin/wasi.wasm ./cmd/wasi
+
+# Requires wasmtime, any other WASI runtime works the same way.
+run-wasi: build-wasi
+	echo "Hello from WASI" | wasmtime bin/wasi.wasm
+`)
+	}
+
+	return b.String()
+}
+
+func (pg *ProjectGenerator) generateWasmMainContent(projectName, moduleName string) string {
+	return `//go:build js && wasm
+
+// Command ` + projectName + ` is compiled to WebAssembly and exposes the
+// functions of internal/text to JavaScript on globalThis.` + wasmNamespace + `.
+// Build it with make build, GOOS=js GOARCH=wasm is required.
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"` + moduleName + `/internal/text"
+)
+
+const namespace = "` + wasmNamespace + `"
+
+func main() {
+	js.Global().Set(namespace, js.ValueOf(map[string]any{
+		"greet":     js.FuncOf(greet),
+		"wordCount": js.FuncOf(wordCount),
+	}))
+
+	// JavaScript can only call the functions while the Go program runs.
+	select {}
+}
+
+// greet(name: string): string
+func greet(_ js.Value, args []js.Value) any {
+	name, err := stringArg(args, 0)
+	if err != nil {
+		return jsError(err)
+	}
+	return text.Greet(name)
+}
+
+// wordCount(text: string): number
+func wordCount(_ js.Value, args []js.Value) any {
+	s, err := stringArg(args, 0)
+	if err != nil {
+		return jsError(err)
+	}
+	return text.WordCount(s)
+}
+
+func stringArg(args []js.Value, i int) (string, error) {
+	if len(args) <= i || args[i].Type() != js.TypeString {
+		return "", fmt.Errorf("argument %d must be a string", i)
+	}
+	return args[i].String(), nil
+}
+
+// jsError returns err as a JavaScript Error. A panic in a js.FuncOf callback
+// stops the Go program, so errors are returned to the caller instead.
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWasmTextContent() string {
+	return `// Package text holds the logic main.go exposes to JavaScript. It has no
+// syscall/js dependency, so it is tested and reused like any Go package.
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Greet returns a greeting for name, or for the world when name is blank.
+func Greet(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "World"
+	}
+	return "Hello, " + name + "!"
+}
+
+// WordCount returns the number of words in s, words being runs of letters and
+// digits.
+func WordCount(s string) int {
+	return len(strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWasmTextTestContent() string {
+	return `package text
+
+import "testing"
+
+func TestGreet(t *testing.T) {
+	if got := Greet("  Gopher "); got != "Hello, Gopher!" {
+		t.Errorf("Greet = %q, want %q", got, "Hello, Gopher!")
+	}
+	if got := Greet(""); got != "Hello, World!" {
+		t.Errorf("Greet = %q, want %q", got, "Hello, World!")
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	tests := map[string]int{
+		"":                          0,
+		"one":                       1,
+		"Hello, WebAssembly world!": 3,
+		"  spaced   out\n\tlines ":  3,
+		"Go runs in the browser":    5,
+	}
+
+	for in, want := range tests {
+		if got := WordCount(in); got != want {
+			t.Errorf("WordCount(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWasmServeContent() string {
+	return `// Command serve is a development server for the page in web/. It disables
+// caching so a rebuilt main.wasm is picked up on reload.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	dir := flag.String("dir", "web", "directory to serve")
+	flag.Parse()
+
+	files := http.FileServer(http.Dir(*dir))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		files.ServeHTTP(w, r)
+	})
+
+	log.Printf("Serving %s on http://localhost%s", *dir, *addr)
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWasmIndexContent(projectName string) string {
+	return `<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+    <title>` + projectName + `</title>
+    <style>
+      body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 2rem auto; padding: 0 1rem; }
+      input, textarea { width: 100%; box-sizing: border-box; font: inherit; padding: 0.5rem; }
+      textarea { min-height: 8rem; }
+    </style>
+    <script src="wasm_exec.js"></script>
+    <script type="module" src="main.js"></script>
+  </head>
+  <body>
+    <h1>` + projectName + `</h1>
+    <p id="status">Loading main.wasm…</p>
+
+    <label for="name">Name</label>
+    <input id="name" placeholder="Gopher" disabled />
+    <p id="greeting"></p>
+
+    <label for="text">Text</label>
+    <textarea id="text" disabled></textarea>
+    <p id="count"></p>
+  </body>
+</html>
+`
+}
+
+func (pg *ProjectGenerator) generateWasmLoaderContent() string {
+	return `// Starts main.wasm with the runtime from wasm_exec.js and wires the
+// functions it sets on globalThis.` + wasmNamespace + ` to the page.
+const go = new Go();
+const status = document.getElementById("status");
+
+try {
+  const { instance } = await WebAssembly.instantiateStreaming(fetch("main.wasm"), go.importObject);
+  // run resolves when the Go program exits, main.go blocks forever.
+  go.run(instance);
+} catch (err) {
+  status.textContent = "Failed to load main.wasm, run make build: " + err;
+  throw err;
+}
+
+const { greet, wordCount } = globalThis.` + wasmNamespace + `;
+const name = document.getElementById("name");
+const text = document.getElementById("text");
+
+function update() {
+  document.getElementById("greeting").textContent = greet(name.value);
+  const count = wordCount(text.value);
+  document.getElementById("count").textContent = count + (count === 1 ? " word" : " words");
+}
+
+name.addEventListener("input", update);
+text.addEventListener("input", update);
+name.disabled = false;
+text.disabled = false;
+status.textContent = "main.wasm is running.";
+update();
+`
+}
+
+func (pg *ProjectGenerator) generateWasmFrontendLoaderContent() string {
+	return `// Loads main.wasm, built into public/ by make build in the project root,
+// and resolves to the functions it sets on globalThis.` + wasmNamespace + `:
+//
+//   const { greet, wordCount } = await loadWasm();
+let loading;
+
+function loadScript(src) {
+  return new Promise((resolve, reject) => {
+    const script = document.createElement("script");
+    script.src = src;
+    script.onload = () => resolve();
+    script.onerror = () => reject(new Error("failed to load " + src));
+    document.head.appendChild(script);
+  });
+}
+
+export function loadWasm() {
+  if (!loading) {
+    loading = (async () => {
+      const base = import.meta.env.BASE_URL;
+      if (!globalThis.Go) {
+        await loadScript(base + "wasm_exec.js");
+      }
+
+      const go = new globalThis.Go();
+      const { instance } = await WebAssembly.instantiateStreaming(fetch(base + "main.wasm"), go.importObject);
+      // run resolves when the Go program exits, main.go blocks forever.
+      go.run(instance);
+
+      return globalThis.` + wasmNamespace + `;
+    })();
+  }
+  return loading;
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWasmFrontendLoaderTSContent() string {
+	return `// Loads main.wasm, built into public/ by make build in the project root,
+// and resolves to the functions it sets on globalThis.` + wasmNamespace + `:
+//
+//   const { greet, wordCount } = await loadWasm();
+
+// The functions return an Error instead of throwing on invalid arguments.
+export interface GoExports {
+  greet(name: string): string | Error;
+  wordCount(text: string): number | Error;
+}
+
+interface GoRuntime {
+  importObject: WebAssembly.Imports;
+  run(instance: WebAssembly.Instance): Promise<void>;
+}
+
+declare global {
+  // eslint-disable-next-line no-var
+  var Go: (new () => GoRuntime) | undefined;
+  // eslint-disable-next-line no-var
+  var ` + wasmNamespace + `: GoExports | undefined;
+}
+
+let loading: Promise<GoExports> | undefined;
+
+function loadScript(src: string): Promise<void> {
+  return new Promise((resolve, reject) => {
+    const script = document.createElement("script");
+    script.src = src;
+    script.onload = () => resolve();
+    script.onerror = () => reject(new Error("failed to load " + src));
+    document.head.appendChild(script);
+  });
+}
+
+export function loadWasm(): Promise<GoExports> {
+  if (!loading) {
+    loading = (async () => {
+      const base = import.meta.env.BASE_URL;
+      if (!globalThis.Go) {
+        await loadScript(base + "wasm_exec.js");
+      }
+
+      const go = new globalThis.Go!();
+      const { instance } = await WebAssembly.instantiateStreaming(fetch(base + "main.wasm"), go.importObject);
+      // run resolves when the Go program exits, main.go blocks forever.
+      void go.run(instance);
+
+      if (!globalThis.` + wasmNamespace + `) {
+        throw new Error("main.wasm did not set globalThis.` + wasmNamespace + `");
+      }
+      return globalThis.` + wasmNamespace + `;
+    })();
+  }
+  return loading;
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWASIMainContent(moduleName string) string {
+	return `//go:build wasip1
+
+// Command wasi is built with GOOS=wasip1 GOARCH=wasm and runs in WASI
+// runtimes such as wasmtime or wazero. It reads text from stdin and prints
+// its word count, reusing internal/text like the browser build.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"` + moduleName + `/internal/text"
+)
+
+func main() {
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Println(text.WordCount(string(input)))
+}
+`
+}
@@ -0,0 +1,974 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	workerPackageDir = "internal/worker"
+	jobsPackageDir   = "internal/jobs"
+	workerCommandDir = "cmd/worker"
+	workerDockerfile = "Dockerfile.worker"
+)
+
+// CreateWorkerProject writes a background worker: internal/worker holds the
+// job, registry, backend and pool types, internal/jobs a sample job and
+// main.go runs the pool until it receives a shutdown signal.
+func (pg *ProjectGenerator) CreateWorkerProject(config *WebProjectConfig) error {
+	moduleName := config.ModuleName
+	if moduleName == "" {
+		moduleName = config.ProjectName
+	}
+
+	files := pg.generateWorkerFiles(moduleName, "main.go")
+	files[".env"] = workerEnvContent
+	files[".env.example"] = workerEnvContent
+
+	if err := writeProjectFiles(".", files); err != nil {
+		return err
+	}
+
+	if config.UseDocker {
+		if err := pg.CreateDockerfile(".", constants.WorkerTemplate, config); err != nil {
+			return fmt.Errorf("failed to create Docker files: %w", err)
+		}
+
+		cc := NewComposeConfig(config)
+		cc.Context = "."
+		cc.WorkerDockerfile = "Dockerfile"
+		cc.WorkerOnly = true
+		if err := pg.CreateDockerComposeFile(".", cc); err != nil {
+			return fmt.Errorf("failed to create docker-compose files: %w", err)
+		}
+	}
+
+	if err := pg.CreateAirFile(".", constants.WorkerTemplate); err != nil {
+		fmt.Printf("Warning: failed to create .air.toml file: %v\n", err)
+	}
+
+	if err := pg.CreateGitignoreFile(constants.WorkerTemplate, "."); err != nil {
+		fmt.Printf("Warning: failed to create .gitignore file: %v\n", err)
+	}
+
+	cmd := exec.Command("go", "mod", "tidy")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	}
+
+	if err := pg.InitGitRepository(config.ProjectName, constants.WorkerTemplate); err != nil {
+		fmt.Printf("Warning: failed to initialize git repository: %v\n", err)
+	}
+
+	return nil
+}
+
+// AddWorker adds the worker packages and a cmd/worker binary to the Go module
+// of the project in rootDir, the api/ directory for web projects. When the
+// project has a docker-compose.yml a worker service is added to it, built
+// from Dockerfile.worker.
+func (pg *ProjectGenerator) AddWorker(rootDir string, config *WebProjectConfig) (string, error) {
+	moduleDir := rootDir
+	composeContext := "."
+	if _, err := os.Stat(filepath.Join(rootDir, "go.mod")); err != nil {
+		moduleDir = filepath.Join(rootDir, constants.APIDir)
+		composeContext = "./" + constants.APIDir
+	}
+
+	moduleName, err := readModulePath(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+
+	for _, dir := range []string{workerPackageDir, jobsPackageDir, workerCommandDir} {
+		if dirExists(filepath.Join(moduleDir, filepath.FromSlash(dir))) {
+			return "", fmt.Errorf("%s already exists in %s", dir, moduleDir)
+		}
+	}
+
+	files := pg.generateWorkerFiles(moduleName, filepath.Join(filepath.FromSlash(workerCommandDir), "main.go"))
+	if err := writeProjectFiles(moduleDir, files); err != nil {
+		return "", err
+	}
+
+	composePath := filepath.Join(rootDir, "docker-compose.yml")
+	if _, err := os.Stat(composePath); err == nil {
+		dockerfile := pg.generateWorkerDockerfile(goVersionFromMod(filepath.Join(moduleDir, "go.mod")), config, "./"+workerCommandDir)
+		if err := os.WriteFile(filepath.Join(moduleDir, workerDockerfile), []byte(dockerfile), 0600); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", workerDockerfile, err)
+		}
+
+		if err := addComposeWorker(rootDir, composeContext, config); err != nil {
+			return "", err
+		}
+	}
+
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = moduleDir
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Warning: failed to run go mod tidy: %v\n", err)
+	}
+
+	return moduleDir, nil
+}
+
+// addComposeWorker inserts the worker service into an existing
+// docker-compose.yml and its override. The project name and the extra
+// services the worker depends on are read from the file.
+func addComposeWorker(rootDir, composeContext string, config *WebProjectConfig) error {
+	composePath := filepath.Join(rootDir, "docker-compose.yml")
+	content, err := os.ReadFile(filepath.Clean(composePath))
+	if err != nil {
+		return fmt.Errorf("failed to read docker-compose.yml: %w", err)
+	}
+	compose := string(content)
+
+	if strings.Contains(compose, "\n  worker:\n") {
+		fmt.Println("Warning: docker-compose.yml already has a worker service, leaving it unchanged")
+		return nil
+	}
+
+	cc := NewComposeConfig(config)
+	cc.Context = composeContext
+	cc.WorkerDockerfile = workerDockerfile
+	for _, line := range strings.Split(compose, "\n") {
+		if name, ok := strings.CutPrefix(line, "name: "); ok {
+			cc.ProjectName = strings.TrimSpace(name)
+			break
+		}
+	}
+	for _, service := range ComposeServices {
+		if strings.Contains(compose, "\n  "+service+":\n") {
+			cc.Services = append(cc.Services, service)
+		}
+	}
+
+	env, err := cc.serviceEnv()
+	if err != nil {
+		return err
+	}
+
+	// Services end where the top-level networks key starts.
+	index := strings.Index(compose, "\nnetworks:\n")
+	if index == -1 {
+		return fmt.Errorf("failed to find the top-level networks key in docker-compose.yml")
+	}
+	compose = compose[:index] + "\n" + cc.generateWorkerService(env) + compose[index:]
+	if err := os.WriteFile(composePath, []byte(compose), 0600); err != nil {
+		return fmt.Errorf("failed to update docker-compose.yml: %w", err)
+	}
+
+	overridePath := filepath.Join(rootDir, "docker-compose.override.yml")
+	override, err := os.ReadFile(filepath.Clean(overridePath))
+	if err != nil {
+		fmt.Printf("Warning: failed to read docker-compose.override.yml, skipping the worker override: %v\n", err)
+		return nil
+	}
+	if err := os.WriteFile(overridePath, append(override, []byte("\n"+cc.generateWorkerOverride())...), 0600); err != nil {
+		return fmt.Errorf("failed to update docker-compose.override.yml: %w", err)
+	}
+
+	return nil
+}
+
+func writeProjectFiles(dirName string, files map[string]string) error {
+	for _, name := range sortedKeys(files) {
+		path := filepath.Join(dirName, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, []byte(files[name]), 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const workerEnvContent = `WORKER_CONCURRENCY=4
+WORKER_MAX_ATTEMPTS=5
+`
+
+func (pg *ProjectGenerator) generateWorkerFiles(moduleName, mainPath string) map[string]string {
+	workerDir := filepath.FromSlash(workerPackageDir)
+	jobsDir := filepath.FromSlash(jobsPackageDir)
+
+	return map[string]string{
+		mainPath:                                   pg.generateWorkerMainContent(moduleName),
+		filepath.Join(workerDir, "job.go"):         pg.generateWorkerJobContent(),
+		filepath.Join(workerDir, "registry.go"):    pg.generateWorkerRegistryContent(),
+		filepath.Join(workerDir, "backend.go"):     pg.generateWorkerBackendContent(),
+		filepath.Join(workerDir, "memory.go"):      pg.generateWorkerMemoryContent(),
+		filepath.Join(workerDir, "postgres.go"):    pg.generateWorkerPostgresContent(),
+		filepath.Join(workerDir, "pool.go"):        pg.generateWorkerPoolContent(),
+		filepath.Join(workerDir, "pool_test.go"):   pg.generateWorkerPoolTestContent(),
+		filepath.Join(jobsDir, "welcome_email.go"): pg.generateWelcomeEmailJobContent(moduleName),
+	}
+}
+
+func (pg *ProjectGenerator) generateWorkerMainContent(moduleName string) string {
+	return `package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"` + moduleName + `/internal/jobs"
+	"` + moduleName + `/internal/worker"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, reading configuration from the environment")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context) error {
+	registry := worker.NewRegistry()
+	jobs.Register(registry)
+
+	// The in-process queue needs no infrastructure. Use
+	// worker.NewPostgresBackend, or your own Backend, to share the queue
+	// between processes.
+	backend := worker.NewMemoryBackend(100)
+
+	config := worker.Config{
+		Concurrency: envInt("WORKER_CONCURRENCY", 4),
+		MaxAttempts: envInt("WORKER_MAX_ATTEMPTS", 5),
+	}
+	pool := worker.NewPool(backend, registry, config)
+
+	// An example job so the worker has something to do, replace it with
+	// your own producers.
+	if err := pool.Enqueue(ctx, jobs.TypeWelcomeEmail, jobs.WelcomeEmail{Email: "gopher@example.com"}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Starting worker %s with concurrency %d\n", version, config.Concurrency)
+	return pool.Run(ctx)
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWorkerJobContent() string {
+	return `package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Job is a unit of work. Type selects the handler, Payload holds the
+// arguments as JSON.
+type Job struct {
+	ID       string
+	Type     string
+	Payload  []byte
+	Attempts int
+}
+
+func NewJob(jobType string, payload any) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", jobType, err)
+	}
+	return &Job{Type: jobType, Payload: data}, nil
+}
+
+// Decode unmarshals the payload into v.
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", j.Type, err)
+	}
+	return nil
+}
+
+// Handler runs jobs of one type. Returning an error retries the job with
+// backoff until the pool's MaxAttempts is reached. Handlers should stop when
+// ctx is cancelled, which happens when a shutdown outlasts the drain timeout.
+type Handler interface {
+	Handle(ctx context.Context, job *Job) error
+}
+
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+func (f HandlerFunc) Handle(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWorkerRegistryContent() string {
+	return `package worker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry maps job types to their handlers.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+// Register adds the handler for jobType, registering a type twice panics.
+func (r *Registry) Register(jobType string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.handlers[jobType]; ok {
+		panic(fmt.Sprintf("worker: handler for %q registered twice", jobType))
+	}
+	r.handlers[jobType] = handler
+}
+
+func (r *Registry) Handler(jobType string) (Handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handler, ok := r.handlers[jobType]
+	return handler, ok
+}
+
+// Types returns the registered job types in sorted order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	for jobType := range r.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWorkerBackendContent() string {
+	return `package worker
+
+import (
+	"context"
+	"time"
+)
+
+// Backend stores the queue. MemoryBackend keeps jobs in process and
+// PostgresBackend in a table shared by all workers, a Redis backend
+// implements the same methods on top of lists or streams.
+type Backend interface {
+	Enqueue(ctx context.Context, job *Job) error
+	// Dequeue blocks until a job is available or ctx is done.
+	Dequeue(ctx context.Context) (*Job, error)
+	// Complete removes a job that ran successfully.
+	Complete(ctx context.Context, job *Job) error
+	// Retry hands the job out again once runAt has passed.
+	Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error
+	// Fail keeps a job that used up its attempts aside for inspection.
+	Fail(ctx context.Context, job *Job, cause error) error
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWorkerMemoryContent() string {
+	return `package worker
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryBackend is an in-process queue. Jobs are lost when the process exits,
+// use it in development, in tests and for work that can be redone.
+type MemoryBackend struct {
+	queue  chan *Job
+	nextID atomic.Int64
+
+	mu     sync.Mutex
+	failed []*Job
+}
+
+// NewMemoryBackend creates a queue holding up to capacity pending jobs,
+// Enqueue blocks while it is full.
+func NewMemoryBackend(capacity int) *MemoryBackend {
+	return &MemoryBackend{queue: make(chan *Job, capacity)}
+}
+
+func (b *MemoryBackend) Enqueue(ctx context.Context, job *Job) error {
+	if job.ID == "" {
+		job.ID = strconv.FormatInt(b.nextID.Add(1), 10)
+	}
+
+	select {
+	case b.queue <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *MemoryBackend) Dequeue(ctx context.Context) (*Job, error) {
+	select {
+	case job := <-b.queue:
+		return job, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *MemoryBackend) Complete(ctx context.Context, job *Job) error {
+	return nil
+}
+
+func (b *MemoryBackend) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	time.AfterFunc(time.Until(runAt), func() {
+		b.queue <- job
+	})
+	return nil
+}
+
+func (b *MemoryBackend) Fail(ctx context.Context, job *Job, cause error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failed = append(b.failed, job)
+	return nil
+}
+
+// Failed returns the jobs that used up their attempts.
+func (b *MemoryBackend) Failed() []*Job {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]*Job(nil), b.failed...)
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWorkerPostgresContent() string {
+	return `package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PostgresSchema creates the table PostgresBackend uses, run it from your
+// migrations.
+const PostgresSchema = ` + "`" + `
+CREATE TABLE IF NOT EXISTS jobs (
+	id           BIGSERIAL PRIMARY KEY,
+	type         TEXT NOT NULL,
+	payload      BYTEA NOT NULL,
+	attempts     INT NOT NULL DEFAULT 0,
+	run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+	locked_until TIMESTAMPTZ,
+	failed_at    TIMESTAMPTZ,
+	last_error   TEXT
+);
+
+CREATE INDEX IF NOT EXISTS jobs_pending_idx ON jobs (run_at) WHERE failed_at IS NULL;
+` + "`" + `
+
+// claimJobQuery hands out the oldest due job. FOR UPDATE SKIP LOCKED lets any
+// number of workers poll the table without blocking on, or claiming, the same
+// row.
+const claimJobQuery = ` + "`" + `
+UPDATE jobs SET locked_until = now() + make_interval(secs => $1)
+WHERE id = (
+	SELECT id FROM jobs
+	WHERE failed_at IS NULL
+		AND run_at <= now()
+		AND (locked_until IS NULL OR locked_until < now())
+	ORDER BY run_at
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, type, payload, attempts` + "`" + `
+
+// PostgresBackend stores jobs in the table from PostgresSchema. Open db with
+// a Postgres driver, for example github.com/jackc/pgx/v5/stdlib.
+type PostgresBackend struct {
+	db *sql.DB
+	// PollInterval is how long Dequeue waits before looking again when no
+	// job is due.
+	PollInterval time.Duration
+	// LockTimeout is how long a claimed job stays hidden from other workers.
+	// A job whose worker crashed is handed out again after it, so it must be
+	// longer than any job runs.
+	LockTimeout time.Duration
+}
+
+func NewPostgresBackend(db *sql.DB) *PostgresBackend {
+	return &PostgresBackend{
+		db:           db,
+		PollInterval: time.Second,
+		LockTimeout:  5 * time.Minute,
+	}
+}
+
+func (b *PostgresBackend) Enqueue(ctx context.Context, job *Job) error {
+	var id int64
+	err := b.db.QueryRowContext(ctx,
+		"INSERT INTO jobs (type, payload, attempts) VALUES ($1, $2, $3) RETURNING id",
+		job.Type, job.Payload, job.Attempts,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s job: %w", job.Type, err)
+	}
+
+	job.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+func (b *PostgresBackend) Dequeue(ctx context.Context) (*Job, error) {
+	for {
+		var id int64
+		job := &Job{}
+		err := b.db.QueryRowContext(ctx, claimJobQuery, b.LockTimeout.Seconds()).
+			Scan(&id, &job.Type, &job.Payload, &job.Attempts)
+		if err == nil {
+			job.ID = strconv.FormatInt(id, 10)
+			return job, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to claim job: %w", err)
+		}
+
+		select {
+		case <-time.After(b.PollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (b *PostgresBackend) Complete(ctx context.Context, job *Job) error {
+	_, err := b.db.ExecContext(ctx, "DELETE FROM jobs WHERE id = $1", job.ID)
+	return err
+}
+
+func (b *PostgresBackend) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	_, err := b.db.ExecContext(ctx,
+		"UPDATE jobs SET attempts = $2, run_at = $3, locked_until = NULL, last_error = $4 WHERE id = $1",
+		job.ID, job.Attempts, runAt, cause.Error(),
+	)
+	return err
+}
+
+func (b *PostgresBackend) Fail(ctx context.Context, job *Job, cause error) error {
+	_, err := b.db.ExecContext(ctx,
+		"UPDATE jobs SET attempts = $2, failed_at = now(), locked_until = NULL, last_error = $3 WHERE id = $1",
+		job.ID, job.Attempts, cause.Error(),
+	)
+	return err
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWorkerPoolContent() string {
+	return `package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrDrainTimeout is returned by Run when jobs were still running after the
+// drain timeout and had their context cancelled.
+var ErrDrainTimeout = errors.New("worker: jobs still running after the drain timeout")
+
+// Config tunes a Pool, zero values use the defaults.
+type Config struct {
+	// Concurrency is the number of jobs processed at the same time,
+	// defaults to 4.
+	Concurrency int
+	// MaxAttempts is how often a job runs before it is marked as failed,
+	// defaults to 5.
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry, defaults to 1s. It
+	// doubles with every attempt up to MaxBackoff, which defaults to 5m.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// DrainTimeout is how long Run waits for jobs in flight once its
+	// context is cancelled, defaults to 30s.
+	DrainTimeout time.Duration
+}
+
+// Pool runs jobs from a Backend with the handlers in a Registry.
+type Pool struct {
+	backend  Backend
+	registry *Registry
+	config   Config
+}
+
+func NewPool(backend Backend, registry *Registry, config Config) *Pool {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Minute
+	}
+	if config.DrainTimeout <= 0 {
+		config.DrainTimeout = 30 * time.Second
+	}
+
+	return &Pool{
+		backend:  backend,
+		registry: registry,
+		config:   config,
+	}
+}
+
+// Enqueue adds a job of the given type, payload is encoded as JSON.
+func (p *Pool) Enqueue(ctx context.Context, jobType string, payload any) error {
+	job, err := NewJob(jobType, payload)
+	if err != nil {
+		return err
+	}
+	return p.backend.Enqueue(ctx, job)
+}
+
+// Run processes jobs until ctx is cancelled. It then stops taking new jobs
+// and waits for the ones in flight, cancelling their context if they are
+// still running after DrainTimeout.
+func (p *Pool) Run(ctx context.Context) error {
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx, jobCtx)
+		}()
+	}
+
+	<-ctx.Done()
+
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(p.config.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+		return nil
+	case <-timer.C:
+		cancelJobs()
+		<-drained
+		return ErrDrainTimeout
+	}
+}
+
+func (p *Pool) work(ctx, jobCtx context.Context) {
+	for {
+		job, err := p.backend.Dequeue(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("worker: failed to dequeue: %v", err)
+
+			select {
+			case <-time.After(p.config.BaseBackoff):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		p.process(jobCtx, job)
+	}
+}
+
+func (p *Pool) process(ctx context.Context, job *Job) {
+	// Bookkeeping has to reach the backend even when a slow job was
+	// cancelled at the end of the drain.
+	backendCtx := context.WithoutCancel(ctx)
+
+	handler, ok := p.registry.Handler(job.Type)
+	if !ok {
+		p.report(job, p.backend.Fail(backendCtx, job, fmt.Errorf("no handler registered for job type %q", job.Type)))
+		return
+	}
+
+	job.Attempts++
+	err := p.handle(ctx, handler, job)
+	switch {
+	case err == nil:
+		p.report(job, p.backend.Complete(backendCtx, job))
+	case job.Attempts >= p.config.MaxAttempts:
+		log.Printf("worker: job %s (%s) failed after %d attempts: %v", job.ID, job.Type, job.Attempts, err)
+		p.report(job, p.backend.Fail(backendCtx, job, err))
+	default:
+		delay := p.backoff(job.Attempts)
+		log.Printf("worker: job %s (%s) attempt %d failed, retrying in %s: %v", job.ID, job.Type, job.Attempts, delay, err)
+		p.report(job, p.backend.Retry(backendCtx, job, time.Now().Add(delay), err))
+	}
+}
+
+// handle runs the handler and turns a panic into an error, so one bad job
+// doesn't take the worker down.
+func (p *Pool) handle(ctx context.Context, handler Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return handler.Handle(ctx, job)
+}
+
+func (p *Pool) report(job *Job, err error) {
+	if err != nil {
+		log.Printf("worker: failed to update job %s (%s): %v", job.ID, job.Type, err)
+	}
+}
+
+// backoff doubles the delay with every attempt and adds up to 20% jitter, so
+// jobs that failed together don't all retry at the same moment.
+func (p *Pool) backoff(attempt int) time.Duration {
+	delay := p.config.BaseBackoff
+	for i := 1; i < attempt && delay < p.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.config.MaxBackoff {
+		delay = p.config.MaxBackoff
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWorkerPoolTestContent() string {
+	return `package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	return Config{
+		Concurrency:  2,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Millisecond,
+		MaxBackoff:   5 * time.Millisecond,
+		DrainTimeout: time.Second,
+	}
+}
+
+// runPool runs the pool until stop is closed and returns the result of Run.
+func runPool(t *testing.T, pool *Pool, stop <-chan struct{}) error {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- pool.Run(ctx)
+	}()
+
+	select {
+	case <-stop:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the jobs")
+	}
+	cancel()
+
+	return <-result
+}
+
+func TestPoolRetriesUntilSuccess(t *testing.T) {
+	backend := NewMemoryBackend(10)
+	registry := NewRegistry()
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	registry.Register("flaky", HandlerFunc(func(ctx context.Context, job *Job) error {
+		if calls.Add(1) < 3 {
+			return errors.New("temporary failure")
+		}
+		close(done)
+		return nil
+	}))
+
+	pool := NewPool(backend, registry, testConfig())
+	if err := pool.Enqueue(context.Background(), "flaky", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPool(t, pool, done); err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("handler ran %d times, want 3", got)
+	}
+	if failed := backend.Failed(); len(failed) != 0 {
+		t.Errorf("expected no failed jobs, got %d", len(failed))
+	}
+}
+
+func TestPoolFailsAfterMaxAttempts(t *testing.T) {
+	backend := NewMemoryBackend(10)
+	registry := NewRegistry()
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	registry.Register("broken", HandlerFunc(func(ctx context.Context, job *Job) error {
+		if calls.Add(1) == 3 {
+			close(done)
+		}
+		return errors.New("permanent failure")
+	}))
+
+	pool := NewPool(backend, registry, testConfig())
+	if err := pool.Enqueue(context.Background(), "broken", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPool(t, pool, done); err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+
+	failed := backend.Failed()
+	if len(failed) != 1 {
+		t.Fatalf("expected 1 failed job, got %d", len(failed))
+	}
+	if failed[0].Attempts != 3 {
+		t.Errorf("failed job has %d attempts, want 3", failed[0].Attempts)
+	}
+}
+
+func TestPoolDrainsJobsInFlight(t *testing.T) {
+	backend := NewMemoryBackend(10)
+	registry := NewRegistry()
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	registry.Register("slow", HandlerFunc(func(ctx context.Context, job *Job) error {
+		close(started)
+		select {
+		case <-time.After(50 * time.Millisecond):
+			finished.Store(true)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}))
+
+	pool := NewPool(backend, registry, testConfig())
+	if err := pool.Enqueue(context.Background(), "slow", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Shutdown starts while the job is running, Run must wait for it.
+	if err := runPool(t, pool, started); err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Run returned before the job in flight finished")
+	}
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWelcomeEmailJobContent(moduleName string) string {
+	return `package jobs
+
+import (
+	"context"
+	"log"
+
+	"` + moduleName + `/internal/worker"
+)
+
+const TypeWelcomeEmail = "email:welcome"
+
+// WelcomeEmail is the payload of a welcome email job.
+type WelcomeEmail struct {
+	Email string ` + "`" + `json:"email"` + "`" + `
+}
+
+// Register adds the handlers of all jobs in this package, add new job types
+// here.
+func Register(registry *worker.Registry) {
+	registry.Register(TypeWelcomeEmail, worker.HandlerFunc(HandleWelcomeEmail))
+}
+
+func HandleWelcomeEmail(ctx context.Context, job *worker.Job) error {
+	var payload WelcomeEmail
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	// Replace with a call to your mail provider.
+	log.Printf("Sending welcome email to %s", payload.Email)
+	return nil
+}
+`
+}
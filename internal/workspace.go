@@ -0,0 +1,507 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	constants "github.com/luigimorel/gogen/consants"
+)
+
+const (
+	workspaceServicesDir = "services"
+	workspacePkgDir      = "pkg"
+	// workspaceServicesMarker is the Makefile line gogen add service appends
+	// service names to.
+	workspaceServicesMarker = "SERVICES :="
+)
+
+var serviceNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// reservedServiceNames would clash with the targets of the workspace Makefile.
+var reservedServiceNames = []string{"build", "test", "tidy", "up", "down", workspacePkgDir}
+
+// ServiceTemplates lists the templates gogen add service can create.
+var ServiceTemplates = []string{constants.APITemplate, constants.WorkerTemplate, constants.CLITemplate}
+
+func IsServiceTemplate(template string) bool {
+	for _, t := range ServiceTemplates {
+		if t == template {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWorkspaceProject writes a multi-module repository: a go.work at the
+// root, the shared pkg module and a Makefile that gogen add service extends
+// with targets for every service under services/.
+func (pg *ProjectGenerator) CreateWorkspaceProject(projectName, moduleName string) error {
+	if moduleName == "" {
+		moduleName = projectName
+	}
+
+	files := map[string]string{
+		filepath.Join(workspacePkgDir, "config", "config.go"):      pg.generateWorkspaceConfigContent(),
+		filepath.Join(workspacePkgDir, "config", "config_test.go"): pg.generateWorkspaceConfigTestContent(),
+		"Makefile":      pg.generateWorkspaceMakefile(),
+		".dockerignore": goDockerignoreContent,
+	}
+	if err := writeProjectFiles(".", files); err != nil {
+		return err
+	}
+
+	pkgInit := exec.Command("go", "mod", "init", moduleName+"/"+workspacePkgDir)
+	pkgInit.Dir = workspacePkgDir
+	if err := pkgInit.Run(); err != nil {
+		return fmt.Errorf("failed to initialize the pkg module: %w", err)
+	}
+
+	if err := exec.Command("go", "work", "init", "./"+workspacePkgDir).Run(); err != nil {
+		return fmt.Errorf("failed to create go.work: %w", err)
+	}
+
+	if err := pg.CreateGitignoreFile(constants.WorkspaceTemplate, "."); err != nil {
+		fmt.Printf("Warning: failed to create .gitignore file: %v\n", err)
+	}
+
+	if err := pg.InitGitRepository(projectName, constants.WorkspaceTemplate); err != nil {
+		fmt.Printf("Warning: failed to initialize git repository: %v\n", err)
+	}
+
+	return nil
+}
+
+type ServiceConfig struct {
+	Name     string
+	Template string
+	Router   string
+	// Port is the port an api service listens on, 0 picks the next free
+	// port in docker-compose.yml.
+	Port       int
+	DockerBase string
+}
+
+// servicePortPattern matches the PORT variables of the services already in
+// docker-compose.yml.
+var servicePortPattern = regexp.MustCompile(`(?m)^\s+- PORT=(\d+)$`)
+
+// CreateWorkspaceService adds a module under services/<name> to the workspace
+// in rootDir with the api, worker or cli generator. The module is added to
+// go.work, replaces the shared pkg module with ../../pkg, and gets make
+// targets. api and worker services are also added to docker-compose.yml,
+// built from the workspace root so they can use pkg.
+func (pg *ProjectGenerator) CreateWorkspaceService(rootDir string, config *ServiceConfig) error {
+	if !serviceNamePattern.MatchString(config.Name) {
+		return fmt.Errorf("invalid service name %q: use lowercase letters, digits and dashes", config.Name)
+	}
+	for _, reserved := range reservedServiceNames {
+		if config.Name == reserved {
+			return fmt.Errorf("invalid service name %q: it is reserved for a workspace make target", config.Name)
+		}
+	}
+	if !IsServiceTemplate(config.Template) {
+		return fmt.Errorf("unsupported service template: %s. Supported templates: %s", config.Template, strings.Join(ServiceTemplates, ", "))
+	}
+
+	if _, err := os.Stat(filepath.Join(rootDir, "go.work")); err != nil {
+		return fmt.Errorf("no go.work found, run the command from the root of a workspace created with gogen new --template workspace")
+	}
+
+	pkgModule, err := readModulePath(filepath.Join(rootDir, workspacePkgDir, "go.mod"))
+	if err != nil {
+		return err
+	}
+	prefix := strings.TrimSuffix(pkgModule, "/"+workspacePkgDir)
+
+	serviceDir := filepath.Join(workspaceServicesDir, config.Name)
+	if dirExists(filepath.Join(rootDir, serviceDir)) {
+		return fmt.Errorf("service %s already exists in %s", config.Name, serviceDir)
+	}
+
+	composePath := filepath.Join(rootDir, "docker-compose.yml")
+	if config.Template == constants.APITemplate && config.Port == 0 {
+		config.Port = nextServicePort(composePath)
+	}
+
+	if err := os.MkdirAll(filepath.Join(rootDir, serviceDir), 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", serviceDir, err)
+	}
+
+	dm, err := NewDirectoryManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize directory manager: %w", err)
+	}
+	if err := dm.ChangeToDir(filepath.Join(rootDir, serviceDir)); err != nil {
+		return fmt.Errorf("failed to change to %s: %w", serviceDir, err)
+	}
+	defer func() {
+		if err := dm.RootDir(); err != nil {
+			fmt.Printf("Warning: failed to change root directory: %v\n", err)
+		}
+	}()
+
+	if err := pg.createService(prefix+"/"+filepath.ToSlash(serviceDir), config); err != nil {
+		return err
+	}
+
+	// The generators start a repository of their own, the workspace root
+	// already is one.
+	if err := os.RemoveAll(".git"); err != nil {
+		fmt.Printf("Warning: failed to remove the nested git repository: %v\n", err)
+	}
+
+	replace := exec.Command("go", "mod", "edit", "-replace", pkgModule+"=../../"+workspacePkgDir)
+	if err := replace.Run(); err != nil {
+		return fmt.Errorf("failed to point %s at the workspace: %w", pkgModule, err)
+	}
+
+	if err := dm.RootDir(); err != nil {
+		return fmt.Errorf("failed to change to root directory: %w", err)
+	}
+
+	use := exec.Command("go", "work", "use", "./"+filepath.ToSlash(serviceDir))
+	use.Dir = rootDir
+	if err := use.Run(); err != nil {
+		return fmt.Errorf("failed to add %s to go.work: %w", serviceDir, err)
+	}
+
+	if err := addServiceMakeTargets(filepath.Join(rootDir, "Makefile"), config.Name); err != nil {
+		fmt.Printf("Warning: failed to add make targets: %v\n", err)
+	}
+
+	if config.Template != constants.CLITemplate {
+		if err := pg.addComposeService(rootDir, serviceDir, config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createService runs the generator for the service template in the current
+// directory.
+func (pg *ProjectGenerator) createService(moduleName string, config *ServiceConfig) error {
+	if config.Template == constants.APITemplate {
+		if err := pg.CreateAPIProject(config.Name, moduleName, config.Router); err != nil {
+			return err
+		}
+
+		envContent := fmt.Sprintf("PORT=%d\n", config.Port)
+		for _, path := range []string{".env", ".env.example"} {
+			if err := os.WriteFile(path, []byte(envContent), 0600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+		}
+		return nil
+	}
+
+	cmd := exec.Command("go", "mod", "init", moduleName)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to initialize go module: %w", err)
+	}
+
+	if config.Template == constants.WorkerTemplate {
+		return pg.CreateWorkerProject(&WebProjectConfig{ProjectName: config.Name, ModuleName: moduleName})
+	}
+	return pg.CreateCLIProject(config.Name, moduleName, CLIFrameworkUrfave)
+}
+
+func nextServicePort(composePath string) int {
+	content, err := os.ReadFile(filepath.Clean(composePath))
+	if err != nil {
+		return DefaultAPIPort
+	}
+
+	port := DefaultAPIPort - 1
+	for _, match := range servicePortPattern.FindAllStringSubmatch(string(content), -1) {
+		if p, err := strconv.Atoi(match[1]); err == nil && p > port {
+			port = p
+		}
+	}
+	return port + 1
+}
+
+// addServiceMakeTargets appends the service to the SERVICES variable and adds
+// its build, run and test targets.
+func addServiceMakeTargets(makefilePath, name string) error {
+	content, err := os.ReadFile(filepath.Clean(makefilePath))
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	found := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, workspaceServicesMarker) {
+			lines[i] = strings.TrimRight(line, " ") + " " + name
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("no %q line in %s", workspaceServicesMarker, makefilePath)
+	}
+
+	dir := workspaceServicesDir + "/" + name
+	makefile := strings.Join(lines, "\n") + fmt.Sprintf(`
+.PHONY: %[1]s run-%[1]s test-%[1]s
+
+%[1]s:
+	cd %[2]s && go build -o ../../bin/%[1]s .
+
+run-%[1]s:
+	cd %[2]s && go run .
+
+test-%[1]s:
+	cd %[2]s && go test ./...
+`, name, dir)
+
+	return os.WriteFile(makefilePath, []byte(makefile), 0600)
+}
+
+// addComposeService writes the service's Dockerfile and adds it to
+// docker-compose.yml and the override, creating both on the first service.
+func (pg *ProjectGenerator) addComposeService(rootDir, serviceDir string, config *ServiceConfig) error {
+	dockerConfig := &WebProjectConfig{
+		APIPort:      config.Port,
+		DockerBase:   config.DockerBase,
+		WorkspaceDir: serviceDir,
+	}
+
+	goVersion := goVersionFromMod(filepath.Join(rootDir, serviceDir, "go.mod"))
+	var dockerfile string
+	if config.Template == constants.WorkerTemplate {
+		dockerfile = pg.generateWorkerDockerfile(goVersion, dockerConfig, ".")
+	} else {
+		healthcheckDir := filepath.Join(rootDir, serviceDir, "cmd", "healthcheck")
+		if err := os.MkdirAll(healthcheckDir, 0750); err != nil {
+			return fmt.Errorf("failed to create healthcheck directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(healthcheckDir, "main.go"), []byte(healthcheckContent), 0600); err != nil {
+			return fmt.Errorf("failed to create healthcheck command: %w", err)
+		}
+		dockerfile = pg.generateAPIDockerfile(goVersion, dockerConfig)
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, serviceDir, "Dockerfile"), []byte(dockerfile), 0600); err != nil {
+		return fmt.Errorf("failed to create Dockerfile: %w", err)
+	}
+
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return err
+	}
+	name := composeName(filepath.Base(absRoot))
+	service, override := generateWorkspaceComposeService(name, serviceDir, config)
+
+	composePath := filepath.Join(rootDir, "docker-compose.yml")
+	compose, err := os.ReadFile(filepath.Clean(composePath))
+	if os.IsNotExist(err) {
+		compose = []byte(fmt.Sprintf("name: %s\n\nservices:\n\nnetworks:\n  default:\n    driver: bridge\n", name))
+	} else if err != nil {
+		return fmt.Errorf("failed to read docker-compose.yml: %w", err)
+	}
+
+	// Services end where the top-level networks key starts.
+	content := string(compose)
+	index := strings.Index(content, "\nnetworks:\n")
+	if index == -1 {
+		return fmt.Errorf("failed to find the top-level networks key in docker-compose.yml")
+	}
+	content = content[:index] + "\n" + service + content[index:]
+	if err := os.WriteFile(composePath, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to update docker-compose.yml: %w", err)
+	}
+
+	overridePath := filepath.Join(rootDir, "docker-compose.override.yml")
+	overrideContent, err := os.ReadFile(filepath.Clean(overridePath))
+	if os.IsNotExist(err) {
+		overrideContent = []byte("services:\n")
+	} else if err != nil {
+		return fmt.Errorf("failed to read docker-compose.override.yml: %w", err)
+	} else {
+		overrideContent = append(overrideContent, '\n')
+	}
+	if err := os.WriteFile(overridePath, append(overrideContent, override...), 0600); err != nil {
+		return fmt.Errorf("failed to update docker-compose.override.yml: %w", err)
+	}
+
+	return nil
+}
+
+// generateWorkspaceComposeService returns the service definition for
+// docker-compose.yml and its development override. The override mounts the
+// whole workspace so go.work and pkg are visible to go run.
+func generateWorkspaceComposeService(projectName, serviceDir string, config *ServiceConfig) (string, string) {
+	dir := filepath.ToSlash(serviceDir)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `  %s:
+    build:
+      context: .
+      dockerfile: %s/Dockerfile
+    container_name: %s-%s
+`, config.Name, dir, projectName, config.Name)
+	if config.Template == constants.APITemplate {
+		fmt.Fprintf(&b, "    ports:\n      - \"%d:%d\"\n", config.Port, config.Port)
+	}
+	b.WriteString("    environment:\n")
+	if config.Template == constants.APITemplate {
+		fmt.Fprintf(&b, "      - PORT=%d\n", config.Port)
+	}
+	b.WriteString("      - ENV=production\n")
+	writeEnvFile(&b, "./"+dir+"/.env")
+	b.WriteString(`    networks:
+      - default
+    restart: unless-stopped
+`)
+	if config.Template == constants.APITemplate {
+		writeHealthcheck(&b, `["CMD", "/app/healthcheck"]`, "30s", "10s", 3, "40s")
+	}
+
+	var o strings.Builder
+	fmt.Fprintf(&o, `  %s:
+    build:
+      target: builder
+    volumes:
+      - .:/src
+    environment:
+      - ENV=development
+      - GO_ENV=development
+    command: ["sh", "-c", "go mod download && go run ."]
+`, config.Name)
+	if config.Template == constants.APITemplate {
+		writeHealthcheck(&o, fmt.Sprintf(`["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:%d%s"]`, config.Port, ReadinessPath), "30s", "10s", 3, "40s")
+	}
+
+	return b.String(), o.String()
+}
+
+func (pg *ProjectGenerator) generateWorkspaceMakefile() string {
+	return `# Services are added by gogen add service, each gets a build target named
+# after it plus run-<name> and test-<name>.
+SERVICES :=
+
+.PHONY: build test test-pkg tidy up down
+
+build: $(SERVICES)
+
+test: test-pkg $(addprefix test-,$(SERVICES))
+
+test-pkg:
+	cd pkg && go test ./...
+
+tidy:
+	cd pkg && go mod tidy
+	for service in $(SERVICES); do (cd services/$$service && go mod tidy) || exit 1; done
+	go work sync
+
+up:
+	docker compose up --build
+
+down:
+	docker compose down
+`
+}
+
+func (pg *ProjectGenerator) generateWorkspaceConfigContent() string {
+	return `// Package config reads settings from the environment. It lives in the shared
+// pkg module so every service parses its configuration the same way.
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// String returns the value of key, or fallback when it is unset or empty.
+func String(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Int returns key parsed as an integer, or fallback when it is unset or
+// invalid.
+func Int(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// Bool returns key parsed with strconv.ParseBool, or fallback when it is
+// unset or invalid.
+func Bool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// Duration returns key parsed with time.ParseDuration, or fallback when it is
+// unset or invalid.
+func Duration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+`
+}
+
+func (pg *ProjectGenerator) generateWorkspaceConfigTestContent() string {
+	return `package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFallbacks(t *testing.T) {
+	t.Setenv("CONFIG_TEST_INVALID", "not a value")
+
+	if got := String("CONFIG_TEST_UNSET", "default"); got != "default" {
+		t.Errorf("String = %q, want %q", got, "default")
+	}
+	if got := Int("CONFIG_TEST_INVALID", 3); got != 3 {
+		t.Errorf("Int = %d, want 3", got)
+	}
+	if got := Bool("CONFIG_TEST_INVALID", true); !got {
+		t.Error("Bool = false, want true")
+	}
+	if got := Duration("CONFIG_TEST_INVALID", time.Second); got != time.Second {
+		t.Errorf("Duration = %s, want 1s", got)
+	}
+}
+
+func TestValues(t *testing.T) {
+	t.Setenv("CONFIG_TEST_STRING", "value")
+	t.Setenv("CONFIG_TEST_INT", "42")
+	t.Setenv("CONFIG_TEST_BOOL", "true")
+	t.Setenv("CONFIG_TEST_DURATION", "1m30s")
+
+	if got := String("CONFIG_TEST_STRING", ""); got != "value" {
+		t.Errorf("String = %q, want %q", got, "value")
+	}
+	if got := Int("CONFIG_TEST_INT", 0); got != 42 {
+		t.Errorf("Int = %d, want 42", got)
+	}
+	if got := Bool("CONFIG_TEST_BOOL", false); !got {
+		t.Error("Bool = false, want true")
+	}
+	if got := Duration("CONFIG_TEST_DURATION", 0); got != 90*time.Second {
+		t.Errorf("Duration = %s, want 1m30s", got)
+	}
+}
+`
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWorkspaceServiceAirFile adds services to a workspace the way gogen add
+// service does and checks each one gets its own .air.toml.
+func TestWorkspaceServiceAirFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates projects")
+	}
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Error(err)
+		}
+	})
+
+	pg := NewProjectGenerator()
+	if err := pg.CreateWorkspaceProject("ws", "example.com/ws"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, template := range []string{"api", "worker"} {
+		t.Run(template, func(t *testing.T) {
+			name := template + "-service"
+			err := pg.CreateWorkspaceService(".", &ServiceConfig{Name: name, Template: template, Router: "stdlib", DockerBase: "distroless"})
+			if err != nil && strings.Contains(err.Error(), "tidy") {
+				t.Skipf("go mod tidy failed, the dependencies are not available: %v", err)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, "services", name, ".air.toml")); err != nil {
+				t.Errorf("services/%s has no .air.toml: %v", name, err)
+			}
+		})
+	}
+}